@@ -0,0 +1,83 @@
+package abis
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
+)
+
+// artifact Hardhat编译产物，只关心其中的ABI
+type artifact struct {
+	ContractName string          `json:"contractName"`
+	Abi          json.RawMessage `json:"abi"`
+}
+
+// Load 读取并解析ABI文件，支持Hardhat编译产物（artifacts/.../X.json）和纯ABI数组两种格式
+func Load(path string) (abi.ABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return abi.ABI{}, errors.Wrap(err, "failed on read abi file")
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		return abi.ABI{}, errors.Wrapf(err, "invalid abi file %s", path)
+	}
+	return parsed, nil
+}
+
+// Parse 解析ABI内容，内容为JSON对象时按Hardhat编译产物读取其中的abi字段
+func Parse(data []byte) (abi.ABI, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return abi.ABI{}, errors.New("empty abi")
+	}
+	if data[0] == '{' {
+		var a artifact
+		if err := json.Unmarshal(data, &a); err != nil {
+			return abi.ABI{}, errors.Wrap(err, "failed on parse artifact")
+		}
+		if len(a.Abi) == 0 {
+			return abi.ABI{}, errors.New("artifact has no abi field")
+		}
+		data = a.Abi
+	}
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return abi.ABI{}, errors.Wrap(err, "failed on parse abi")
+	}
+	return parsed, nil
+}
+
+// MustParse 解析代码中内置的ABI，内容有误时panic，只用于包级变量初始化
+func MustParse(data string) abi.ABI {
+	parsed, err := Parse([]byte(data))
+	if err != nil {
+		panic(errors.Wrap(err, "invalid builtin abi"))
+	}
+	return parsed
+}
+
+// Require 校验ABI中包含所有需要的事件和方法，一次返回全部缺失项
+func Require(parsed abi.ABI, events []string, methods []string) error {
+	var missing []string
+	for _, event := range events {
+		if _, ok := parsed.Events[event]; !ok {
+			missing = append(missing, "event "+event)
+		}
+	}
+	for _, method := range methods {
+		if _, ok := parsed.Methods[method]; !ok {
+			missing = append(missing, "method "+method)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("abi missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package abis
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const (
+	// poolV3Abi 调度服务配置的v3 pool合约ABI，由 solidity/scripts/v3/ExportAbi.js 从编译产物导出
+	poolV3Abi = "../../config/abi/Aave2PoolV3.json"
+	// poolV3Source v3 pool合约源码
+	poolV3Source = "../../../../solidity/contracts/v3/Aave2PoolV2.sol"
+	// poolV3Artifact 在solidity目录执行 npx hardhat compile 后的编译产物
+	poolV3Artifact = "../../../../solidity/artifacts/contracts/v3/Aave2PoolV2.sol/Aave2Pool.json"
+)
+
+// poolInherited 从 OwnableUpgradeable、UUPSUpgradeable 和 SafeERC20 继承的成员，不在合约源码中声明
+var poolInherited = map[string]bool{
+	"AdminChanged": true, "BeaconUpgraded": true, "Initialized": true, "OwnershipTransferred": true, "Upgraded": true,
+	"owner": true, "proxiableUUID": true, "renounceOwnership": true, "transferOwnership": true, "upgradeTo": true, "upgradeToAndCall": true,
+	"SafeERC20FailedOperation": true,
+}
+
+const erc20Abi = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+func TestParsePlainAbi(t *testing.T) {
+	parsed, err := Parse([]byte(erc20Abi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.Events["Transfer"]; !ok {
+		t.Fatal("Transfer event not parsed")
+	}
+}
+
+func TestParseArtifact(t *testing.T) {
+	artifact := `{"_format":"hh-sol-artifact-1","contractName":"Token","abi":` + erc20Abi + `,"bytecode":"0x"}`
+	parsed, err := Parse([]byte(artifact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.Methods["balanceOf"]; !ok {
+		t.Fatal("balanceOf method not parsed")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{"", "  ", `{"contractName":"Token"}`, `[{"type":"event","name":"Transfer"`} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "erc20.json")
+	if err := os.WriteFile(path, []byte(erc20Abi), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path + ".missing"); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	parsed, err := Parse([]byte(erc20Abi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Require(parsed, []string{"Transfer"}, []string{"balanceOf"}); err != nil {
+		t.Fatal(err)
+	}
+	err = Require(parsed, []string{"Transfer", "Approval"}, []string{"balanceOf", "allowance"})
+	if err == nil {
+		t.Fatal("expected missing error")
+	}
+	if !strings.Contains(err.Error(), "event Approval") || !strings.Contains(err.Error(), "method allowance") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid abi")
+		}
+	}()
+	MustParse(`[{"type":"event"`)
+}
+
+// abiMembers 按完整签名列出ABI中的方法、事件和错误，包含参数名、indexed和返回值
+func abiMembers(parsed abi.ABI) []string {
+	var members []string
+	for _, method := range parsed.Methods {
+		members = append(members, method.String())
+	}
+	for _, event := range parsed.Events {
+		members = append(members, event.String())
+	}
+	for _, e := range parsed.Errors {
+		members = append(members, e.String())
+	}
+	sort.Strings(members)
+	return members
+}
+
+func TestPoolV3AbiMatchesArtifact(t *testing.T) {
+	if _, err := os.Stat(poolV3Artifact); err != nil {
+		t.Skip("pool artifact not compiled, run npx hardhat compile in solidity")
+	}
+	artifact, err := Load(poolV3Artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured, err := Load(poolV3Abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := abiMembers(artifact), abiMembers(configured)
+	if strings.Join(want, "\n") != strings.Join(got, "\n") {
+		t.Fatalf("%s differs from the artifact, run npx hardhat run scripts/v3/ExportAbi.js in solidity\nwant:\n%s\ngot:\n%s",
+			poolV3Abi, strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+var (
+	solFunction    = regexp.MustCompile(`function\s+(\w+)\s*\([^)]*\)([^{;]*)`)
+	solPublicState = regexp.MustCompile(`(?m)^\s*[\w\[\]() =>]+?\s+public\s+(?:constant\s+)?(\w+)\s*[;=]`)
+	solEvent       = regexp.MustCompile(`event\s+(\w+)\s*\(`)
+	solExternal    = regexp.MustCompile(`\b(public|external)\b`)
+)
+
+// TestPoolV3AbiMatchesSource 没有编译产物时按名称比对源码，ABI中的成员都在源码中声明或继承，源码中的公开成员都在ABI中
+func TestPoolV3AbiMatchesSource(t *testing.T) {
+	source, err := os.ReadFile(poolV3Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := make(map[string]bool)
+	for _, m := range solFunction.FindAllStringSubmatch(string(source), -1) {
+		if solExternal.MatchString(m[2]) {
+			declared[m[1]] = true
+		}
+	}
+	for _, re := range []*regexp.Regexp{solPublicState, solEvent} {
+		for _, m := range re.FindAllStringSubmatch(string(source), -1) {
+			declared[m[1]] = true
+		}
+	}
+	configured, err := Load(poolV3Abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inAbi := make(map[string]bool)
+	for name := range configured.Methods {
+		inAbi[name] = true
+	}
+	for name := range configured.Events {
+		inAbi[name] = true
+	}
+	for name := range configured.Errors {
+		inAbi[name] = true
+	}
+	for name := range inAbi {
+		if !declared[name] && !poolInherited[name] {
+			t.Errorf("%s is not declared in %s", name, poolV3Source)
+		}
+	}
+	for name := range declared {
+		if !inAbi[name] {
+			t.Errorf("%s is missing in %s", name, poolV3Abi)
+		}
+	}
+}
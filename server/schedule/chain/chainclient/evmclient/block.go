@@ -0,0 +1,75 @@
+package evmclient
+
+import (
+	logTypes "aave_schedule/chain/types"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// rpcBlockTxs 只解析区块时间和交易中需要的字段，兼容L2链额外的交易类型和字段
+type rpcBlockTxs struct {
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	Transactions []struct {
+		Hash             common.Hash     `json:"hash"`
+		TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+		From             common.Address  `json:"from"`
+		To               *common.Address `json:"to"`
+		Input            hexutil.Bytes   `json:"input"`
+		Gas              hexutil.Uint64  `json:"gas"`
+		GasPrice         *hexutil.Big    `json:"gasPrice"`
+	} `json:"transactions"`
+}
+
+// BlockTxsByNumbers 通过JSON-RPC批量请求一次获取多个区块的时间和交易，返回区块号到区块的映射
+func (s *Service) BlockTxsByNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]*logTypes.BlockTxs, error) {
+	blocks := make(map[uint64]*logTypes.BlockTxs, len(blockNumbers))
+	for start := 0; start < len(blockNumbers); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		results := make([]*rpcBlockTxs, end-start)
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(blockNumbers[start+i]), true},
+				Result: &results[i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, errors.Wrap(err, "failed on batch get blocks")
+		}
+		for i, elem := range elems {
+			blockNumber := blockNumbers[start+i]
+			if elem.Error != nil {
+				return nil, errors.Wrapf(elem.Error, "failed on get block %d", blockNumber)
+			}
+			if results[i] == nil {
+				return nil, errors.Errorf("block %d not found", blockNumber)
+			}
+			block := &logTypes.BlockTxs{Number: blockNumber, Time: uint64(results[i].Timestamp)}
+			for _, tx := range results[i].Transactions {
+				blockTx := &logTypes.BlockTx{
+					Hash:  tx.Hash,
+					Index: int(tx.TransactionIndex),
+					From:  tx.From,
+					To:    tx.To,
+					Input: tx.Input,
+					Gas:   uint64(tx.Gas),
+				}
+				if tx.GasPrice != nil {
+					blockTx.GasPrice = (*big.Int)(tx.GasPrice)
+				}
+				block.Transactions = append(block.Transactions, blockTx)
+			}
+			blocks[blockNumber] = block
+		}
+	}
+	return blocks, nil
+}
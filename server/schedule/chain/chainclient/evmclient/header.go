@@ -0,0 +1,47 @@
+package evmclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// blockTimeHeader 只解析区块头中的时间戳，不依赖完整的区块头结构，兼容L2链额外的字段
+type blockTimeHeader struct {
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+}
+
+// BlockTimesByNumbers 通过JSON-RPC批量请求一次获取多个区块的时间戳，返回区块号到时间戳的映射
+func (s *Service) BlockTimesByNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]uint64, error) {
+	blockTimes := make(map[uint64]uint64, len(blockNumbers))
+	for start := 0; start < len(blockNumbers); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		headers := make([]*blockTimeHeader, end-start)
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(blockNumbers[start+i]), false},
+				Result: &headers[i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, errors.Wrap(err, "failed on batch get block headers")
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return nil, errors.Wrapf(elem.Error, "failed on get block header %d", blockNumbers[start+i])
+			}
+			if headers[i] == nil {
+				return nil, errors.Errorf("block %d not found", blockNumbers[start+i])
+			}
+			blockTimes[blockNumbers[start+i]] = uint64(headers[i].Timestamp)
+		}
+	}
+	return blockTimes, nil
+}
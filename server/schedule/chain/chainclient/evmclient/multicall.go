@@ -0,0 +1,121 @@
+package evmclient
+
+import (
+	"aave_schedule/chain"
+	"aave_schedule/chain/abis"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	logTypes "aave_schedule/chain/types"
+)
+
+// maxMulticallSize 单次 aggregate3 最多包含的调用数量，避免超出节点 eth_call 的gas上限
+const maxMulticallSize = 200
+
+// maxBatchSize 单次JSON-RPC批量请求最多包含的请求数量，大部分节点服务商限制在100以内
+const maxBatchSize = 50
+
+const multicall3AbiJson = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var multicall3Abi = abis.MustParse(multicall3AbiJson)
+
+// BatchCallContract 把多个 eth_call 合并为JSON-RPC批量请求，全部固定在blockNumber执行
+// 单个调用失败只记录在对应结果的Err中，只有请求本身失败时才返回error
+func (s *Service) BatchCallContract(ctx context.Context, msgs []ethereum.CallMsg, blockNumber *big.Int) ([]logTypes.CallResult, error) {
+	results := make([]logTypes.CallResult, len(msgs))
+	for start := 0; start < len(msgs); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		datas := make([]hexutil.Bytes, end-start)
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{toCallArg(msgs[start+i]), toBlockNumArg(blockNumber)},
+				Result: &datas[i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, errors.Wrap(err, "failed on batch call contract")
+		}
+		for i, elem := range elems {
+			results[start+i] = logTypes.CallResult{Data: datas[i], Err: elem.Error}
+		}
+	}
+	return results, nil
+}
+
+// Multicall 按 maxMulticallSize 把调用拆分为多个 aggregate3，再通过一次批量请求发送，
+// 所有调用都在同一区块执行，返回结果与calls一一对应
+func (s *Service) Multicall(ctx context.Context, calls []logTypes.MulticallCall, blockNumber *big.Int) ([]logTypes.MulticallResult, error) {
+	multicallAddress := common.HexToAddress(chain.Multicall3Address)
+	var msgs []ethereum.CallMsg
+	for start := 0; start < len(calls); start += maxMulticallSize {
+		end := start + maxMulticallSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		data, err := multicall3Abi.Pack("aggregate3", calls[start:end])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on pack aggregate3")
+		}
+		msgs = append(msgs, ethereum.CallMsg{To: &multicallAddress, Data: data})
+	}
+
+	callResults, err := s.BatchCallContract(ctx, msgs, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]logTypes.MulticallResult, 0, len(calls))
+	for _, callResult := range callResults {
+		if callResult.Err != nil {
+			return nil, errors.Wrap(callResult.Err, "failed on call aggregate3")
+		}
+		var out []logTypes.MulticallResult
+		if err := multicall3Abi.UnpackIntoInterface(&out, "aggregate3", callResult.Data); err != nil {
+			return nil, errors.Wrap(err, "failed on unpack aggregate3")
+		}
+		results = append(results, out...)
+	}
+	if len(results) != len(calls) {
+		return nil, errors.Errorf("multicall returned %d results, want %d", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// toCallArg 与 ethclient 中的实现一致，把 CallMsg 转换为 eth_call 的参数
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
+
+// toBlockNumArg blockNumber为nil时表示最新区块
+func toBlockNumArg(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(blockNumber)
+}
@@ -0,0 +1,82 @@
+package evmclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// TransactionReceipts 通过JSON-RPC批量请求获取多个交易的收据，返回结果与hashes一一对应
+func (s *Service) TransactionReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	for start := 0; start < len(hashes); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{hashes[start+i]},
+				Result: &receipts[start+i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, errors.Wrap(err, "failed on batch get transaction receipts")
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return nil, errors.Wrapf(elem.Error, "failed on get transaction receipt %s", hashes[start+i].Hex())
+			}
+			if receipts[start+i] == nil {
+				return nil, errors.Errorf("transaction receipt %s not found", hashes[start+i].Hex())
+			}
+		}
+	}
+	return receipts, nil
+}
+
+// rpcReceiptTo 只解析收据中交易的接收地址
+type rpcReceiptTo struct {
+	To *common.Address `json:"to"`
+}
+
+// BlocksWithTxTo 通过JSON-RPC批量请求 eth_getBlockReceipts 读取区块收据，返回存在直接调用 to 地址的交易的区块，顺序与 blockNumbers 相同
+func (s *Service) BlocksWithTxTo(ctx context.Context, blockNumbers []uint64, to common.Address) ([]uint64, error) {
+	var matched []uint64
+	for start := 0; start < len(blockNumbers); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		results := make([][]*rpcReceiptTo, end-start)
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBlockReceipts",
+				Args:   []interface{}{hexutil.EncodeUint64(blockNumbers[start+i])},
+				Result: &results[i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, errors.Wrap(err, "failed on batch get block receipts")
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return nil, errors.Wrapf(elem.Error, "failed on get receipts of block %d", blockNumbers[start+i])
+			}
+			for _, receipt := range results[i] {
+				if receipt != nil && receipt.To != nil && *receipt.To == to {
+					matched = append(matched, blockNumbers[start+i])
+					break
+				}
+			}
+		}
+	}
+	return matched, nil
+}
@@ -83,6 +83,14 @@ func (s *Service) CallContract(ctx context.Context, msg ethereum.CallMsg, blockN
 	return s.client.CallContract(ctx, msg, blockNumber)
 }
 
+func (s *Service) StorageAt(ctx context.Context, address common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	value, err := s.client.StorageAt(ctx, address, key, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on get storage")
+	}
+	return value, nil
+}
+
 func (s *Service) BlockNumber() (uint64, error) {
 	var err error
 	blockNum, err := s.client.BlockNumber(context.Background())
@@ -7,6 +7,8 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
 	logTypes "aave_schedule/chain/types"
@@ -15,11 +17,25 @@ import (
 type ChainClient interface {
 	FilterLogs(ctx context.Context, q logTypes.FilterQuery) ([]interface{}, error)
 	BlockTimeByNumber(context.Context, *big.Int) (uint64, error)
+	// BlockTimesByNumbers 批量获取多个区块的时间戳
+	BlockTimesByNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]uint64, error)
 	Client() interface{}
 	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
 	CallContractByChain(ctx context.Context, param logTypes.CallParam) (interface{}, error)
+	// BatchCallContract 通过一次JSON-RPC批量请求在同一区块执行多个 eth_call
+	BatchCallContract(ctx context.Context, msgs []ethereum.CallMsg, blockNumber *big.Int) ([]logTypes.CallResult, error)
+	// Multicall 通过 Multicall3 aggregate3 在同一区块聚合执行多个调用
+	Multicall(ctx context.Context, calls []logTypes.MulticallCall, blockNumber *big.Int) ([]logTypes.MulticallResult, error)
+	// StorageAt 读取合约在指定区块的存储槽，blockNumber 为空时读取最新区块
+	StorageAt(ctx context.Context, address common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
 	BlockNumber() (uint64, error)
 	BlockWithTxs(ctx context.Context, blockNumber uint64) (interface{}, error)
+	// BlockTxsByNumbers 批量获取多个区块的时间和交易
+	BlockTxsByNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]*logTypes.BlockTxs, error)
+	// BlocksWithTxTo 批量读取区块收据，返回存在直接调用 to 地址的交易的区块
+	BlocksWithTxTo(ctx context.Context, blockNumbers []uint64, to common.Address) ([]uint64, error)
+	// TransactionReceipts 批量获取交易收据，结果与hashes一一对应
+	TransactionReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error)
 }
 
 func New(chainID int, nodeUrl string) (ChainClient, error) {
@@ -13,6 +13,9 @@ const (
 	Sepolia  = "sepolia"
 )
 
+// Multicall3Address Multicall3 合约在各条EVM链上的部署地址相同
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
 const (
 	EthChainID      = 1
 	OptimismChainID = 10
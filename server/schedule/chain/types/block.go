@@ -0,0 +1,25 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BlockTxs 区块时间和区块中的交易，只包含记录交易需要的字段
+type BlockTxs struct {
+	Number       uint64
+	Time         uint64
+	Transactions []*BlockTx
+}
+
+// BlockTx 区块中的交易，To 为空表示创建合约的交易
+type BlockTx struct {
+	Hash     common.Hash
+	Index    int
+	From     common.Address
+	To       *common.Address
+	Input    []byte
+	Gas      uint64
+	GasPrice *big.Int
+}
@@ -4,9 +4,29 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type CallParam struct {
 	EVMParam    ethereum.CallMsg
 	BlockNumber *big.Int
 }
+
+// CallResult 批量 eth_call 中单个调用的结果，Err不为空时表示该调用失败
+type CallResult struct {
+	Data []byte
+	Err  error
+}
+
+// MulticallCall Multicall3 aggregate3 中的单个调用
+type MulticallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// MulticallResult Multicall3 aggregate3 中单个调用的结果，Success为false时ReturnData为revert数据
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+}
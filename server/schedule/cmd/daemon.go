@@ -13,6 +13,7 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -62,7 +63,8 @@ var DaemonCmd = &cobra.Command{
 				return
 			}
 
-			if cfg.Monitor.PprofEnable { // 开启pprof，用于性能监控
+			if cfg.Monitor.PprofEnable { // 开启pprof，用于性能监控，同时暴露对账等监控指标
+				http.Handle("/metrics", promhttp.Handler())
 				err := http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", cfg.Monitor.PprofPort), nil)
 				if err != nil {
 					onSyncExit <- err
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/service"
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var repairDrift bool
+
+var ReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "reconcile indexed state against chain.",
+	Long:  "reconcile indexed state against chain, drifts are saved to reconcile_drift, --repair rebuilds activity records from deploy_block and checks again.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		cfg, err := config.UnmarshalCmdConfig() // 读取和解析配置文件
+		if err != nil {
+			return err
+		}
+		if _, err := xzap.SetUp(cfg.Log); err != nil { // 初始化日志模块
+			return err
+		}
+
+		s, err := service.New(ctx, cfg)
+		if err != nil {
+			xzap.WithContext(ctx).Error("Failed to create sync server", zap.Error(err))
+			return err
+		}
+		report, err := s.Reconcile(repairDrift)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("run %d at block %d: checked %d, drifts %d\n", report.RunID, report.BlockNumber, report.Checked, len(report.Drifts))
+		for _, drift := range report.Drifts {
+			fmt.Printf("  %-24s token=%s user=%s indexed=%s chain=%s diff=%s\n",
+				drift.Scope, drift.TokenAddress, drift.UserAddress, drift.IndexedValue, drift.ChainValue, drift.Diff)
+		}
+		if report.Repaired {
+			fmt.Printf("repaired, remaining drifts %d\n", report.Remaining)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ReconcileCmd.Flags().BoolVar(&repairDrift, "repair", false, "rebuild activity records from deploy_block when drift found")
+	rootCmd.AddCommand(ReconcileCmd)
+}
@@ -3,6 +3,8 @@ package config
 import (
 	logging "aave_schedule/logger"
 	"aave_schedule/stores/gdb"
+	"path/filepath"
+
 	"github.com/spf13/viper"
 )
 
@@ -24,27 +26,68 @@ type Monitor struct {
 }
 
 type Config struct {
-	Monitor     *Monitor        `toml:"monitor" mapstructure:"monitor" json:"monitor"`
-	Log         logging.LogConf `toml:"log" json:"log"`
-	DB          gdb.Config      `toml:"db" json:"db"`
-	Kv          *KvConf         `toml:"kv" json:"kv"`
-	AnkrCfg     AnkrCfg         `toml:"ankr_cfg" mapstructure:"ankr_cfg" json:"ankr_cfg"`
-	ChainCfg    ChainCfg        `toml:"chain_cfg" mapstructure:"chain_cfg" json:"chain_cfg"`
-	ContractCfg ContractCfg     `toml:"contract_cfg" mapstructure:"contract_cfg" json:"contract_cfg"`
+	Monitor      *Monitor        `toml:"monitor" mapstructure:"monitor" json:"monitor"`
+	Log          logging.LogConf `toml:"log" json:"log"`
+	DB           gdb.Config      `toml:"db" json:"db"`
+	Kv           *KvConf         `toml:"kv" json:"kv"`
+	AnkrCfg      AnkrCfg         `toml:"ankr_cfg" mapstructure:"ankr_cfg" json:"ankr_cfg"`
+	ChainCfg     ChainCfg        `toml:"chain_cfg" mapstructure:"chain_cfg" json:"chain_cfg"`
+	ContractCfg  ContractCfg     `toml:"contract_cfg" mapstructure:"contract_cfg" json:"contract_cfg"`
+	ReconcileCfg ReconcileCfg    `toml:"reconcile_cfg" mapstructure:"reconcile_cfg" json:"reconcile_cfg"`
+	ParamCfg     ParamCfg        `toml:"param_cfg" mapstructure:"param_cfg" json:"param_cfg"`
+	TxCfg        TxCfg           `toml:"tx_cfg" mapstructure:"tx_cfg" json:"tx_cfg"`
+	Contracts    []Contract      `toml:"contracts" mapstructure:"contracts" json:"contracts"`
+}
+
+const (
+	// ContractKindERC20 ERC-20代币合约，同步 Transfer、Approval 事件
+	ContractKindERC20 = "erc20"
+	// ContractKindOracle 价格预言机合约，按批次读取代币价格
+	ContractKindOracle = "oracle"
+)
+
+// Contract 除pool合约外需要同步的合约
+type Contract struct {
+	Name       string `toml:"name" mapstructure:"name" json:"name"`
+	Address    string `toml:"address" mapstructure:"address" json:"address"`
+	PoolGetter string `toml:"pool_getter" mapstructure:"pool_getter" json:"pool_getter"` // 未配置地址时，启动时调用pool合约的该方法读取地址，如 cUsdcTokenAddress
+	Kind       string `toml:"kind" mapstructure:"kind" json:"kind"`                      // erc20、oracle
+	AbiFile    string `toml:"abi_file" mapstructure:"abi_file" json:"abi_file"`          // ABI文件路径，相对路径相对于配置文件所在目录
+}
+
+// ReconcileCfg 对账配置，定期比较根据事件计算的数据和链上合约状态
+type ReconcileCfg struct {
+	Interval          int    `toml:"interval" mapstructure:"interval" json:"interval"`                                  // 定时对账间隔（秒），0表示不开启
+	Repair            bool   `toml:"repair" mapstructure:"repair" json:"repair"`                                        // 定时对账发现偏差时是否自动修复
+	DeployBlock       uint64 `toml:"deploy_block" mapstructure:"deploy_block" json:"deploy_block"`                      // 修复时重建事件的起始区块，一般为合约部署区块，开启修复时必须配置
+	RepairBatchBlocks uint64 `toml:"repair_batch_blocks" mapstructure:"repair_batch_blocks" json:"repair_batch_blocks"` // 修复时每次查询日志的区块数
+}
+
+// ParamCfg 风险参数监控配置，按区块区间读取没有事件的owner参数，记录参数的版本历史
+type ParamCfg struct {
+	BlockRange uint64  `toml:"block_range" mapstructure:"block_range" json:"block_range"` // 每隔多少个区块读取一次参数，0表示不开启
+	AlertRatio float64 `toml:"alert_ratio" mapstructure:"alert_ratio" json:"alert_ratio"` // 参数相对变化比例超过该值时告警，0.2表示20%
+}
+
+// TxCfg pool合约交易和gas统计配置，AlertWindow 秒内 AlertMethods 中某个方法的失败交易达到 AlertFailedCount 笔时告警
+type TxCfg struct {
+	Enable           bool     `toml:"enable" mapstructure:"enable" json:"enable"`
+	ScanBlocks       bool     `toml:"scan_blocks" mapstructure:"scan_blocks" json:"scan_blocks"`       // 读取全部区块的收据，找出直接调用pool合约的失败交易，关闭时只读取有pool日志的区块
+	AlertMethods     []string `toml:"alert_methods" mapstructure:"alert_methods" json:"alert_methods"` // 方法名，无法解析方法名的交易使用0x开头的4字节选择器
+	AlertWindow      int64    `toml:"alert_window" mapstructure:"alert_window" json:"alert_window"`
+	AlertFailedCount int64    `toml:"alert_failed_count" mapstructure:"alert_failed_count" json:"alert_failed_count"`
 }
 
+// ContractCfg 合约配置，pool合约ABI优先从 AbiFiles 中 AbiVersion 对应的文件读取，未配置时使用 AbiJson
+// Implementations 为pool代理合约实现地址到 AbiFiles 版本的映射，升级前后的日志按区块使用对应版本的ABI解析，未配置的实现合约使用 AbiVersion
+// 事件按ABI事件名注册处理器分发，不需要配置事件topic
 type ContractCfg struct {
-	AavePoolAddress            string `toml:"aave_pool_address" mapstructure:"aave_pool_address" json:"aave_pool_address"`
-	TokenAddressMap            string `toml:"token_address_map" mapstructure:"token_address_map" json:"token_address_map"`
-	AbiJson                    string `toml:"abijson" mapstructure:"abijson" json:"abijson"`
-	DepositLendTopic           string `toml:"deposit_lend_topic" mapstructure:"deposit_lend_topic" json:"deposit_lend_topic"`
-	DepositLendWithdrawTopic   string `toml:"deposit_lend_withdraw_topic" mapstructure:"deposit_lend_withdraw_topic" json:"deposit_lend_withdraw_topic"`
-	DepositBorrowTopic         string `toml:"deposit_borrow_topic" mapstructure:"deposit_borrow_topic" json:"deposit_borrow_topic"`
-	DepositBorrowWithdrawTopic string `toml:"deposit_borrow_withdraw_topic" mapstructure:"deposit_borrow_withdraw_topic" json:"deposit_borrow_withdraw_topic"`
-	LiquidateTopic             string `toml:"liquidate_topic" mapstructure:"liquidate_topic" json:"liquidate_topic"`
-	CalculateBorrowableTopic   string `toml:"calculate_borrowable_topic" mapstructure:"calculate_borrowable_topic" json:"calculate_borrowable_topic"`
-	StatusChangedTopic         string `toml:"status_changed_topic" mapstructure:"status_changed_topic" json:"status_changed_topic"`
-	CollateralChangedTopic     string `toml:"collateral_changed_topic" mapstructure:"collateral_changed_topic" json:"collateral_changed_topic"`
+	AavePoolAddress string            `toml:"aave_pool_address" mapstructure:"aave_pool_address" json:"aave_pool_address"`
+	TokenAddressMap string            `toml:"token_address_map" mapstructure:"token_address_map" json:"token_address_map"`
+	AbiJson         string            `toml:"abijson" mapstructure:"abijson" json:"abijson"`
+	AbiVersion      string            `toml:"abi_version" mapstructure:"abi_version" json:"abi_version"`
+	AbiFiles        map[string]string `toml:"abi_files" mapstructure:"abi_files" json:"abi_files"`
+	Implementations map[string]string `toml:"implementations" mapstructure:"implementations" json:"implementations"`
 }
 
 type KvConf struct {
@@ -67,9 +110,24 @@ func UnmarshalCmdConfig() (*Config, error) {
 	if err := viper.Unmarshal(&c); err != nil {
 		return nil, err
 	}
+	configDir := filepath.Dir(viper.ConfigFileUsed())
+	for version, file := range c.ContractCfg.AbiFiles {
+		c.ContractCfg.AbiFiles[version] = resolvePath(configDir, file)
+	}
+	for i := range c.Contracts {
+		c.Contracts[i].AbiFile = resolvePath(configDir, c.Contracts[i].AbiFile)
+	}
 	return &c, nil
 }
 
+// resolvePath 相对路径转换为相对于配置文件所在目录的路径
+func resolvePath(configDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configDir, path)
+}
+
 func DefaultConfig() (*Config, error) {
 	return &Config{}, nil
 }
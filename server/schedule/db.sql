@@ -17,10 +17,15 @@ create table aave.lend
     total_deposits   decimal(32, 2)                    not null default 0 comment '总存入金额，usdc换算成的dollar，保留两小数',
     utilization_rate decimal(4, 2)                     not null default 0 comment '利用率，保留两位小数，百分比，例子：10.02%',
     apy_interest     decimal(4, 2)                     not null comment '动态年化利率，保留两位小数，百分比，例子：10.02%',
-    create_time      bigint                            not null comment '创建时间',
+    block_number     bigint                            not null default 0 comment '事件所在区块号',
+    tx_hash          char(66)                          not null default '' comment '事件所在交易哈希',
+    log_index        int                               not null default 0 comment '日志序号',
+    create_time      bigint                            not null comment '创建时间，事件所在区块的时间',
     update_time      bigint                            not null comment '更新时间',
     creator          char(42)                          not null comment '创建人',
-    updater          char(42)                          not null comment '更新人'
+    updater          char(42)                          not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_block_number (block_number)
 );
 
 
@@ -32,8 +37,221 @@ create table aave.collateral
     borrowable       decimal(32, 2)                    not null default 0 comment '总可借金额，usdc换算成的dollar，保留两小数',
     utilization_rate decimal(4, 2)                     not null default 0 comment '利用率，保留两位小数，百分比，例子：10.02%',
     apy_interest     decimal(4, 2)                     not null comment '动态年化利率',
-    create_time      bigint                            not null comment '创建时间',
+    block_number     bigint                            not null default 0 comment '事件所在区块号',
+    tx_hash          char(66)                          not null default '' comment '事件所在交易哈希',
+    log_index        int                               not null default 0 comment '日志序号',
+    create_time      bigint                            not null comment '创建时间，事件所在区块的时间',
     update_time      bigint                            not null comment '更新时间',
     creator          char(42)                          not null comment '创建人',
-    updater          char(42)                          not null comment '更新人'
-)
\ No newline at end of file
+    updater          char(42)                          not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_block_number (block_number)
+);
+
+create table aave.interest_index
+(
+    id                    bigint auto_increment primary key not null comment '主键ID,自增',
+    block_number          bigint                            not null comment '区块号',
+    block_time            bigint                            not null comment '区块时间戳',
+    liquidity_index       varchar(100)                      not null comment '存款指数，1e27精度',
+    borrow_index          varchar(100)                      not null comment '借款指数，1e27精度',
+    last_update_timestamp bigint                            not null comment '合约指数最后更新时间',
+    create_time           bigint                            not null comment '创建时间',
+    update_time           bigint                            not null comment '更新时间',
+    creator               char(42)                          not null comment '创建人',
+    updater               char(42)                          not null comment '更新人',
+    unique key uk_block_number (block_number)
+);
+
+create table aave.user_activity
+(
+    id                bigint auto_increment primary key not null comment '主键ID,自增',
+    user_address      char(42)                          not null comment '用户地址，小写',
+    action            varchar(32)                       not null comment '操作类型',
+    token_address     char(42)     default ''           not null comment '抵押代币地址，存取款为空',
+    amount            varchar(100) default '0'          not null comment '操作金额，链上精度',
+    interest          varchar(100) default '0'          not null comment '取款时实现的利息，链上精度',
+    liquidity_index   varchar(100) default ''           not null comment '存款时的存款指数，1e27精度',
+    collateral_amount varchar(100) default '0'          not null comment '清算时被扣押或还款时返还的抵押物数量，链上精度',
+    counterparty      char(42)     default ''           not null comment '清算的对手方地址',
+    tx_hash           char(66)                          not null comment '交易哈希',
+    log_index         int                               not null comment '日志序号',
+    block_number      bigint                            not null comment '区块号',
+    block_time        bigint                            not null comment '区块时间戳',
+    create_time       bigint                            not null comment '创建时间',
+    update_time       bigint                            not null comment '更新时间',
+    creator           char(42)                          not null comment '创建人',
+    updater           char(42)                          not null comment '更新人',
+    unique key uk_tx_log_user (tx_hash, log_index, user_address),
+    key idx_user_block (user_address, block_number)
+);
+
+create table aave.liquidation
+(
+    id                bigint auto_increment primary key not null comment '主键ID,自增',
+    borrower          char(42)                          not null comment '被清算的借款人地址，小写',
+    liquidator        char(42)                          not null comment '清算人地址，小写',
+    collateral_token  char(42)                          not null comment '抵押代币地址，小写',
+    liquidated_amount varchar(100)                      not null comment '清算人代还的USDC数量，链上精度',
+    collateral_seized varchar(100)                      not null comment '清算人获得的抵押物数量（含奖励），链上精度',
+    bonus_amount      varchar(100) default '0'          not null comment '其中清算奖励的抵押物数量，链上精度',
+    penalty_rate      varchar(100) default '0'          not null comment '清算时的liquidationPenaltyFeeRate4Cleaner，6位精度',
+    collateral_price  varchar(100) default '0'          not null comment '清算时抵押物的预言机价格，美元6位精度',
+    usdc_price        varchar(100) default '0'          not null comment '清算时USDC的预言机价格，美元6位精度',
+    debt_value_usd    varchar(100) default '0'          not null comment '代还债务的美元价值，6位精度',
+    seized_value_usd  varchar(100) default '0'          not null comment '获得抵押物的美元价值，6位精度',
+    bonus_value_usd   varchar(100) default '0'          not null comment '清算奖励的美元价值，6位精度',
+    tx_hash           char(66)                          not null comment '交易哈希',
+    log_index         int                               not null comment '日志序号',
+    block_number      bigint                            not null comment '区块号',
+    block_time        bigint                            not null comment '区块时间戳',
+    create_time       bigint                            not null comment '创建时间',
+    update_time       bigint                            not null comment '更新时间',
+    creator           char(42)                          not null comment '创建人',
+    updater           char(42)                          not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_liquidator (liquidator),
+    key idx_collateral_token (collateral_token),
+    key idx_borrower (borrower)
+);
+
+create table aave.reconcile_drift
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    run_id         bigint                            not null comment '对账批次，对账开始时间戳（毫秒）',
+    block_number   bigint                            not null comment '对账所在区块',
+    scope          varchar(32)                       not null comment '对账项',
+    token_address  char(42)     default ''           not null comment '抵押代币地址，全局项为空',
+    user_address   char(42)     default ''           not null comment '用户地址，非用户项为空',
+    indexed_value  varchar(100)                      not null comment '根据已同步事件计算的值',
+    chain_value    varchar(100)                      not null comment '链上读取的值',
+    diff           varchar(100)                      not null comment 'chain_value - indexed_value',
+    repaired       tinyint      default 0            not null comment '是否已触发修复',
+    create_time    bigint                            not null comment '创建时间',
+    update_time    bigint                            not null comment '更新时间',
+    creator        char(42)                          not null comment '创建人',
+    updater        char(42)                          not null comment '更新人',
+    key idx_run_id (run_id),
+    key idx_scope_token (scope, token_address)
+);
+
+create table aave.token_transfer
+(
+    id            bigint auto_increment primary key not null comment '主键ID,自增',
+    token_address char(42)     not null comment '代币合约地址，小写',
+    from_address  char(42)     not null comment '转出地址，小写',
+    to_address    char(42)     not null comment '转入地址，小写',
+    amount        varchar(100) not null comment '转账数量，链上精度',
+    tx_hash       char(66)     not null comment '交易哈希',
+    log_index     int          not null comment '日志序号',
+    block_number  bigint       not null comment '区块号',
+    block_time    bigint       not null comment '区块时间戳',
+    create_time   bigint       not null comment '创建时间',
+    update_time   bigint       not null comment '更新时间',
+    creator       char(42)     not null comment '创建人',
+    updater       char(42)     not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_token_from (token_address, from_address),
+    key idx_token_to (token_address, to_address)
+);
+
+create table aave.token_approval
+(
+    id              bigint auto_increment primary key not null comment '主键ID,自增',
+    token_address   char(42)     not null comment '代币合约地址，小写',
+    owner_address   char(42)     not null comment '授权人地址，小写',
+    spender_address char(42)     not null comment '被授权地址，小写',
+    amount          varchar(100) not null comment '授权额度，链上精度',
+    tx_hash         char(66)     not null comment '交易哈希',
+    log_index       int          not null comment '日志序号',
+    block_number    bigint       not null comment '区块号',
+    block_time      bigint       not null comment '区块时间戳',
+    create_time     bigint       not null comment '创建时间',
+    update_time     bigint       not null comment '更新时间',
+    creator         char(42)     not null comment '创建人',
+    updater         char(42)     not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_token_owner_spender (token_address, owner_address, spender_address)
+);
+
+create table aave.token_price
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    oracle_address char(42)     not null comment '预言机合约地址，小写',
+    token_address  char(42)     not null comment '代币合约地址，小写',
+    price          varchar(100) not null comment '预言机价格，预言机精度',
+    block_number   bigint       not null comment '读取价格的区块号',
+    block_time     bigint       not null comment '读取价格的区块时间戳',
+    create_time    bigint       not null comment '创建时间',
+    update_time    bigint       not null comment '更新时间',
+    creator        char(42)     not null comment '创建人',
+    updater        char(42)     not null comment '更新人',
+    unique key uk_oracle_token_block (oracle_address, token_address, block_number),
+    key idx_token_block (token_address, block_number)
+);
+
+create table aave.pool_implementation
+(
+    id                     bigint auto_increment primary key not null comment '主键ID,自增',
+    proxy_address          char(42)                          not null comment '代理合约地址，小写',
+    implementation_address char(42)                          not null comment '实现合约地址，小写',
+    abi_version            varchar(32)                       not null comment '解析该实现合约日志使用的ABI版本',
+    source                 varchar(16)                       not null comment '发现方式，event为Upgraded事件，slot为读取ERC-1967存储槽',
+    tx_hash                char(66)     default ''           not null comment '升级交易哈希，slot方式为空',
+    log_index              int          default 0            not null comment '日志序号，slot方式为0',
+    block_number           bigint                            not null comment '生效区块号，slot方式为发现变化的区块',
+    block_time             bigint                            not null comment '区块时间戳',
+    create_time            bigint                            not null comment '创建时间',
+    update_time            bigint                            not null comment '更新时间',
+    creator                char(42)                          not null comment '创建人',
+    updater                char(42)                          not null comment '更新人',
+    unique key uk_proxy_implementation_block (proxy_address, implementation_address, block_number),
+    key idx_proxy_block (proxy_address, block_number)
+);
+
+create table aave.pool_param
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    param_name     varchar(64)                       not null comment '参数名，与合约getter同名，抵押代币参数为liquidationThreshold、collateralizationRatio',
+    scope          char(42)     default ''           not null comment '参数作用范围，全局参数为空，抵押代币参数为代币地址，小写',
+    value          varchar(100)                      not null comment '参数值，合约精度',
+    previous_value varchar(100) default ''           not null comment '变化前的值，第一个版本为空',
+    version        int                               not null comment '参数版本，同一参数从1开始递增',
+    change_ratio   varchar(32)  default '0'          not null comment '相对变化比例，(value - previous_value) / previous_value',
+    alerted        tinyint(1)   default 0            not null comment '变化是否超过告警阈值',
+    block_number   bigint                            not null comment '读取到变化的区块号',
+    block_time     bigint                            not null comment '读取到变化的区块时间戳',
+    create_time    bigint                            not null comment '创建时间',
+    update_time    bigint                            not null comment '更新时间',
+    creator        char(42)                          not null comment '创建人',
+    updater        char(42)                          not null comment '更新人',
+    unique key uk_param_scope_version (param_name, scope, version),
+    key idx_block_number (block_number)
+);
+
+create table aave.pool_transaction
+(
+    id                  bigint auto_increment primary key not null comment '主键ID,自增',
+    tx_hash             char(66)                          not null comment '交易哈希',
+    tx_index            int                               not null comment '交易在区块中的序号',
+    from_address        char(42)                          not null comment '交易发送方地址，小写',
+    to_address          char(42)     default ''           not null comment '交易接收方地址，小写，创建合约时为空',
+    method_selector     char(10)     default ''           not null comment '调用数据的前4字节，0x开头',
+    method              varchar(64)  default ''           not null comment 'pool合约ABI中的方法名，不是直接调用pool合约或未知方法时为空',
+    status              tinyint                           not null comment '交易状态，1成功，0失败',
+    log_count           int          default 0            not null comment '交易中pool合约发出的日志数量',
+    gas_limit           bigint                            not null comment '交易的gas上限',
+    gas_used            bigint                            not null comment '交易实际消耗的gas',
+    effective_gas_price varchar(100)                      not null comment '实际gas价格，wei',
+    fee                 varchar(100)                      not null comment '交易手续费，gas_used * effective_gas_price，wei',
+    block_number        bigint                            not null comment '区块号',
+    block_time          bigint                            not null comment '区块时间戳',
+    create_time         bigint                            not null comment '创建时间',
+    update_time         bigint                            not null comment '更新时间',
+    creator             char(42)                          not null comment '创建人',
+    updater             char(42)                          not null comment '更新人',
+    unique key uk_tx_hash (tx_hash),
+    key idx_method_time (method, block_time),
+    key idx_status_time (status, block_time),
+    key idx_from_address (from_address)
+);
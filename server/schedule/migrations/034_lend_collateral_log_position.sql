@@ -0,0 +1,30 @@
+-- 已部署的库升级：lend、collateral 增加事件所在的区块号、交易哈希、日志序号，按(tx_hash, log_index)去重
+-- 新建的库直接使用 db.sql，不需要执行本文件
+-- 升级前写入的记录没有事件位置，tx_hash 回填为 legacy-<id> 保证唯一键不冲突，block_number、log_index 保持0，
+-- 按区块排序时排在所有新记录之前
+
+alter table aave.lend
+    add column block_number bigint   not null default 0 comment '事件所在区块号' after apy_interest,
+    add column tx_hash      char(66) not null default '' comment '事件所在交易哈希' after block_number,
+    add column log_index    int      not null default 0 comment '日志序号' after tx_hash;
+
+update aave.lend
+set tx_hash = concat('legacy-', id)
+where tx_hash = '';
+
+alter table aave.lend
+    add unique key uk_tx_log (tx_hash, log_index),
+    add key idx_block_number (block_number);
+
+alter table aave.collateral
+    add column block_number bigint   not null default 0 comment '事件所在区块号' after apy_interest,
+    add column tx_hash      char(66) not null default '' comment '事件所在交易哈希' after block_number,
+    add column log_index    int      not null default 0 comment '日志序号' after tx_hash;
+
+update aave.collateral
+set tx_hash = concat('legacy-', id)
+where tx_hash = '';
+
+alter table aave.collateral
+    add unique key uk_tx_log (tx_hash, log_index),
+    add key idx_block_number (block_number);
@@ -0,0 +1,93 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/collection"
+	"go.uber.org/zap"
+)
+
+// blockTimeCacheSeconds 区块时间缓存时长，区块时间不会变化，缓存时间可以较长
+const blockTimeCacheSeconds = 7 * 24 * 3600
+
+// blockTimeLocalLimit 进程内区块时间LRU缓存的最大条数
+const blockTimeLocalLimit = 10000
+
+func getBlockTimeKey(blockNumber uint64) string {
+	return fmt.Sprintf("aave:block:time:%d", blockNumber)
+}
+
+// newBlockTimeCache 进程内区块时间LRU缓存，挡在redis前面，回填历史区块时减少redis访问
+func newBlockTimeCache() *collection.Cache {
+	cache, err := collection.NewCache(blockTimeCacheSeconds, collection.WithLimit(blockTimeLocalLimit), collection.WithName("block_time"))
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}
+
+// cachedBlockTime 依次从进程内缓存和redis读取区块时间
+func (s *Service) cachedBlockTime(blockNumber uint64) (uint64, bool) {
+	local := strconv.FormatUint(blockNumber, 10)
+	if cached, ok := s.blockTimes.Get(local); ok {
+		return cached.(uint64), true
+	}
+	if cached, err := s.kv.GetInt64(getBlockTimeKey(blockNumber)); err == nil && cached > 0 {
+		s.blockTimes.Set(local, uint64(cached))
+		return uint64(cached), true
+	}
+	return 0, false
+}
+
+func (s *Service) cacheBlockTime(blockNumber, blockTime uint64) {
+	s.blockTimes.Set(strconv.FormatUint(blockNumber, 10), blockTime)
+	_ = s.kv.SetInt64(getBlockTimeKey(blockNumber), int64(blockTime), blockTimeCacheSeconds)
+}
+
+// blockTime 获取区块时间戳，优先读取缓存，未命中时通过 BlockTimeByNumber 查询并写入缓存
+func (s *Service) blockTime(blockNumber uint64) (uint64, error) {
+	if cached, ok := s.cachedBlockTime(blockNumber); ok {
+		return cached, nil
+	}
+	blockTime, err := s.chainClient.BlockTimeByNumber(s.ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed on get block time")
+	}
+	s.cacheBlockTime(blockNumber, blockTime)
+	return blockTime, nil
+}
+
+// prefetchBlockTimes 处理一批日志前，把缓存中没有的区块时间通过一次批量请求取回，
+// 失败时只记录日志，后续处理单条日志时会逐个区块重新查询
+func (s *Service) prefetchBlockTimes(logs []interface{}) {
+	seen := make(map[uint64]bool)
+	var missing []uint64
+	for _, log := range logs {
+		blockNumber := log.(ethereumTypes.Log).BlockNumber
+		if seen[blockNumber] {
+			continue
+		}
+		seen[blockNumber] = true
+		if _, ok := s.cachedBlockTime(blockNumber); !ok {
+			missing = append(missing, blockNumber)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	blockTimes, err := s.chainClient.BlockTimesByNumbers(s.ctx, missing)
+	if err != nil {
+		xzap.WithContext(s.ctx).Warn("failed on prefetch block times", zap.Error(err), zap.Int("blocks", len(missing)))
+		return
+	}
+	for blockNumber, blockTime := range blockTimes {
+		s.cacheBlockTime(blockNumber, blockTime)
+	}
+}
@@ -0,0 +1,89 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// handleUserTokenEvent 处理 (user indexed, collateralToken indexed, amount) 结构的事件，
+// 包括抵押物存取、借款和还款，这几个事件只有金额字段名不同
+func (s *Service) handleUserTokenEvent(log ethereumTypes.Log, eventName string, action string) error {
+	// 检查 topics 数量是否正确（事件签名和2个indexed参数）
+	if len(log.Topics) != 3 {
+		return errors.Errorf("insufficient topics for %s event: %d", eventName, len(log.Topics))
+	}
+
+	user := common.BytesToAddress(log.Topics[1].Bytes())
+	token := common.BytesToAddress(log.Topics[2].Bytes())
+	values, err := s.poolAbi(log.BlockNumber).Unpack(eventName, log.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed on unpack %s event data %s", eventName, common.Bytes2Hex(log.Data))
+	}
+	if len(values) != 1 {
+		return errors.Errorf("unexpected %s event data length: %d", eventName, len(values))
+	}
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return errors.Errorf("unexpected amount type of %s event", eventName)
+	}
+
+	xzap.WithContext(s.ctx).Info(eventName+" event parsed successfully",
+		zap.String("user", user.Hex()),
+		zap.String("token", token.Hex()),
+		zap.String("amount", amount.String()),
+		zap.String("tx_hash", log.TxHash.Hex()),
+		zap.Uint64("block_number", log.BlockNumber),
+		zap.Uint("log_index", log.Index),
+	)
+
+	activity := &types.UserActivity{
+		UserAddress:  strings.ToLower(user.Hex()),
+		Action:       action,
+		TokenAddress: strings.ToLower(token.Hex()),
+		Amount:       amount.String(),
+	}
+	if action == types.ActionRepay {
+		// 还款事件不包含返还给用户的抵押物数量，按合约 borrowRepay 的算法用当时的价格还原
+		returned, err := s.repayCollateralReturned(log, token, amount)
+		if err != nil {
+			xzap.WithContext(s.ctx).Error("Error calculating BorrowRepay collateral returned",
+				zap.String("tx_hash", log.TxHash.Hex()), zap.Error(err))
+		} else {
+			activity.CollateralAmount = returned.String()
+		}
+	}
+	if err := s.saveUserActivity(log, activity); err != nil {
+		return errors.Wrapf(err, "failed on create %s activity", eventName)
+	}
+	return nil
+}
+
+// repayCollateralReturned 还款时合约返还的抵押物数量 = 还款金额 × USDC价格 × DOLLAR_DECIMALS / 抵押物价格
+func (s *Service) repayCollateralReturned(log ethereumTypes.Log, token common.Address, amount *big.Int) (*big.Int, error) {
+	block := new(big.Int).SetUint64(log.BlockNumber)
+	tokenPrice, err := s.callPoolUint256("getTokenPrice", block, token)
+	if err != nil {
+		return nil, err
+	}
+	usdcToken, err := s.callPoolAddress("usdcTokenAddress", block)
+	if err != nil {
+		return nil, err
+	}
+	usdcPrice, err := s.callPoolUint256("getTokenPrice", block, usdcToken)
+	if err != nil {
+		return nil, err
+	}
+	if tokenPrice.Sign() == 0 {
+		return nil, errors.New("collateral price is zero")
+	}
+	returned := new(big.Int).Mul(amount, usdcPrice)
+	returned.Mul(returned, dollarDecimals)
+	return returned.Div(returned, tokenPrice), nil
+}
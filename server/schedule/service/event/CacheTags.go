@@ -0,0 +1,125 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// web服务接口缓存标签，与 aave_web/api/middleware 中的定义保持一致
+const (
+	cacheTagLend             = "lend"
+	cacheTagCollateralPrefix = "collateral:"
+	cacheTagUserPrefix       = "user:"
+)
+
+// stateChange pool合约状态变化通知，web服务据此清除 Tags 对应的接口缓存并推送最新数据
+type stateChange struct {
+	Type  string   `json:"type"`
+	Block uint64   `json:"block"`
+	Tags  []string `json:"tags"`
+}
+
+// notifyStateChange 汇总本批次pool合约日志影响的缓存标签，发送一条状态变化通知
+func (s *Service) notifyStateChange(logs []interface{}, endBlock uint64) {
+	pool := common.HexToAddress(s.cfg.ContractCfg.AavePoolAddress)
+	tokenTypes := s.collateralTypes()
+	tagSet := make(map[string]struct{})
+	for _, log := range logs {
+		ethLog := log.(ethereumTypes.Log)
+		if ethLog.Address != pool {
+			continue
+		}
+		for _, tag := range s.cacheTags(ethLog, tokenTypes) {
+			tagSet[tag] = struct{}{}
+		}
+	}
+	if len(tagSet) == 0 {
+		return
+	}
+	tags := make([]string, 0, len(tagSet))
+	for tag := range tagSet {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	s.eventNotify(&stateChange{Type: "pool_changed", Block: endBlock, Tags: tags})
+}
+
+// lendEvents 改变存款池总存款、总借款或利率指数的事件，需要清除存款池数据
+var lendEvents = map[string]bool{
+	"StatusChanged":   true,
+	"LendDeposited":   true,
+	"LendWithdraw":    true,
+	"BorrowDeposited": true,
+	"BorrowRepay":     true,
+	"Liquidated":      true,
+}
+
+// cacheTags pool合约日志影响的缓存标签
+// lendEvents 更新存款池数据；indexed 的地址参数为抵押代币时更新该抵押资产数据，否则为用户地址，更新该用户数据；
+// 非 indexed 的地址参数（如 Liquidated 的 collateralToken）为抵押代币时也更新该抵押资产数据
+// 无法解析的日志清除存款池数据，保证不会返回过期的数据
+func (s *Service) cacheTags(log ethereumTypes.Log, tokenTypes map[common.Address]int) []string {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	parsedAbi := s.poolAbi(log.BlockNumber)
+	event, err := parsedAbi.EventByID(log.Topics[0])
+	if err != nil {
+		return []string{cacheTagLend}
+	}
+	var tags []string
+	if lendEvents[event.Name] {
+		tags = append(tags, cacheTagLend)
+	}
+	topic := 1
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			continue
+		}
+		if topic >= len(log.Topics) {
+			break
+		}
+		if input.Type.String() == "address" {
+			address := common.BytesToAddress(log.Topics[topic].Bytes())
+			if tokenType, ok := tokenTypes[address]; ok {
+				tags = append(tags, cacheTagCollateralPrefix+strconv.Itoa(tokenType))
+			} else {
+				tags = append(tags, cacheTagUserPrefix+strings.ToLower(address.Hex()))
+			}
+		}
+		topic++
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return append(tags, cacheTagLend)
+	}
+	for _, value := range values {
+		if address, ok := value.(common.Address); ok {
+			if tokenType, ok := tokenTypes[address]; ok {
+				tags = append(tags, cacheTagCollateralPrefix+strconv.Itoa(tokenType))
+			}
+		}
+	}
+	return tags
+}
+
+// collateralTypes 抵押代币地址到抵押资产类型的映射
+func (s *Service) collateralTypes() map[common.Address]int {
+	var tokenAddressMap map[string]int
+	if err := json.Unmarshal([]byte(s.cfg.ContractCfg.TokenAddressMap), &tokenAddressMap); err != nil {
+		xzap.WithContext(s.ctx).Error("Error unmarshalling contract cfg TokenAddressMap", zap.Error(err))
+		return nil
+	}
+	tokenTypes := make(map[common.Address]int, len(tokenAddressMap))
+	for address, tokenType := range tokenAddressMap {
+		tokenTypes[common.HexToAddress(address)] = tokenType
+	}
+	return tokenTypes
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"aave_schedule/chain/abis"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCacheTags(t *testing.T) {
+	parsedAbi, err := abis.Load("../../config/abi/Aave2PoolV3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := newPoolVersions(map[string]abi.ABI{"v3": parsedAbi}, "v3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{pool: pool}
+
+	borrower := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	liquidator := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	token := common.HexToAddress("0x4533840185dF00119F5a3cD8F2379C0160CA875b")
+	tokenTypes := map[common.Address]int{token: 2}
+
+	liquidated := parsedAbi.Events["Liquidated"]
+	liquidatedData, err := liquidated.Inputs.NonIndexed().Pack(token, big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lendDeposited := parsedAbi.Events["LendDeposited"]
+	lendData, err := lendDeposited.Inputs.NonIndexed().Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		log  ethereumTypes.Log
+		want []string
+	}{
+		{
+			name: "liquidated tags lend, both users and the collateral from data",
+			log: ethereumTypes.Log{
+				Topics: []common.Hash{liquidated.ID, common.BytesToHash(borrower.Bytes()), common.BytesToHash(liquidator.Bytes())},
+				Data:   liquidatedData,
+			},
+			want: []string{"collateral:2", "lend", "user:0x00000000000000000000000000000000000000b1", "user:0x00000000000000000000000000000000000000b2"},
+		},
+		{
+			name: "lend deposited tags lend and user",
+			log: ethereumTypes.Log{
+				Topics: []common.Hash{lendDeposited.ID, common.BytesToHash(borrower.Bytes())},
+				Data:   lendData,
+			},
+			want: []string{"lend", "user:0x00000000000000000000000000000000000000b1"},
+		},
+		{
+			name: "unknown event tags lend",
+			log:  ethereumTypes.Log{Topics: []common.Hash{common.HexToHash("0x01")}},
+			want: []string{"lend"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.cacheTags(tt.log, tokenTypes)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cacheTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
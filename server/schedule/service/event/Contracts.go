@@ -0,0 +1,81 @@
+package event
+
+import (
+	"aave_schedule/chain/abis"
+	"aave_schedule/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// oracleContract 预言机合约
+type oracleContract struct {
+	name      string
+	address   common.Address
+	parsedAbi abi.ABI
+}
+
+// contractEvents 各类型合约需要同步的事件
+var contractEvents = map[string][]string{
+	config.ContractKindERC20: {"Transfer", "Approval"},
+}
+
+// contractMethods 各类型合约需要读取的方法
+var contractMethods = map[string][]string{
+	config.ContractKindOracle: {"getTokenPrice"},
+}
+
+// addContracts 把pool合约和配置的其他合约及其ABI加入注册表，并按类型记录代币和预言机合约
+func (s *Service) addContracts() error {
+	if err := s.registry.AddContract(s.cfg.ContractCfg.AavePoolAddress, s.pool.abis[s.pool.current], s.pool.historyAbis()...); err != nil {
+		return errors.Wrap(err, "failed on add pool contract")
+	}
+	for _, contract := range s.cfg.Contracts {
+		parsedAbi, err := abis.Load(contract.AbiFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed on load abi of contract %s", contract.Name)
+		}
+		if err := abis.Require(parsedAbi, contractEvents[contract.Kind], contractMethods[contract.Kind]); err != nil {
+			return errors.Wrapf(err, "invalid abi of contract %s", contract.Name)
+		}
+		address, err := s.contractAddress(contract)
+		if err != nil {
+			return err
+		}
+		if err := s.registry.AddContract(address, parsedAbi); err != nil {
+			return errors.Wrapf(err, "failed on add contract %s", contract.Name)
+		}
+		switch contract.Kind {
+		case config.ContractKindERC20:
+			s.tokenContracts = append(s.tokenContracts, address)
+		case config.ContractKindOracle:
+			s.oracleContracts = append(s.oracleContracts, &oracleContract{
+				name:      contract.Name,
+				address:   common.HexToAddress(address),
+				parsedAbi: parsedAbi,
+			})
+		default:
+			return errors.Errorf("unknown kind %s of contract %s", contract.Kind, contract.Name)
+		}
+	}
+	return nil
+}
+
+// contractAddress 合约地址，未配置地址时调用pool合约的 PoolGetter 方法读取最新区块的地址
+func (s *Service) contractAddress(contract config.Contract) (string, error) {
+	if contract.Address != "" {
+		return contract.Address, nil
+	}
+	if contract.PoolGetter == "" {
+		return "", errors.Errorf("address or pool_getter of contract %s required", contract.Name)
+	}
+	address, err := s.callPoolAddress(contract.PoolGetter, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed on resolve address of contract %s", contract.Name)
+	}
+	if address == (common.Address{}) {
+		return "", errors.Errorf("pool %s of contract %s returned zero address", contract.PoolGetter, contract.Name)
+	}
+	return address.Hex(), nil
+}
@@ -1,6 +1,7 @@
 package event
 
 import (
+	"aave_schedule/chain/abis"
 	"aave_schedule/chain/chainclient"
 	chainTypes "aave_schedule/chain/types"
 	"aave_schedule/config"
@@ -12,24 +13,30 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/collection"
 	"github.com/zeromicro/go-zero/core/threading"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"math/big"
 	"strconv"
-	"strings"
 	"time"
 )
 
 type Service struct {
-	ctx         context.Context
-	cfg         *config.Config
-	db          *gorm.DB
-	kv          *xkv.Store
-	chainClient chainclient.ChainClient
-	chainId     int64
-	chain       string
-	parsedAbi   abi.ABI
+	ctx             context.Context
+	cfg             *config.Config
+	db              *gorm.DB
+	kv              *xkv.Store
+	chainClient     chainclient.ChainClient
+	chainId         int64
+	chain           string
+	pool            *poolVersions // pool合约各版本ABI和实现合约历史
+	blockTimes      *collection.Cache
+	registry        *Registry
+	tokenContracts  []string          // 同步 Transfer、Approval 事件的ERC-20代币合约地址
+	oracleContracts []*oracleContract // 按批次读取价格的预言机合约
 }
 
 var MultiChainMaxBlockDifference = map[string]uint64{
@@ -41,9 +48,26 @@ var MultiChainMaxBlockDifference = map[string]uint64{
 	"zksync-era": 2,
 }
 
-func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, chainClient chainclient.ChainClient, chainId int64, chain string) *Service {
-	parsedAbi, _ := abi.JSON(strings.NewReader(cfg.ContractCfg.AbiJson)) // 通过ABI实例化
-	return &Service{
+// poolMethods 事件处理时读取的pool合约方法
+var poolMethods = []string{
+	"liquidityIndex",
+	"borrowIndex",
+	"lastUpdateTimestamp",
+	"getTokenPrice",
+	"usdcTokenAddress",
+	"liquidationPenaltyFeeRate4Cleaner",
+}
+
+// New 创建事件同步服务，poolAbis 为pool合约各版本的ABI，当前版本缺少处理器需要的方法时返回错误
+func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, chainClient chainclient.ChainClient, chainId int64, chain string, poolAbis map[string]abi.ABI) (*Service, error) {
+	pool, err := newPoolVersions(poolAbis, cfg.ContractCfg.AbiVersion, cfg.ContractCfg.Implementations)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pool abi versions")
+	}
+	if err := abis.Require(poolAbis[cfg.ContractCfg.AbiVersion], nil, poolMethods); err != nil {
+		return nil, errors.Wrap(err, "invalid pool abi")
+	}
+	s := &Service{
 		ctx:         ctx,
 		cfg:         cfg,
 		db:          db,
@@ -51,8 +75,26 @@ func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, c
 		chainClient: chainClient,
 		chain:       chain,
 		chainId:     chainId,
-		parsedAbi:   parsedAbi,
+		pool:        pool,
+		blockTimes:  newBlockTimeCache(),
+		registry:    NewRegistry(ctx, cfg.ContractCfg.AavePoolAddress),
+	}
+	if err := s.loadImplementationHistory(); err != nil {
+		return nil, err
+	}
+	if err := s.addContracts(); err != nil {
+		return nil, err
 	}
+	if err := s.registerHandlers(); err != nil {
+		return nil, errors.Wrap(err, "failed on register event handlers")
+	}
+	xzap.WithContext(ctx).Info("event handlers registered", zap.Any("handlers", s.registry.Handlers()))
+	return s, nil
+}
+
+// Register 注册额外的事件处理器，需要在 Start 之前调用
+func (s *Service) Register(handler Handler) error {
+	return s.registry.Register(handler)
 }
 
 func (s *Service) Start() {
@@ -71,17 +113,19 @@ func (s *Service) SyncAaveEventLoop() {
 		xzap.WithContext(s.ctx).Error("get indexed status error", zap.Error(err))
 		return
 	}
-	if indexedStatus.LastIndexedTime >= time.Now().Unix() {
-		// 上次同步时间大于当前时间，直接返回，说明时间有问题
+	if indexedStatus.LastIndexedTime > time.Now().Unix() {
+		// 上次同步时间大于当前时间，直接返回，说明时间有问题；同步时会更新该时间，同一秒内重启是正常的
 		return
 	}
 	var beginBlockNumber = uint64(indexedStatus.LastIndexedBlock)
+	// indexedTime 最后一次完成同步批次或确认已同步到最新区块的时间，web服务据此判断同步是否停滞
+	var indexedTime = indexedStatus.LastIndexedTime
 	for {
 		// 直接修改indexedStatus的LastIndexedBlock为beginBlockNumber，避免重复同步
 		err := s.db.WithContext(s.ctx).
 			Table(types.GetIndexedStatusTableName()).
 			Where("chain_id = ?", s.chainId).
-			Update("last_indexed_block", beginBlockNumber).Error
+			Updates(map[string]interface{}{"last_indexed_block": beginBlockNumber, "last_indexed_time": indexedTime}).Error
 		if err != nil {
 			xzap.WithContext(s.ctx).Error("update indexed status error", zap.Error(err))
 			time.Sleep(5 * time.Second)
@@ -101,79 +145,101 @@ func (s *Service) SyncAaveEventLoop() {
 			continue
 		}
 		if currentBlockNumber < beginBlockNumber {
-			// 区块高度没有更新，等待5秒后再次尝试
+			// 已同步到最新区块，区块高度没有更新，等待5秒后再次尝试
+			indexedTime = time.Now().Unix()
 			xzap.WithContext(s.ctx).Info("current block number is not updated, wait 5 seconds")
 			time.Sleep(5 * time.Second)
 			continue
 		}
-		// 免费节点限制最多10个，这里设置为5个
-		endBlockNumber := beginBlockNumber + 5
-		if endBlockNumber > currentBlockNumber {
-			endBlockNumber = currentBlockNumber
-		}
-		xzap.WithContext(s.ctx).Info("begin block number is " + strconv.FormatUint(beginBlockNumber, 10) + " end block number is " + strconv.FormatUint(endBlockNumber, 10))
-		// 确定了开始和结束的区块高度，开始同步事件
-		filterQuery := chainTypes.FilterQuery{
-			FromBlock: new(big.Int).SetUint64(beginBlockNumber),
-			ToBlock:   new(big.Int).SetUint64(endBlockNumber),
-			Addresses: []string{s.cfg.ContractCfg.AavePoolAddress},
-		}
-		// 开始查询过滤日志
-		filterLogs, err := s.chainClient.FilterLogs(s.ctx, filterQuery)
+		next, err := s.syncNext(beginBlockNumber, currentBlockNumber)
 		if err != nil {
-			xzap.WithContext(s.ctx).Error("filter logs error, wait 5 seconds", zap.Error(err))
+			// 不移动同步进度，等待5秒后重试本批次，处理器按 uk_tx_log 忽略重复写入，重试是安全的
+			xzap.WithContext(s.ctx).Error("failed on sync batch, retry in 5 seconds", zap.Uint64("begin_block", beginBlockNumber), zap.Error(err))
 			time.Sleep(5 * time.Second)
-			beginBlockNumber = endBlockNumber + 1
 			continue
 		}
-		if len(filterLogs) == 0 {
-			// 没有日志，说明没有新的事件，继续下一个区块
-			beginBlockNumber = endBlockNumber + 1
+		indexedTime = time.Now().Unix()
+		beginBlockNumber = next
+	}
+}
+
+// syncNext 同步从 beginBlockNumber 开始的一个批次，返回下一个批次的开始区块，失败时返回 beginBlockNumber
+func (s *Service) syncNext(beginBlockNumber, currentBlockNumber uint64) (uint64, error) {
+	// 免费节点限制最多10个，这里设置为5个
+	endBlockNumber := beginBlockNumber + 5
+	if endBlockNumber > currentBlockNumber {
+		endBlockNumber = currentBlockNumber
+	}
+	xzap.WithContext(s.ctx).Info("begin block number is " + strconv.FormatUint(beginBlockNumber, 10) + " end block number is " + strconv.FormatUint(endBlockNumber, 10))
+	if err := s.syncBatch(beginBlockNumber, endBlockNumber); err != nil {
+		return beginBlockNumber, err
+	}
+	return endBlockNumber + 1, nil
+}
+
+// syncBatch 同步 [beginBlockNumber, endBlockNumber] 区间的事件，读取日志或任一处理器失败时返回错误
+func (s *Service) syncBatch(beginBlockNumber, endBlockNumber uint64) error {
+	// 确定了开始和结束的区块高度，开始同步事件
+	filterQuery := chainTypes.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(beginBlockNumber),
+		ToBlock:   new(big.Int).SetUint64(endBlockNumber),
+		Addresses: s.registry.Addresses(),
+	}
+	// 开始查询过滤日志
+	filterLogs, err := s.chainClient.FilterLogs(s.ctx, filterQuery)
+	if err != nil {
+		return errors.Wrap(err, "failed on filter logs")
+	}
+	// 读取预言机价格，价格变化没有事件，没有日志的批次也需要读取
+	s.recordOraclePrices(endBlockNumber)
+	// 记录与pool合约交互的交易，失败交易没有日志，没有日志的批次也需要读取
+	s.recordPoolTransactions(beginBlockNumber, endBlockNumber, filterLogs)
+	if len(filterLogs) == 0 {
+		// 没有日志，说明没有新的事件，核对实现合约后继续下一个区块
+		s.checkImplementation(endBlockNumber)
+		return nil
+	}
+	// 批量获取本批次日志所在区块的时间
+	s.prefetchBlockTimes(filterLogs)
+	if err := s.dispatchLogs(filterLogs); err != nil {
+		return err
+	}
+	// 最后通知，pool合约状态发生变化，带上需要清除的缓存标签
+	s.notifyStateChange(filterLogs, endBlockNumber)
+	// 记录本批次日志所在区块的利率指数快照
+	s.recordInterestIndexes(filterLogs)
+	// 分发完 Upgraded 事件后再核对实现合约存储槽
+	s.checkImplementation(endBlockNumber)
+	return nil
+}
+
+// dispatchLogs 分发一批日志，单个处理器失败不影响其它日志和处理器，全部分发后返回失败的日志数
+func (s *Service) dispatchLogs(logs []interface{}) error {
+	failed := 0
+	var lastErr error
+	for _, log := range logs {
+		ethLog := log.(ethereumTypes.Log)
+		handled, err := s.registry.Dispatch(ethLog)
+		if err != nil {
+			failed++
+			lastErr = errors.Wrapf(err, "tx %s log %d", ethLog.TxHash.Hex(), ethLog.Index)
 			continue
 		}
-		for _, log := range filterLogs {
-			ethLog := log.(ethereumTypes.Log)
-			xzap.WithContext(s.ctx).Info("filter log", zap.Any("event_topic", ethLog.Topics[0].String()))
-			switch ethLog.Topics[0].String() {
-			case s.cfg.ContractCfg.DepositLendTopic:
-				xzap.WithContext(s.ctx).Info("handle deposit lend event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleDepositLendEvent(ethLog)
-			case s.cfg.ContractCfg.DepositLendWithdrawTopic:
-				xzap.WithContext(s.ctx).Info("handle deposit lend withdraw event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleDepositLendWithdrawEvent(ethLog)
-			case s.cfg.ContractCfg.DepositBorrowTopic:
-				xzap.WithContext(s.ctx).Info("handle deposit borrow event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleDepositBorrowEvent(ethLog)
-			case s.cfg.ContractCfg.DepositBorrowWithdrawTopic:
-				xzap.WithContext(s.ctx).Info("handle deposit borrow withdraw event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleDepositBorrowWithdrawEvent(ethLog)
-			case s.cfg.ContractCfg.LiquidateTopic:
-				xzap.WithContext(s.ctx).Info("handle liquidate event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleLiquidateEvent(ethLog)
-			case s.cfg.ContractCfg.CalculateBorrowableTopic:
-				xzap.WithContext(s.ctx).Info("handle calculate borrowable event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleCalculateBorrowableEvent(ethLog)
-			case s.cfg.ContractCfg.StatusChangedTopic:
-				xzap.WithContext(s.ctx).Info("handle status changed event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleStatusChangedEvent(ethLog)
-			case s.cfg.ContractCfg.CollateralChangedTopic:
-				xzap.WithContext(s.ctx).Info("handle collateral changed event", zap.String("topic", ethLog.Topics[0].String()))
-				s.handleCollateralChangedEvent(ethLog)
-			default:
-			}
-			// 最后通知，合约状态发生变化
-			s.eventNotify()
+		if handled == 0 {
+			xzap.WithContext(s.ctx).Info("no handler for log", zap.String("tx_hash", ethLog.TxHash.Hex()),
+				zap.Uint("log_index", ethLog.Index), zap.String("address", ethLog.Address.Hex()))
 		}
-		beginBlockNumber = endBlockNumber + 1
 	}
+	if failed > 0 {
+		return errors.Wrapf(lastErr, "%d of %d logs failed", failed, len(logs))
+	}
+	return nil
 }
 
-func (s *Service) handleDepositLendEvent(log ethereumTypes.Log) {
+func (s *Service) handleDepositLendEvent(log ethereumTypes.Log) error {
 	// 检查 topics 数量是否正确（至少要有事件签名和1个indexed参数）
-	if len(log.Topics) < 1 {
-		xzap.WithContext(s.ctx).Error("Insufficient topics for DepositLend event",
-			zap.Int("topics_count", len(log.Topics)))
-		return
+	if len(log.Topics) < 2 {
+		return errors.Errorf("insufficient topics for DepositLend event: %d", len(log.Topics))
 	}
 
 	// 定义事件结构体
@@ -188,14 +254,9 @@ func (s *Service) handleDepositLendEvent(log ethereumTypes.Log) {
 	event.User = common.BytesToAddress(log.Topics[1].Bytes())
 
 	// 2. 解析非 indexed 参数 (pool 和 amount) 从 data 字段
-	err := s.parsedAbi.UnpackIntoInterface(&event, "DepositLend", log.Data)
+	err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(&event, "DepositLend", log.Data)
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error unpacking DepositLend event data",
-			zap.Error(err),
-			zap.String("data_hex", common.Bytes2Hex(log.Data)),
-			zap.Int("data_length", len(log.Data)),
-			zap.String("tx_hash", log.TxHash.Hex()))
-		return
+		return errors.Wrapf(err, "failed on unpack DepositLend event data %s", common.Bytes2Hex(log.Data))
 	}
 
 	// 3. 验证解析结果
@@ -207,7 +268,7 @@ func (s *Service) handleDepositLendEvent(log ethereumTypes.Log) {
 	if event.Amount == nil || event.Amount.Sign() <= 0 {
 		xzap.WithContext(s.ctx).Warn("Amount is zero or negative",
 			zap.String("tx_hash", log.TxHash.Hex()))
-		return
+		return nil
 	}
 
 	// 4. 记录解析成功的信息
@@ -221,35 +282,33 @@ func (s *Service) handleDepositLendEvent(log ethereumTypes.Log) {
 	)
 
 	// 5. todo 修改lend记录，这里简单了，流程已经通了
-
+	return nil
 }
 
-func (s *Service) handleDepositLendWithdrawEvent(log ethereumTypes.Log) {
-
+func (s *Service) handleDepositLendWithdrawEvent(log ethereumTypes.Log) error {
+	return nil
 }
 
-func (s *Service) handleDepositBorrowEvent(log ethereumTypes.Log) {
-
+func (s *Service) handleDepositBorrowEvent(log ethereumTypes.Log) error {
+	return nil
 }
 
-func (s *Service) handleDepositBorrowWithdrawEvent(log ethereumTypes.Log) {
-
+func (s *Service) handleDepositBorrowWithdrawEvent(log ethereumTypes.Log) error {
+	return nil
 }
 
-func (s *Service) handleLiquidateEvent(log ethereumTypes.Log) {
-
+func (s *Service) handleLiquidateEvent(log ethereumTypes.Log) error {
+	return nil
 }
 
-func (s *Service) handleCalculateBorrowableEvent(log ethereumTypes.Log) {
-
+func (s *Service) handleCalculateBorrowableEvent(log ethereumTypes.Log) error {
+	return nil
 }
 
-func (s *Service) handleStatusChangedEvent(log ethereumTypes.Log) {
+func (s *Service) handleStatusChangedEvent(log ethereumTypes.Log) error {
 	// 检查 topics 数量是否正确
 	if len(log.Topics) != 1 {
-		xzap.WithContext(s.ctx).Error("Insufficient topics for StatusChanged event",
-			zap.Int("topics_count", len(log.Topics)))
-		return
+		return errors.Errorf("insufficient topics for StatusChanged event: %d", len(log.Topics))
 	}
 
 	// 定义事件结构体
@@ -261,14 +320,9 @@ func (s *Service) handleStatusChangedEvent(log ethereumTypes.Log) {
 	}
 
 	// 解析非 indexed 参数 (pool 和 amount) 从 data 字段
-	err := s.parsedAbi.UnpackIntoInterface(&event, "StatusChanged", log.Data)
+	err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(&event, "StatusChanged", log.Data)
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error unpacking StatusChanged event data",
-			zap.Error(err),
-			zap.String("data_hex", common.Bytes2Hex(log.Data)),
-			zap.Int("data_length", len(log.Data)),
-			zap.String("tx_hash", log.TxHash.Hex()))
-		return
+		return errors.Wrapf(err, "failed on unpack StatusChanged event data %s", common.Bytes2Hex(log.Data))
 	}
 
 	// 4. 记录解析成功的信息
@@ -281,29 +335,34 @@ func (s *Service) handleStatusChangedEvent(log ethereumTypes.Log) {
 		zap.Uint64("block_number", log.BlockNumber),
 		zap.Uint("log_index", log.Index),
 	)
-	// 5. 直接新增一条lend记录
-	if err := s.db.WithContext(s.ctx).Table(types.GetLendTableName()).Create(&types.Lend{
+	// 5. 直接新增一条lend记录，时间使用事件所在区块的时间，回填历史区块时才能得到正确的历史曲线
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(s.ctx).Table(types.GetLendTableName()).Clauses(clause.OnConflict{DoNothing: true}).Create(&types.Lend{
 		Type:            0,
 		TotalBorrow:     event.TotalBorrow.String(),
 		TotalDeposits:   event.TotalDeposits.String(),
 		UtilizationRate: int(event.UtilizationRate.Int64()),
 		InterestRate:    int(event.InterestRate.Int64()),
-		CreateTime:      int(time.Now().Unix()),
-		UpdateTime:      int(time.Now().Unix()),
+		BlockNumber:     int64(log.BlockNumber),
+		TxHash:          log.TxHash.Hex(),
+		LogIndex:        int(log.Index),
+		CreateTime:      int(blockTime),
+		UpdateTime:      int(blockTime),
 		Creator:         "system",
 		Updater:         "system",
 	}).Error; err != nil {
-		xzap.WithContext(s.ctx).Error("Error creating Lend", zap.Error(err))
+		return errors.Wrap(err, "failed on create lend")
 	}
-
+	return nil
 }
 
-func (s *Service) handleCollateralChangedEvent(log ethereumTypes.Log) {
+func (s *Service) handleCollateralChangedEvent(log ethereumTypes.Log) error {
 	// 检查 topics 数量是否正确
 	if len(log.Topics) != 2 {
-		xzap.WithContext(s.ctx).Error("Insufficient topics for CollateralChanged event",
-			zap.Int("topics_count", len(log.Topics)))
-		return
+		return errors.Errorf("insufficient topics for CollateralChanged event: %d", len(log.Topics))
 	}
 
 	// 定义事件结构体
@@ -322,14 +381,9 @@ func (s *Service) handleCollateralChangedEvent(log ethereumTypes.Log) {
 	event.TokenAddress = common.BytesToAddress(log.Topics[1].Bytes())
 
 	// 解析非 indexed 参数 (pool 和 amount) 从 data 字段
-	err := s.parsedAbi.UnpackIntoInterface(&event, "CollateralChanged", log.Data)
+	err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(&event, "CollateralChanged", log.Data)
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error unpacking CollateralChanged event data",
-			zap.Error(err),
-			zap.String("data_hex", common.Bytes2Hex(log.Data)),
-			zap.Int("data_length", len(log.Data)),
-			zap.String("tx_hash", log.TxHash.Hex()))
-		return
+		return errors.Wrapf(err, "failed on unpack CollateralChanged event data %s", common.Bytes2Hex(log.Data))
 	}
 
 	// 4. 记录解析成功的信息
@@ -351,10 +405,13 @@ func (s *Service) handleCollateralChangedEvent(log ethereumTypes.Log) {
 	var tokenAddressMap map[string]int
 	err = json.Unmarshal([]byte(s.cfg.ContractCfg.TokenAddressMap), &tokenAddressMap)
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error marshalling contract cfg TokenAddressMap", zap.Error(err))
-		return
+		return errors.Wrap(err, "failed on unmarshal contract cfg TokenAddressMap")
 	}
-	if err := s.db.WithContext(s.ctx).Table(types.GetCollateralTableName()).Create(&types.Collateral{
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(s.ctx).Table(types.GetCollateralTableName()).Clauses(clause.OnConflict{DoNothing: true}).Create(&types.Collateral{
 		TokenAddress:          event.TokenAddress.String(),
 		Type:                  tokenAddressMap[event.TokenAddress.String()],
 		Borrowed:              event.Borrowed.String(),
@@ -364,17 +421,28 @@ func (s *Service) handleCollateralChangedEvent(log ethereumTypes.Log) {
 		HealthFactor:          int(event.HealthFactor.Int64()),
 		LiquidationThreshold:  int(event.LiquidationThreshold.Int64()),
 		CollateralizationRate: int(event.CollateralizationRatio.Int64()),
-		CreateTime:            int(time.Now().Unix()),
-		UpdateTime:            int(time.Now().Unix()),
+		BlockNumber:           int64(log.BlockNumber),
+		TxHash:                log.TxHash.Hex(),
+		LogIndex:              int(log.Index),
+		CreateTime:            int(blockTime),
+		UpdateTime:            int(blockTime),
 		Creator:               "system",
 		Updater:               "system",
 	}).Error; err != nil {
-		xzap.WithContext(s.ctx).Error("Error creating Collateral", zap.Error(err))
+		return errors.Wrap(err, "failed on create collateral")
 	}
+	return nil
 }
 
-func (s *Service) eventNotify() {
-	s.kv.Store.Lpush(getNotifyQueue(), "1")
+func (s *Service) eventNotify(change *stateChange) {
+	message, err := json.Marshal(change)
+	if err != nil {
+		xzap.WithContext(s.ctx).Error("Error marshalling state change", zap.Error(err))
+		return
+	}
+	if _, err := s.kv.Store.Lpush(getNotifyQueue(), string(message)); err != nil {
+		xzap.WithContext(s.ctx).Error("Error pushing state change", zap.Error(err))
+	}
 }
 
 func getNotifyQueue() string {
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	"aave_schedule/chain/chainclient"
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// logsClient 只实现同步批次需要的 FilterLogs 和 StorageAt，其它方法调用时panic
+type logsClient struct {
+	chainclient.ChainClient
+	logs []interface{}
+	err  error
+}
+
+func (c *logsClient) FilterLogs(ctx context.Context, q chainTypes.FilterQuery) ([]interface{}, error) {
+	return c.logs, c.err
+}
+
+// StorageAt 实现合约存储槽为空，不记录实现合约
+func (c *logsClient) StorageAt(ctx context.Context, address common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return make([]byte, 32), nil
+}
+
+func TestSyncNextKeepsCursorOnFailure(t *testing.T) {
+	ctx := xzap.ToContext(context.Background(), zap.NewNop())
+	pool := "0x0000000000000000000000000000000000000001"
+	parsedAbi := abis.MustParse(registryTestAbi)
+	registry := NewRegistry(ctx, pool)
+	if err := registry.AddContract(pool, parsedAbi); err != nil {
+		t.Fatal(err)
+	}
+	var handlerErr error
+	if err := registry.Register(NewHandler("ping", "Ping", func(log ethereumTypes.Log) error {
+		return handlerErr
+	})); err != nil {
+		t.Fatal(err)
+	}
+	log := ethereumTypes.Log{Address: common.HexToAddress(pool), Topics: []common.Hash{parsedAbi.Events["Ping"].ID}, BlockNumber: 102}
+	client := &logsClient{logs: []interface{}{log}}
+	s := &Service{
+		ctx:         ctx,
+		cfg:         &config.Config{},
+		chainClient: client,
+		registry:    registry,
+		blockTimes:  newBlockTimeCache(),
+	}
+	// 区块时间已缓存，不需要读取链上和Redis
+	s.blockTimes.Set(strconv.FormatUint(log.BlockNumber, 10), uint64(1700000000))
+
+	// 处理器失败时不移动同步进度，下一次重试同一批次
+	handlerErr = errors.New("mysql gone away")
+	next, err := s.syncNext(100, 200)
+	if err == nil || next != 100 {
+		t.Fatalf("next = %d, err = %v, want cursor kept at 100 with error", next, err)
+	}
+
+	// 读取日志失败时同样不移动
+	client.err = errors.New("rpc timeout")
+	if next, err := s.syncNext(100, 200); err == nil || next != 100 {
+		t.Fatalf("next = %d, err = %v, want cursor kept at 100 with error", next, err)
+	}
+
+	// 没有日志时移动到批次之后
+	client.err, client.logs = nil, nil
+	next, err = s.syncNext(100, 200)
+	if err != nil || next != 106 {
+		t.Fatalf("next = %d, err = %v, want 106", next, err)
+	}
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"aave_schedule/types"
+	"errors"
+
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// registerHandlers 注册pool合约和ERC-20代币合约事件的处理器
+func (s *Service) registerHandlers() error {
+	handlers := []Handler{
+		NewHandler("deposit_lend", "DepositLend", s.handleDepositLendEvent),
+		NewHandler("deposit_lend_withdraw", "DepositLendWithdraw", s.handleDepositLendWithdrawEvent),
+		NewHandler("deposit_borrow", "DepositBorrow", s.handleDepositBorrowEvent),
+		NewHandler("deposit_borrow_withdraw", "DepositBorrowWithdraw", s.handleDepositBorrowWithdrawEvent),
+		NewHandler("liquidate", "Liquidate", s.handleLiquidateEvent),
+		NewHandler("calculate_borrowable", "CalculateBorrowable", s.handleCalculateBorrowableEvent),
+		// 利用率、利率快照
+		NewHandler("lend_snapshot", "StatusChanged", s.handleStatusChangedEvent),
+		NewHandler("collateral_snapshot", "CollateralChanged", s.handleCollateralChangedEvent),
+		// 用户操作记录
+		NewHandler("lend_deposited_activity", "LendDeposited", s.handleLendDepositedEvent),
+		NewHandler("lend_withdraw_activity", "LendWithdraw", s.handleLendWithdrawEvent),
+		s.userTokenHandler("collateral_deposit_activity", "DepositCollateral", types.ActionCollateralDeposit),
+		s.userTokenHandler("collateral_withdraw_activity", "DepositCollateralWithdraw", types.ActionCollateralWithdraw),
+		s.userTokenHandler("borrow_activity", "BorrowDeposited", types.ActionBorrow),
+		s.userTokenHandler("repay_activity", "BorrowRepay", types.ActionRepay),
+		// 清算记录
+		NewHandler("liquidation", "Liquidated", s.handleLiquidatedEvent),
+		// 代理合约升级，切换之后区块解析日志使用的ABI
+		NewHandler("pool_upgrade", "Upgraded", s.handleUpgradedEvent),
+	}
+	// ERC-20代币的转账和授权
+	if len(s.tokenContracts) > 0 {
+		handlers = append(handlers,
+			NewHandler("token_transfer", "Transfer", s.handleTransferEvent, s.tokenContracts...),
+			NewHandler("token_approval", "Approval", s.handleApprovalEvent, s.tokenContracts...),
+		)
+	}
+	// 校验全部处理器后一次返回所有错误，方便一次修正配置
+	var errs []error
+	for _, handler := range handlers {
+		if err := s.registry.Register(handler); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// userTokenHandler 处理 (user, collateralToken, amount) 结构的用户操作事件
+func (s *Service) userTokenHandler(name, event, action string) Handler {
+	return NewHandler(name, event, func(log ethereumTypes.Log) error {
+		return s.handleUserTokenEvent(log, event, action)
+	})
+}
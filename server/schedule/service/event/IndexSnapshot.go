@@ -0,0 +1,116 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// recordInterestIndexes 对日志涉及的每个区块，读取合约当时的存款/借款指数并落库
+// 指数只在有交互的区块变化，所以只需要在有日志的区块做快照
+func (s *Service) recordInterestIndexes(logs []interface{}) {
+	var lastBlock uint64
+	for _, l := range logs {
+		blockNumber := l.(ethereumTypes.Log).BlockNumber
+		if blockNumber == lastBlock {
+			continue
+		}
+		lastBlock = blockNumber
+		if err := s.recordInterestIndex(blockNumber); err != nil {
+			xzap.WithContext(s.ctx).Error("failed on record interest index",
+				zap.Uint64("block_number", blockNumber), zap.Error(err))
+		}
+	}
+}
+
+func (s *Service) recordInterestIndex(blockNumber uint64) error {
+	block := new(big.Int).SetUint64(blockNumber)
+	liquidityIndex, err := s.callPoolUint256("liquidityIndex", block)
+	if err != nil {
+		return err
+	}
+	borrowIndex, err := s.callPoolUint256("borrowIndex", block)
+	if err != nil {
+		return err
+	}
+	lastUpdateTimestamp, err := s.callPoolUint256("lastUpdateTimestamp", block)
+	if err != nil {
+		return err
+	}
+	blockTime, err := s.blockTime(blockNumber)
+	if err != nil {
+		return err
+	}
+
+	now := int(time.Now().Unix())
+	return s.db.WithContext(s.ctx).
+		Table(types.GetInterestIndexTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.InterestIndex{
+			BlockNumber:         int64(blockNumber),
+			BlockTime:           int64(blockTime),
+			LiquidityIndex:      liquidityIndex.String(),
+			BorrowIndex:         borrowIndex.String(),
+			LastUpdateTimestamp: lastUpdateTimestamp.Int64(),
+			CreateTime:          now,
+			UpdateTime:          now,
+			Creator:             "system",
+			Updater:             "system",
+		}).Error
+}
+
+// callPoolUint256 在指定区块调用池子合约返回单个uint256的view函数
+func (s *Service) callPoolUint256(method string, blockNumber *big.Int, args ...interface{}) (*big.Int, error) {
+	values, err := s.callPool(method, blockNumber, args...)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, errors.Errorf("unexpected %s output type", method)
+	}
+	return value, nil
+}
+
+// callPoolAddress 在指定区块调用池子合约返回单个address的view函数
+func (s *Service) callPoolAddress(method string, blockNumber *big.Int, args ...interface{}) (common.Address, error) {
+	values, err := s.callPool(method, blockNumber, args...)
+	if err != nil {
+		return common.Address{}, err
+	}
+	value, ok := values[0].(common.Address)
+	if !ok {
+		return common.Address{}, errors.Errorf("unexpected %s output type", method)
+	}
+	return value, nil
+}
+
+// callPool 在指定区块调用池子合约只有一个返回值的view函数
+func (s *Service) callPool(method string, blockNumber *big.Int, args ...interface{}) ([]interface{}, error) {
+	parsedAbi := s.poolAbiAtBlock(blockNumber)
+	data, err := parsedAbi.Pack(method, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on pack %s", method)
+	}
+	pool := common.HexToAddress(s.cfg.ContractCfg.AavePoolAddress)
+	out, err := s.chainClient.CallContract(s.ctx, ethereum.CallMsg{To: &pool, Data: data}, blockNumber)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on call %s", method)
+	}
+	values, err := parsedAbi.Unpack(method, out)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on unpack %s", method)
+	}
+	if len(values) != 1 {
+		return nil, errors.Errorf("unexpected %s outputs: %d", method, len(values))
+	}
+	return values, nil
+}
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/stores/xkv"
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 不连接数据库的gorm实例，只生成SQL，写入成功的记录按顺序收集到 created 中
+func dryRunDB(t *testing.T) (*gorm.DB, *[]interface{}) {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:3306)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []interface{}
+	if err := db.Callback().Create().After("gorm:create").Register("test:collect", func(tx *gorm.DB) {
+		if tx.Error == nil {
+			created = append(created, tx.Statement.Dest)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return db, &created
+}
+
+// newTestPoolService 使用v3 pool ABI和内存键值存储的服务，区块时间已缓存
+func newTestPoolService(t *testing.T, blocks ...uint64) (*Service, abi.ABI, *[]interface{}) {
+	t.Helper()
+	ctx := xzap.ToContext(context.Background(), zap.NewNop())
+	parsedAbi, err := abis.Load("../../config/abi/Aave2PoolV3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := newPoolVersions(map[string]abi.ABI{"v3": parsedAbi}, "v3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, created := dryRunDB(t)
+	s := &Service{
+		ctx:        ctx,
+		cfg:        &config.Config{ContractCfg: config.ContractCfg{AavePoolAddress: "0x0000000000000000000000000000000000000001"}},
+		db:         db,
+		kv:         &xkv.Store{Store: &memoryKV{values: make(map[string]string)}},
+		pool:       pool,
+		blockTimes: newBlockTimeCache(),
+	}
+	for _, block := range blocks {
+		s.blockTimes.Set(strconv.FormatUint(block, 10), 1700000000+block)
+	}
+	return s, parsedAbi, created
+}
+
+// 同一区块的多条日志只读取和记录一次指数快照，读取使用日志所在区块
+func TestRecordInterestIndexesOncePerBlock(t *testing.T) {
+	s, parsedAbi, created := newTestPoolService(t, 100, 101)
+	calls := make(map[string]int)
+	s.chainClient = &poolCallClient{
+		parsedAbi: parsedAbi,
+		call: func(method string, blockNumber *big.Int, args []interface{}) ([]interface{}, error) {
+			calls[method+"@"+blockNumber.String()]++
+			switch method {
+			case "liquidityIndex":
+				return []interface{}{new(big.Int).Add(ray(), blockNumber)}, nil
+			case "borrowIndex":
+				return []interface{}{new(big.Int).Sub(ray(), blockNumber)}, nil
+			case "lastUpdateTimestamp":
+				return []interface{}{big.NewInt(1700000000)}, nil
+			}
+			return nil, errors.Errorf("unexpected call %s", method)
+		},
+	}
+
+	s.recordInterestIndexes([]interface{}{
+		ethereumTypes.Log{BlockNumber: 100, Index: 0},
+		ethereumTypes.Log{BlockNumber: 100, Index: 1},
+		ethereumTypes.Log{BlockNumber: 101, Index: 0},
+	})
+
+	for _, key := range []string{"liquidityIndex@100", "borrowIndex@100", "lastUpdateTimestamp@100", "liquidityIndex@101"} {
+		if calls[key] != 1 {
+			t.Fatalf("%s called %d times, want 1", key, calls[key])
+		}
+	}
+	if len(*created) != 2 {
+		t.Fatalf("created %d snapshots, want 2", len(*created))
+	}
+	for i, block := range []int64{100, 101} {
+		snapshot := (*created)[i].(*types.InterestIndex)
+		if snapshot.BlockNumber != block || snapshot.BlockTime != 1700000000+block {
+			t.Fatalf("snapshot %d = %+v", i, snapshot)
+		}
+		if snapshot.LiquidityIndex != new(big.Int).Add(ray(), big.NewInt(block)).String() ||
+			snapshot.BorrowIndex != new(big.Int).Sub(ray(), big.NewInt(block)).String() {
+			t.Fatalf("snapshot %d indexes = %s, %s", i, snapshot.LiquidityIndex, snapshot.BorrowIndex)
+		}
+	}
+}
+
+func ray() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+}
@@ -0,0 +1,94 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func (s *Service) handleLendDepositedEvent(log ethereumTypes.Log) error {
+	// 检查 topics 数量是否正确（事件签名和1个indexed参数）
+	if len(log.Topics) != 2 {
+		return errors.Errorf("insufficient topics for LendDeposited event: %d", len(log.Topics))
+	}
+
+	// 定义事件结构体
+	var event struct {
+		User           common.Address // indexed 参数，从 topics 中解析
+		Amount         *big.Int       // 非 indexed 参数，从 data 中解析
+		LiquidityIndex *big.Int       // 非 indexed 参数，从 data 中解析
+	}
+	event.User = common.BytesToAddress(log.Topics[1].Bytes())
+
+	err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(&event, "LendDeposited", log.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed on unpack LendDeposited event data %s", common.Bytes2Hex(log.Data))
+	}
+
+	xzap.WithContext(s.ctx).Info("LendDeposited event parsed successfully",
+		zap.String("user", event.User.Hex()),
+		zap.String("amount", event.Amount.String()),
+		zap.String("liquidityIndex", event.LiquidityIndex.String()),
+		zap.String("tx_hash", log.TxHash.Hex()),
+		zap.Uint64("block_number", log.BlockNumber),
+		zap.Uint("log_index", log.Index),
+	)
+
+	// 保存存款记录，收益计算需要存款时的指数
+	if err := s.saveUserActivity(log, &types.UserActivity{
+		UserAddress:    strings.ToLower(event.User.Hex()),
+		Action:         types.ActionLendDeposit,
+		Amount:         event.Amount.String(),
+		Interest:       "0",
+		LiquidityIndex: event.LiquidityIndex.String(),
+	}); err != nil {
+		return errors.Wrap(err, "failed on create LendDeposited activity")
+	}
+	return nil
+}
+
+func (s *Service) handleLendWithdrawEvent(log ethereumTypes.Log) error {
+	// 检查 topics 数量是否正确（事件签名和1个indexed参数）
+	if len(log.Topics) != 2 {
+		return errors.Errorf("insufficient topics for LendWithdraw event: %d", len(log.Topics))
+	}
+
+	// 定义事件结构体
+	var event struct {
+		User     common.Address // indexed 参数，从 topics 中解析
+		Amount   *big.Int       // 非 indexed 参数，从 data 中解析
+		Interest *big.Int       // 非 indexed 参数，从 data 中解析
+	}
+	event.User = common.BytesToAddress(log.Topics[1].Bytes())
+
+	err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(&event, "LendWithdraw", log.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed on unpack LendWithdraw event data %s", common.Bytes2Hex(log.Data))
+	}
+
+	xzap.WithContext(s.ctx).Info("LendWithdraw event parsed successfully",
+		zap.String("user", event.User.Hex()),
+		zap.String("amount", event.Amount.String()),
+		zap.String("interest", event.Interest.String()),
+		zap.String("tx_hash", log.TxHash.Hex()),
+		zap.Uint64("block_number", log.BlockNumber),
+		zap.Uint("log_index", log.Index),
+	)
+
+	// 保存取款记录，interest为本次取款实现的利息
+	if err := s.saveUserActivity(log, &types.UserActivity{
+		UserAddress: strings.ToLower(event.User.Hex()),
+		Action:      types.ActionLendWithdraw,
+		Amount:      event.Amount.String(),
+		Interest:    event.Interest.String(),
+	}); err != nil {
+		return errors.Wrap(err, "failed on create LendWithdraw activity")
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// liquidatedEvent Liquidated 事件，LiquidatedAmount 为代还的USDC数量，CollateralSeized 为清算人获得的抵押物（含奖励）
+type liquidatedEvent struct {
+	Borrower         common.Address // indexed 参数，从 topics 中解析
+	Liquidator       common.Address // indexed 参数，从 topics 中解析
+	CollateralToken  common.Address // 非 indexed 参数，从 data 中解析
+	LiquidatedAmount *big.Int       // 非 indexed 参数，从 data 中解析
+	CollateralSeized *big.Int       // 非 indexed 参数，从 data 中解析
+}
+
+// parseLiquidatedEvent 按日志所在区块的pool ABI解析 Liquidated 事件
+func (s *Service) parseLiquidatedEvent(log ethereumTypes.Log) (*liquidatedEvent, error) {
+	// 检查 topics 数量是否正确（事件签名和2个indexed参数）
+	if len(log.Topics) != 3 {
+		return nil, errors.Errorf("insufficient topics for Liquidated event: %d", len(log.Topics))
+	}
+	event := &liquidatedEvent{
+		Borrower:   common.BytesToAddress(log.Topics[1].Bytes()),
+		Liquidator: common.BytesToAddress(log.Topics[2].Bytes()),
+	}
+	if err := s.poolAbi(log.BlockNumber).UnpackIntoInterface(event, "Liquidated", log.Data); err != nil {
+		return nil, errors.Wrapf(err, "failed on unpack Liquidated event data %s", common.Bytes2Hex(log.Data))
+	}
+	return event, nil
+}
+
+func (s *Service) handleLiquidatedEvent(log ethereumTypes.Log) error {
+	event, err := s.parseLiquidatedEvent(log)
+	if err != nil {
+		return err
+	}
+
+	xzap.WithContext(s.ctx).Info("Liquidated event parsed successfully",
+		zap.String("borrower", event.Borrower.Hex()),
+		zap.String("liquidator", event.Liquidator.Hex()),
+		zap.String("collateralToken", event.CollateralToken.Hex()),
+		zap.String("liquidatedAmount", event.LiquidatedAmount.String()),
+		zap.String("collateralSeized", event.CollateralSeized.String()),
+		zap.String("tx_hash", log.TxHash.Hex()),
+		zap.Uint64("block_number", log.BlockNumber),
+		zap.Uint("log_index", log.Index),
+	)
+
+	borrower := strings.ToLower(event.Borrower.Hex())
+	liquidator := strings.ToLower(event.Liquidator.Hex())
+	token := strings.ToLower(event.CollateralToken.Hex())
+	// 借款人和清算人各记一条，双方都能在自己的操作记录中看到这次清算
+	for _, activity := range []*types.UserActivity{
+		{UserAddress: borrower, Action: types.ActionLiquidated, Counterparty: liquidator},
+		{UserAddress: liquidator, Action: types.ActionLiquidate, Counterparty: borrower},
+	} {
+		activity.TokenAddress = token
+		activity.Amount = event.LiquidatedAmount.String()
+		activity.CollateralAmount = event.CollateralSeized.String()
+		if err := s.saveUserActivity(log, activity); err != nil {
+			return errors.Wrap(err, "failed on create Liquidated activity")
+		}
+	}
+
+	liquidation := &types.Liquidation{
+		Borrower:         borrower,
+		Liquidator:       liquidator,
+		CollateralToken:  token,
+		LiquidatedAmount: event.LiquidatedAmount.String(),
+		CollateralSeized: event.CollateralSeized.String(),
+	}
+	// 估值失败时仍然保存清算记录，估值字段保持为0
+	if err := s.valueLiquidation(log, event.CollateralToken, event.LiquidatedAmount, liquidation); err != nil {
+		xzap.WithContext(s.ctx).Error("Error valuing Liquidated event",
+			zap.String("tx_hash", log.TxHash.Hex()), zap.Error(err))
+	}
+	if err := s.saveLiquidation(log, liquidation); err != nil {
+		return errors.Wrap(err, "failed on create Liquidated record")
+	}
+	return nil
+}
+
+// tokenDecimals 合约中的 TOKEN_DECIMALS，代币数量均按18位精度计算
+var tokenDecimals = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// rateDecimals 合约中的 RATE_DECIMALS，清算奖励率为6位精度
+var rateDecimals = big.NewInt(1e6)
+
+// dollarDecimals 合约中的 DOLLAR_DECIMALS，预言机价格为6位精度
+var dollarDecimals = big.NewInt(1e6)
+
+// valueLiquidation 读取清算所在区块的预言机价格和清算奖励率，按合约 liquidate 的算法还原清算奖励并折算美元价值
+// 合约中 抵押物基础数量 = 代还金额 × USDC价格 / 抵押物价格，奖励 = 基础数量 × 奖励率 / RATE_DECIMALS
+func (s *Service) valueLiquidation(log ethereumTypes.Log, collateralToken common.Address, liquidatedAmount *big.Int, liquidation *types.Liquidation) error {
+	block := new(big.Int).SetUint64(log.BlockNumber)
+	penaltyRate, err := s.callPoolUint256("liquidationPenaltyFeeRate4Cleaner", block)
+	if err != nil {
+		return err
+	}
+	collateralPrice, err := s.callPoolUint256("getTokenPrice", block, collateralToken)
+	if err != nil {
+		return err
+	}
+	usdcToken, err := s.callPoolAddress("usdcTokenAddress", block)
+	if err != nil {
+		return err
+	}
+	usdcPrice, err := s.callPoolUint256("getTokenPrice", block, usdcToken)
+	if err != nil {
+		return err
+	}
+	if collateralPrice.Sign() == 0 {
+		return errors.New("collateral price is zero")
+	}
+
+	seized, _ := new(big.Int).SetString(liquidation.CollateralSeized, 10)
+	baseAmount := new(big.Int).Div(new(big.Int).Mul(liquidatedAmount, usdcPrice), collateralPrice)
+	bonusAmount := new(big.Int).Div(new(big.Int).Mul(baseAmount, penaltyRate), rateDecimals)
+
+	liquidation.PenaltyRate = penaltyRate.String()
+	liquidation.CollateralPrice = collateralPrice.String()
+	liquidation.UsdcPrice = usdcPrice.String()
+	liquidation.BonusAmount = bonusAmount.String()
+	liquidation.DebtValueUsd = usdValue(liquidatedAmount, usdcPrice).String()
+	liquidation.SeizedValueUsd = usdValue(seized, collateralPrice).String()
+	liquidation.BonusValueUsd = usdValue(bonusAmount, collateralPrice).String()
+	return nil
+}
+
+// usdValue 代币数量按价格折算为美元价值，结果为美元6位精度
+func usdValue(amount, price *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(amount, price), tokenDecimals)
+}
+
+// saveLiquidation 补全区块信息后保存清算记录，以(tx_hash, log_index)去重
+func (s *Service) saveLiquidation(log ethereumTypes.Log, liquidation *types.Liquidation) error {
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+
+	now := int(time.Now().Unix())
+	liquidation.TxHash = log.TxHash.Hex()
+	liquidation.LogIndex = int(log.Index)
+	liquidation.BlockNumber = int64(log.BlockNumber)
+	liquidation.BlockTime = int64(blockTime)
+	liquidation.CreateTime = now
+	liquidation.UpdateTime = now
+	liquidation.Creator = "system"
+	liquidation.Updater = "system"
+	return s.db.WithContext(s.ctx).
+		Table(types.GetLiquidationTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(liquidation).Error
+}
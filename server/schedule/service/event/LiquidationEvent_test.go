@@ -0,0 +1,127 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// poolCallClient 按方法名、区块和参数返回pool合约只读调用的结果
+type poolCallClient struct {
+	logsClient
+	parsedAbi abi.ABI
+	call      func(method string, blockNumber *big.Int, args []interface{}) ([]interface{}, error)
+}
+
+func (c *poolCallClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := c.parsedAbi.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	values, err := c.call(method.Name, blockNumber, args)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(values...)
+}
+
+func TestLiquidationValuedAtBlockPrices(t *testing.T) {
+	s, parsedAbi, _ := newTestPoolService(t)
+	weth := common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	usdc := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+
+	// 代还 1000 USDC，抵押物价格 $2000，USDC价格 $1
+	ether := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	liquidatedAmount := new(big.Int).Mul(big.NewInt(1000), ether)
+	collateralPrice := big.NewInt(2000e6)
+	usdcPrice := big.NewInt(1e6)
+	// 按合约 liquidate 计算清算人获得的抵押物：基础数量 0.5，奖励 0.025
+	baseAmount := new(big.Int).Div(new(big.Int).Mul(liquidatedAmount, usdcPrice), collateralPrice)
+	bonusAmount := new(big.Int).Div(new(big.Int).Mul(baseAmount, big.NewInt(50000)), big.NewInt(1e6))
+	seized := new(big.Int).Add(baseAmount, bonusAmount)
+
+	event := parsedAbi.Events["Liquidated"]
+	data, err := event.Inputs.NonIndexed().Pack(weth, liquidatedAmount, seized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	borrower := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	liquidator := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	log := ethereumTypes.Log{
+		Topics:      []common.Hash{event.ID, common.BytesToHash(borrower.Bytes()), common.BytesToHash(liquidator.Bytes())},
+		Data:        data,
+		BlockNumber: 100,
+	}
+
+	parsed, err := s.parseLiquidatedEvent(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Borrower != borrower || parsed.Liquidator != liquidator || parsed.CollateralToken != weth ||
+		parsed.LiquidatedAmount.Cmp(liquidatedAmount) != 0 || parsed.CollateralSeized.Cmp(seized) != 0 {
+		t.Fatalf("parsed = %+v", parsed)
+	}
+
+	// 只有清算所在区块返回预言机价格和奖励率（5%，6位精度）
+	s.chainClient = &poolCallClient{
+		parsedAbi: parsedAbi,
+		call: func(method string, blockNumber *big.Int, args []interface{}) ([]interface{}, error) {
+			if blockNumber == nil || blockNumber.Uint64() != log.BlockNumber {
+				return nil, errors.Errorf("%s called at block %v, want %d", method, blockNumber, log.BlockNumber)
+			}
+			switch method {
+			case "liquidationPenaltyFeeRate4Cleaner":
+				return []interface{}{big.NewInt(50000)}, nil
+			case "usdcTokenAddress":
+				return []interface{}{usdc}, nil
+			case "getTokenPrice":
+				if args[0].(common.Address) == weth {
+					return []interface{}{collateralPrice}, nil
+				}
+				return []interface{}{usdcPrice}, nil
+			}
+			return nil, errors.Errorf("unexpected call %s", method)
+		},
+	}
+
+	liquidation := &types.Liquidation{CollateralSeized: parsed.CollateralSeized.String()}
+	if err := s.valueLiquidation(log, parsed.CollateralToken, parsed.LiquidatedAmount, liquidation); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"PenaltyRate":     "50000",
+		"CollateralPrice": "2000000000",
+		"UsdcPrice":       "1000000",
+		"BonusAmount":     "25000000000000000",
+		// 美元价值为6位精度：债务 $1000，扣押 $1050，奖励 $50
+		"DebtValueUsd":   "1000000000",
+		"SeizedValueUsd": "1050000000",
+		"BonusValueUsd":  "50000000",
+	}
+	got := map[string]string{
+		"PenaltyRate":     liquidation.PenaltyRate,
+		"CollateralPrice": liquidation.CollateralPrice,
+		"UsdcPrice":       liquidation.UsdcPrice,
+		"BonusAmount":     liquidation.BonusAmount,
+		"DebtValueUsd":    liquidation.DebtValueUsd,
+		"SeizedValueUsd":  liquidation.SeizedValueUsd,
+		"BonusValueUsd":   liquidation.BonusValueUsd,
+	}
+	for field, value := range want {
+		if got[field] != value {
+			t.Fatalf("%s = %s, want %s", field, got[field], value)
+		}
+	}
+}
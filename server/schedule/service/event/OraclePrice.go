@@ -0,0 +1,98 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// oraclePriceCacheSeconds 最近一次价格的缓存时长
+const oraclePriceCacheSeconds = 7 * 24 * 3600
+
+func getOraclePriceKey(oracle, token string) string {
+	return fmt.Sprintf("aave:oracle:price:%s:%s", oracle, token)
+}
+
+// recordOraclePrices 预言机设置价格时不发出事件，在每个同步批次的结束区块读取所有代币的价格，
+// 和上一次记录的价格不同时保存一条价格记录
+func (s *Service) recordOraclePrices(blockNumber uint64) {
+	if len(s.oracleContracts) == 0 || len(s.tokenContracts) == 0 {
+		return
+	}
+	block := new(big.Int).SetUint64(blockNumber)
+	for _, oracle := range s.oracleContracts {
+		msgs := make([]ethereum.CallMsg, len(s.tokenContracts))
+		for i, token := range s.tokenContracts {
+			data, err := oracle.parsedAbi.Pack("getTokenPrice", common.HexToAddress(token))
+			if err != nil {
+				xzap.WithContext(s.ctx).Error("failed on pack getTokenPrice", zap.String("oracle", oracle.name), zap.Error(err))
+				return
+			}
+			msgs[i] = ethereum.CallMsg{To: &oracle.address, Data: data}
+		}
+		results, err := s.chainClient.BatchCallContract(s.ctx, msgs, block)
+		if err != nil {
+			xzap.WithContext(s.ctx).Error("failed on read oracle prices", zap.String("oracle", oracle.name),
+				zap.Uint64("block_number", blockNumber), zap.Error(err))
+			continue
+		}
+		for i, result := range results {
+			// 未设置价格的代币调用会revert，跳过
+			if result.Err != nil {
+				continue
+			}
+			values, err := oracle.parsedAbi.Unpack("getTokenPrice", result.Data)
+			if err != nil || len(values) != 1 {
+				continue
+			}
+			price, ok := values[0].(*big.Int)
+			if !ok {
+				continue
+			}
+			if err := s.saveOraclePrice(oracle, s.tokenContracts[i], price, blockNumber); err != nil {
+				xzap.WithContext(s.ctx).Error("failed on save oracle price", zap.String("oracle", oracle.name),
+					zap.String("token", s.tokenContracts[i]), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Service) saveOraclePrice(oracle *oracleContract, token string, price *big.Int, blockNumber uint64) error {
+	oracleAddress := strings.ToLower(oracle.address.Hex())
+	tokenAddress := strings.ToLower(token)
+	key := getOraclePriceKey(oracleAddress, tokenAddress)
+	if last, err := s.kv.Get(key); err == nil && last == price.String() {
+		return nil
+	}
+	blockTime, err := s.blockTime(blockNumber)
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	if err := s.db.WithContext(s.ctx).
+		Table(types.GetTokenPriceTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.TokenPrice{
+			OracleAddress: oracleAddress,
+			TokenAddress:  tokenAddress,
+			Price:         price.String(),
+			BlockNumber:   int64(blockNumber),
+			BlockTime:     int64(blockTime),
+			CreateTime:    now,
+			UpdateTime:    now,
+			Creator:       "system",
+			Updater:       "system",
+		}).Error; err != nil {
+		return errors.Wrap(err, "failed on create token price")
+	}
+	return s.kv.SetString(key, price.String(), oraclePriceCacheSeconds)
+}
@@ -0,0 +1,274 @@
+package event
+
+import (
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// poolTxs 与pool合约交互的交易数量，status 为 success 或 failed
+	poolTxs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "pool_tx",
+		Name:      "total",
+		Help:      "Number of transactions interacting with the pool, by method and status.",
+	}, []string{"method", "status"})
+	// poolTxGasUsed 与pool合约交互的交易消耗的gas
+	poolTxGasUsed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "pool_tx",
+		Name:      "gas_used_total",
+		Help:      "Gas used by transactions interacting with the pool, by method.",
+	}, []string{"method"})
+	// poolTxFailedAlerts 失败交易数量达到告警阈值的次数
+	poolTxFailedAlerts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "pool_tx",
+		Name:      "failed_alerts_total",
+		Help:      "Number of times failed transactions of a method reached the alert threshold.",
+	}, []string{"method"})
+)
+
+func init() {
+	prometheus.MustRegister(poolTxs, poolTxGasUsed, poolTxFailedAlerts)
+}
+
+// poolTxMaxBlocks 每个同步批次最多记录的区块数，交易记录落后于同步进度时分批追赶
+const poolTxMaxBlocks = 50
+
+func getPoolTxCursorKey(chainId int64) string {
+	return fmt.Sprintf("aave:pool_tx:cursor:%d", chainId)
+}
+
+// recordPoolTransactions 记录与pool合约交互的交易和gas消耗，从上次记录到的区块继续，最多记录到 endBlock
+// 读取区块或收据失败时不移动游标，下一个同步批次重试；logs 为 [beginBlock, endBlock] 区间的日志
+func (s *Service) recordPoolTransactions(beginBlock, endBlock uint64, logs []interface{}) {
+	if !s.cfg.TxCfg.Enable {
+		return
+	}
+	from := beginBlock
+	if cursor, err := s.kv.GetInt64(getPoolTxCursorKey(s.chainId)); err == nil && cursor > 0 {
+		from = uint64(cursor) + 1
+	}
+	if from > endBlock {
+		return
+	}
+	to := endBlock
+	if to-from+1 > poolTxMaxBlocks {
+		to = from + poolTxMaxBlocks - 1
+	}
+	// 落后于本批次时补查之前区块的pool日志
+	if from < beginBlock {
+		catchUpTo := beginBlock - 1
+		if catchUpTo > to {
+			catchUpTo = to
+		}
+		earlier, err := s.chainClient.FilterLogs(s.ctx, chainTypes.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(catchUpTo),
+			Addresses: []string{s.cfg.ContractCfg.AavePoolAddress},
+		})
+		if err != nil {
+			xzap.WithContext(s.ctx).Error("failed on filter pool logs for transactions", zap.Uint64("begin_block", from),
+				zap.Uint64("end_block", catchUpTo), zap.Error(err))
+			return
+		}
+		logs = append(earlier, logs...)
+	}
+	if err := s.savePoolTransactions(from, to, logs); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on record pool transactions, retry in next batch", zap.Uint64("begin_block", from),
+			zap.Uint64("end_block", to), zap.Error(err))
+		return
+	}
+	if err := s.kv.SetInt64(getPoolTxCursorKey(s.chainId), int64(to)); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on save pool transaction cursor", zap.Uint64("block_number", to), zap.Error(err))
+	}
+}
+
+// savePoolTransactions 保存 [beginBlock, endBlock] 区间内与pool合约交互的交易
+// 包括发出pool合约日志的交易（可能经由其他合约调用）和直接调用pool合约的交易，失败交易没有日志，
+// 开启 scan_blocks 时按区块收据的接收地址找出直接调用pool合约的区块，否则只读取有pool日志的区块
+func (s *Service) savePoolTransactions(beginBlock, endBlock uint64, logs []interface{}) error {
+	pool := common.HexToAddress(s.cfg.ContractCfg.AavePoolAddress)
+	logCounts := make(map[common.Hash]int)
+	logBlocks := make(map[uint64]bool)
+	for _, log := range logs {
+		ethLog := log.(ethereumTypes.Log)
+		if ethLog.Address != pool || ethLog.BlockNumber < beginBlock || ethLog.BlockNumber > endBlock {
+			continue
+		}
+		logCounts[ethLog.TxHash]++
+		logBlocks[ethLog.BlockNumber] = true
+	}
+	if s.cfg.TxCfg.ScanBlocks {
+		// 收据只用于筛选区块，只读取有交易直接调用pool合约的区块的交易
+		all := make([]uint64, 0, endBlock-beginBlock+1)
+		for blockNumber := beginBlock; blockNumber <= endBlock; blockNumber++ {
+			all = append(all, blockNumber)
+		}
+		called, err := s.chainClient.BlocksWithTxTo(s.ctx, all, pool)
+		if err != nil {
+			return err
+		}
+		for _, blockNumber := range called {
+			logBlocks[blockNumber] = true
+		}
+	}
+	var blockNumbers []uint64
+	for blockNumber := beginBlock; blockNumber <= endBlock; blockNumber++ {
+		if logBlocks[blockNumber] {
+			blockNumbers = append(blockNumbers, blockNumber)
+		}
+	}
+	if len(blockNumbers) == 0 {
+		return nil
+	}
+	blocks, err := s.chainClient.BlockTxsByNumbers(s.ctx, blockNumbers)
+	if err != nil {
+		return err
+	}
+
+	now := int(time.Now().Unix())
+	var records []*types.PoolTransaction
+	var txs []*chainTypes.BlockTx
+	var hashes []common.Hash
+	for _, blockNumber := range blockNumbers {
+		block := blocks[blockNumber]
+		s.cacheBlockTime(blockNumber, block.Time)
+		for _, tx := range block.Transactions {
+			toPool := tx.To != nil && *tx.To == pool
+			if !toPool && logCounts[tx.Hash] == 0 {
+				continue
+			}
+			record := &types.PoolTransaction{
+				TxHash:      tx.Hash.Hex(),
+				TxIndex:     tx.Index,
+				FromAddress: strings.ToLower(tx.From.Hex()),
+				LogCount:    logCounts[tx.Hash],
+				GasLimit:    int64(tx.Gas),
+				BlockNumber: int64(blockNumber),
+				BlockTime:   int64(block.Time),
+				CreateTime:  now,
+				UpdateTime:  now,
+				Creator:     "system",
+				Updater:     "system",
+			}
+			if tx.To != nil {
+				record.ToAddress = strings.ToLower(tx.To.Hex())
+			}
+			if len(tx.Input) >= 4 {
+				record.MethodSelector = hexutil.Encode(tx.Input[:4])
+				if toPool {
+					parsedAbi := s.poolAbi(blockNumber)
+					if method, err := parsedAbi.MethodById(tx.Input[:4]); err == nil {
+						record.Method = method.RawName
+					}
+				}
+			}
+			records = append(records, record)
+			txs = append(txs, tx)
+			hashes = append(hashes, tx.Hash)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	// 只读取与pool合约交互的交易的收据
+	receipts, err := s.chainClient.TransactionReceipts(s.ctx, hashes)
+	if err != nil {
+		return err
+	}
+	failedMethods := make(map[string]bool)
+	for i, record := range records {
+		receipt := receipts[i]
+		gasPrice := receipt.EffectiveGasPrice
+		if gasPrice == nil {
+			gasPrice = txs[i].GasPrice
+		}
+		if gasPrice == nil {
+			gasPrice = new(big.Int)
+		}
+		record.Status = int(receipt.Status)
+		record.GasUsed = int64(receipt.GasUsed)
+		record.EffectiveGasPrice = gasPrice.String()
+		record.Fee = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)).String()
+
+		method := record.Method
+		if method == "" {
+			method = "unknown"
+		}
+		status := "success"
+		if record.Status == types.TxStatusFailed {
+			status = "failed"
+			failedMethods[alertMethod(record)] = true
+		}
+		poolTxs.WithLabelValues(method, status).Inc()
+		poolTxGasUsed.WithLabelValues(method).Add(float64(record.GasUsed))
+	}
+	if err := s.db.WithContext(s.ctx).
+		Table(types.GetPoolTransactionTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(records, 100).Error; err != nil {
+		return errors.Wrap(err, "failed on create pool transactions")
+	}
+	for _, method := range s.cfg.TxCfg.AlertMethods {
+		if !failedMethods[method] {
+			continue
+		}
+		if err := s.checkFailedTransactions(method, records[len(records)-1].BlockTime); err != nil {
+			xzap.WithContext(s.ctx).Error("failed on check failed pool transactions", zap.String("method", method), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// alertMethod 失败交易告警使用的方法名，ABI中没有对应方法（或经由其他合约调用）时使用4字节选择器
+func alertMethod(record *types.PoolTransaction) string {
+	if record.Method != "" {
+		return record.Method
+	}
+	return record.MethodSelector
+}
+
+// checkFailedTransactions 统计截止到 blockTime 的告警窗口内某个方法的失败交易数量，达到阈值时告警
+// method 为0x开头的4字节选择器时按选择器统计没有方法名的交易
+func (s *Service) checkFailedTransactions(method string, blockTime int64) error {
+	if s.cfg.TxCfg.AlertFailedCount <= 0 {
+		return nil
+	}
+	query := s.db.WithContext(s.ctx).Table(types.GetPoolTransactionTableName())
+	if strings.HasPrefix(method, "0x") {
+		query = query.Where("method = '' AND method_selector = ?", method)
+	} else {
+		query = query.Where("method = ?", method)
+	}
+	var failed int64
+	if err := query.
+		Where("status = ? AND block_time > ? AND block_time <= ?",
+			types.TxStatusFailed, blockTime-s.cfg.TxCfg.AlertWindow, blockTime).
+		Count(&failed).Error; err != nil {
+		return errors.Wrap(err, "failed on count failed pool transactions")
+	}
+	if failed < s.cfg.TxCfg.AlertFailedCount {
+		return nil
+	}
+	poolTxFailedAlerts.WithLabelValues(method).Inc()
+	xzap.WithContext(s.ctx).Warn("failed pool transactions spiked",
+		zap.String("method", method), zap.Int64("failed", failed),
+		zap.Int64("window_seconds", s.cfg.TxCfg.AlertWindow), zap.Int64("block_time", blockTime))
+	return nil
+}
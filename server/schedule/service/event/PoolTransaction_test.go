@@ -0,0 +1,99 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// txClient 按区块返回交易和收据，记录读取了哪些区块
+type txClient struct {
+	logsClient
+	called   []uint64 // 有交易直接调用pool合约的区块
+	blocks   map[uint64]*chainTypes.BlockTxs
+	statuses map[common.Hash]uint64
+	scanned  []uint64
+	fetched  []uint64
+}
+
+func (c *txClient) BlocksWithTxTo(ctx context.Context, blockNumbers []uint64, to common.Address) ([]uint64, error) {
+	c.scanned = append(c.scanned, blockNumbers...)
+	return c.called, nil
+}
+
+func (c *txClient) BlockTxsByNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]*chainTypes.BlockTxs, error) {
+	c.fetched = append(c.fetched, blockNumbers...)
+	return c.blocks, nil
+}
+
+func (c *txClient) TransactionReceipts(ctx context.Context, hashes []common.Hash) ([]*ethereumTypes.Receipt, error) {
+	receipts := make([]*ethereumTypes.Receipt, len(hashes))
+	for i, hash := range hashes {
+		receipts[i] = &ethereumTypes.Receipt{Status: c.statuses[hash], GasUsed: 21000, EffectiveGasPrice: big.NewInt(1e9)}
+	}
+	return receipts, nil
+}
+
+// scan_blocks 时只读取收据中有交易直接调用pool合约的区块和有pool日志的区块，
+// 无法解析方法名的失败交易按4字节选择器告警
+func TestSavePoolTransactionsScansByReceipt(t *testing.T) {
+	s, parsedAbi, created := newTestPoolService(t)
+	s.cfg.TxCfg.ScanBlocks = true
+	pool := common.HexToAddress(s.cfg.ContractCfg.AavePoolAddress)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+	borrowTx := common.HexToHash("0x01")
+	unknownTx := common.HexToHash("0x02")
+	viaRouterTx := common.HexToHash("0x03")
+	client := &txClient{
+		called: []uint64{103},
+		blocks: map[uint64]*chainTypes.BlockTxs{
+			101: {Number: 101, Time: 1700000101, Transactions: []*chainTypes.BlockTx{
+				{Hash: viaRouterTx, To: &other, Input: []byte{0xaa, 0xbb, 0xcc, 0xdd}},
+			}},
+			103: {Number: 103, Time: 1700000103, Transactions: []*chainTypes.BlockTx{
+				{Hash: borrowTx, Index: 0, To: &pool, Input: parsedAbi.Methods["borrow"].ID},
+				{Hash: unknownTx, Index: 1, To: &pool, Input: []byte{0x12, 0x34, 0x56, 0x78}},
+				{Hash: common.HexToHash("0x04"), Index: 2, To: &other},
+			}},
+		},
+		statuses: map[common.Hash]uint64{borrowTx: 1, viaRouterTx: 1},
+	}
+	s.chainClient = client
+
+	logs := []interface{}{ethereumTypes.Log{Address: pool, TxHash: viaRouterTx, BlockNumber: 101}}
+	if err := s.savePoolTransactions(100, 105, logs); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.scanned) != 6 {
+		t.Fatalf("scanned receipts of %v, want blocks 100-105", client.scanned)
+	}
+	if len(client.fetched) != 2 || client.fetched[0] != 101 || client.fetched[1] != 103 {
+		t.Fatalf("fetched blocks %v, want [101 103]", client.fetched)
+	}
+	if len(*created) != 1 {
+		t.Fatalf("created %d batches, want 1", len(*created))
+	}
+	records := (*created)[0].([]*types.PoolTransaction)
+	if len(records) != 3 {
+		t.Fatalf("recorded %d transactions, want 3", len(records))
+	}
+	byHash := make(map[string]*types.PoolTransaction)
+	for _, record := range records {
+		byHash[record.TxHash] = record
+	}
+	if record := byHash[viaRouterTx.Hex()]; record.LogCount != 1 || record.Method != "" || alertMethod(record) != "0xaabbccdd" {
+		t.Fatalf("router tx = %+v", record)
+	}
+	if record := byHash[borrowTx.Hex()]; record.Method != "borrow" || alertMethod(record) != "borrow" || record.Status != types.TxStatusSuccess {
+		t.Fatalf("borrow tx = %+v", record)
+	}
+	if record := byHash[unknownTx.Hex()]; record.Status != types.TxStatusFailed || alertMethod(record) != "0x12345678" {
+		t.Fatalf("unknown tx = %+v", record)
+	}
+}
@@ -0,0 +1,218 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// implementationSlot ERC-1967 实现合约地址的存储槽，bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+var implementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// implementationRange 实现合约从 block 开始生效
+type implementationRange struct {
+	block          uint64
+	implementation common.Address
+	version        string
+}
+
+// poolVersions pool代理合约各版本的ABI和实现合约历史，按区块选择解析日志和调用合约使用的ABI
+// 同步主循环和对账修复会并发读写，需要加锁
+type poolVersions struct {
+	mu              sync.RWMutex
+	abis            map[string]abi.ABI
+	current         string
+	implementations map[common.Address]string
+	history         []implementationRange
+}
+
+// newPoolVersions current 为当前版本，implementations 为实现合约地址到版本的映射，版本必须存在于 abis 中
+func newPoolVersions(abis map[string]abi.ABI, current string, implementations map[string]string) (*poolVersions, error) {
+	if _, ok := abis[current]; !ok {
+		return nil, errors.Errorf("abi of current pool version %q not loaded", current)
+	}
+	p := &poolVersions{
+		abis:            abis,
+		current:         current,
+		implementations: make(map[common.Address]string, len(implementations)),
+	}
+	for address, version := range implementations {
+		if !common.IsHexAddress(address) {
+			return nil, errors.Errorf("invalid pool implementation address %s", address)
+		}
+		if _, ok := abis[version]; !ok {
+			return nil, errors.Errorf("abi of pool version %q of implementation %s not loaded", version, address)
+		}
+		p.implementations[common.HexToAddress(address)] = version
+	}
+	return p, nil
+}
+
+// historyAbis 除当前版本外的其他版本ABI，按版本排序
+func (p *poolVersions) historyAbis() []abi.ABI {
+	versions := make([]string, 0, len(p.abis))
+	for version := range p.abis {
+		if version != p.current {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	parsedAbis := make([]abi.ABI, 0, len(versions))
+	for _, version := range versions {
+		parsedAbis = append(parsedAbis, p.abis[version])
+	}
+	return parsedAbis
+}
+
+// versionOf 实现合约对应的ABI版本，未配置的实现合约使用当前版本
+func (p *poolVersions) versionOf(implementation common.Address) (string, bool) {
+	if version, ok := p.implementations[implementation]; ok {
+		return version, true
+	}
+	return p.current, false
+}
+
+// add 记录实现合约从 block 开始生效，按区块有序插入，重复记录忽略
+func (p *poolVersions) add(block uint64, implementation common.Address, version string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := sort.Search(len(p.history), func(i int) bool { return p.history[i].block > block })
+	for j := i - 1; j >= 0 && p.history[j].block == block; j-- {
+		if p.history[j].implementation == implementation {
+			return
+		}
+	}
+	p.history = append(p.history, implementationRange{})
+	copy(p.history[i+1:], p.history[i:])
+	p.history[i] = implementationRange{block: block, implementation: implementation, version: version}
+}
+
+// at 区块 block 时生效的实现合约，没有记录时返回false
+func (p *poolVersions) at(block uint64) (implementationRange, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	i := sort.Search(len(p.history), func(i int) bool { return p.history[i].block > block })
+	if i == 0 {
+		return implementationRange{}, false
+	}
+	return p.history[i-1], true
+}
+
+// abiAt 区块 block 时生效的ABI，没有实现合约记录时使用当前版本
+func (p *poolVersions) abiAt(block uint64) abi.ABI {
+	if r, ok := p.at(block); ok {
+		return p.abis[r.version]
+	}
+	return p.abis[p.current]
+}
+
+// poolAbi 解析 blockNumber 区块的pool合约日志和调用使用的ABI
+func (s *Service) poolAbi(blockNumber uint64) abi.ABI {
+	return s.pool.abiAt(blockNumber)
+}
+
+// poolAbiAtBlock 与 poolAbi 相同，blockNumber 为空时表示最新区块
+func (s *Service) poolAbiAtBlock(blockNumber *big.Int) abi.ABI {
+	if blockNumber == nil {
+		return s.poolAbi(math.MaxUint64)
+	}
+	return s.poolAbi(blockNumber.Uint64())
+}
+
+// loadImplementationHistory 从数据库加载pool合约的实现合约历史
+func (s *Service) loadImplementationHistory() error {
+	var records []types.PoolImplementation
+	err := s.db.WithContext(s.ctx).
+		Table(types.GetPoolImplementationTableName()).
+		Where("proxy_address = ?", strings.ToLower(s.cfg.ContractCfg.AavePoolAddress)).
+		Order("block_number asc").
+		Find(&records).Error
+	if err != nil {
+		return errors.Wrap(err, "failed on get pool implementation history")
+	}
+	for _, record := range records {
+		implementation := common.HexToAddress(record.ImplementationAddress)
+		// 按当前配置重新确定版本，配置新增实现合约映射后不需要修改历史记录
+		version, _ := s.pool.versionOf(implementation)
+		s.pool.add(uint64(record.BlockNumber), implementation, version)
+	}
+	return nil
+}
+
+// handleUpgradedEvent 处理ERC-1967 Upgraded(address indexed implementation)事件，记录实现合约并切换之后区块使用的ABI
+func (s *Service) handleUpgradedEvent(log ethereumTypes.Log) error {
+	if len(log.Topics) < 2 {
+		return errors.Errorf("insufficient topics for Upgraded event: %d", len(log.Topics))
+	}
+	implementation := common.BytesToAddress(log.Topics[1].Bytes())
+	return s.recordImplementation(implementation, log.BlockNumber, types.ImplementationSourceEvent, log.TxHash.Hex(), int(log.Index))
+}
+
+// checkImplementation 读取 blockNumber 区块pool代理合约的ERC-1967存储槽，实现合约与记录不一致时补充记录，防止漏掉 Upgraded 事件
+func (s *Service) checkImplementation(blockNumber uint64) {
+	value, err := s.chainClient.StorageAt(s.ctx, common.HexToAddress(s.cfg.ContractCfg.AavePoolAddress), implementationSlot, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		xzap.WithContext(s.ctx).Error("failed on get pool implementation slot", zap.Uint64("block_number", blockNumber), zap.Error(err))
+		return
+	}
+	implementation := common.BytesToAddress(value)
+	if implementation == (common.Address{}) {
+		return
+	}
+	if r, ok := s.pool.at(blockNumber); ok && r.implementation == implementation {
+		return
+	}
+	// 存储槽只能确定在该区块已经生效，实际升级区块可能更早
+	if err := s.recordImplementation(implementation, blockNumber, types.ImplementationSourceSlot, "", 0); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on record pool implementation", zap.Uint64("block_number", blockNumber), zap.Error(err))
+	}
+}
+
+// recordImplementation 保存实现合约记录并加入内存中的实现合约历史
+func (s *Service) recordImplementation(implementation common.Address, blockNumber uint64, source, txHash string, logIndex int) error {
+	version, known := s.pool.versionOf(implementation)
+	if !known {
+		xzap.WithContext(s.ctx).Warn("unknown pool implementation, decode with current abi version",
+			zap.String("implementation", implementation.Hex()), zap.String("version", version))
+	}
+	blockTime, err := s.blockTime(blockNumber)
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	if err := s.db.WithContext(s.ctx).
+		Table(types.GetPoolImplementationTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.PoolImplementation{
+			ProxyAddress:          strings.ToLower(s.cfg.ContractCfg.AavePoolAddress),
+			ImplementationAddress: strings.ToLower(implementation.Hex()),
+			AbiVersion:            version,
+			Source:                source,
+			TxHash:                txHash,
+			LogIndex:              logIndex,
+			BlockNumber:           int64(blockNumber),
+			BlockTime:             int64(blockTime),
+			CreateTime:            now,
+			UpdateTime:            now,
+			Creator:               "system",
+			Updater:               "system",
+		}).Error; err != nil {
+		return errors.Wrap(err, "failed on create pool implementation")
+	}
+	s.pool.add(blockNumber, implementation, version)
+	xzap.WithContext(s.ctx).Info("pool implementation recorded", zap.String("implementation", implementation.Hex()),
+		zap.String("version", version), zap.String("source", source), zap.Uint64("block_number", blockNumber))
+	return nil
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// slotClient 按区块返回pool代理合约ERC-1967存储槽中的实现合约地址
+type slotClient struct {
+	logsClient
+	implementations map[uint64]common.Address
+}
+
+func (c *slotClient) StorageAt(ctx context.Context, address common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return common.BytesToHash(c.implementations[blockNumber.Uint64()].Bytes()).Bytes(), nil
+}
+
+// 升级前后的区块按当时生效的实现合约选择ABI，漏掉的升级由存储槽补记
+func TestPoolAbiResolvedByBlock(t *testing.T) {
+	s, v3Abi, created := newTestPoolService(t, 10, 50, 80)
+	v1Abi, err := abis.Load("../../config/abi/Aave2PoolV1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	implV1 := common.HexToAddress("0x00000000000000000000000000000000000001a1")
+	implV3 := common.HexToAddress("0x00000000000000000000000000000000000001a3")
+	s.pool, err = newPoolVersions(map[string]abi.ABI{"v1": v1Abi, "v3": v3Abi}, "v3", map[string]string{
+		implV1.Hex(): "v1",
+		implV3.Hex(): "v3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	isV1 := func(block uint64) bool {
+		_, ok := s.poolAbi(block).Events["DepositLend"]
+		return ok
+	}
+
+	// 没有实现合约记录时使用当前版本
+	if isV1(5) {
+		t.Fatal("block 5 should use current abi before any implementation recorded")
+	}
+	upgraded := v3Abi.Events["Upgraded"].ID
+	for _, log := range []ethereumTypes.Log{
+		{Topics: []common.Hash{upgraded, common.BytesToHash(implV3.Bytes())}, BlockNumber: 50},
+		// 乱序处理的旧升级事件按区块插入
+		{Topics: []common.Hash{upgraded, common.BytesToHash(implV1.Bytes())}, BlockNumber: 10},
+	} {
+		if err := s.handleUpgradedEvent(log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for block, want := range map[uint64]bool{5: false, 10: true, 49: true, 50: false, 1000: false} {
+		if isV1(block) != want {
+			t.Fatalf("block %d uses v1 abi = %v, want %v", block, !want, want)
+		}
+	}
+	if len(*created) != 2 || (*created)[1].(*types.PoolImplementation).AbiVersion != "v1" {
+		t.Fatalf("created = %v", *created)
+	}
+
+	// 存储槽中的实现合约与记录一致时不补记，不一致时从该区块开始切换
+	s.chainClient = &slotClient{implementations: map[uint64]common.Address{50: implV3, 80: implV1}}
+	s.checkImplementation(50)
+	if len(*created) != 2 {
+		t.Fatalf("created %d implementations, want 2", len(*created))
+	}
+	s.checkImplementation(80)
+	if len(*created) != 3 || (*created)[2].(*types.PoolImplementation).Source != types.ImplementationSourceSlot {
+		t.Fatalf("created = %v", *created)
+	}
+	if isV1(79) || !isV1(80) {
+		t.Fatal("abi should switch to v1 from block 80")
+	}
+}
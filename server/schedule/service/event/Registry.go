@@ -0,0 +1,254 @@
+package event
+
+import (
+	"aave_schedule/logger/xzap"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Handler 事件处理器，按ABI事件名注册，同一个事件可以注册多个处理器
+// 新增的数据投影（持仓、清算、价格等）只需要实现 Handler 并注册，不需要修改同步主循环
+type Handler interface {
+	// Name 处理器名称，唯一，用于日志和监控指标
+	Name() string
+	// Event ABI中的事件名
+	Event() string
+	// Addresses 监听的合约地址，为空时监听默认的pool合约
+	Addresses() []string
+	// Handle 处理一条日志，返回的错误只影响当前处理器
+	Handle(log ethereumTypes.Log) error
+}
+
+// funcHandler 把处理函数包装为 Handler
+type funcHandler struct {
+	name      string
+	event     string
+	addresses []string
+	handle    func(log ethereumTypes.Log) error
+}
+
+// NewHandler 用处理函数创建 Handler，addresses 为空时监听默认的pool合约
+func NewHandler(name, event string, handle func(log ethereumTypes.Log) error, addresses ...string) Handler {
+	return &funcHandler{name: name, event: event, addresses: addresses, handle: handle}
+}
+
+func (h *funcHandler) Name() string                       { return h.name }
+func (h *funcHandler) Event() string                      { return h.event }
+func (h *funcHandler) Addresses() []string                { return h.addresses }
+func (h *funcHandler) Handle(log ethereumTypes.Log) error { return h.handle(log) }
+
+var (
+	// handledEvents 各处理器处理的日志数量，result 为 ok、error 或 panic
+	handledEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "event",
+		Name:      "handled_total",
+		Help:      "Number of logs handled, by handler and result.",
+	}, []string{"handler", "result"})
+	// handleSeconds 各处理器处理单条日志的耗时
+	handleSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aave",
+		Subsystem: "event",
+		Name:      "handle_seconds",
+		Help:      "Time spent handling a single log, by handler.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"handler"})
+)
+
+func init() {
+	prometheus.MustRegister(handledEvents, handleSeconds)
+}
+
+// registeredHandler 注册后的处理器和它监听的合约地址
+type registeredHandler struct {
+	handler   Handler
+	addresses map[common.Address]bool
+}
+
+// Registry 事件处理器注册表，按事件签名（topic0）和合约地址把日志分发给处理器
+// 每个合约使用自己的ABI，处理器按事件名在所监听合约的ABI中查找事件签名，升级过的合约可以有多个版本的ABI
+type Registry struct {
+	ctx            context.Context
+	defaultAddress common.Address
+	abis           map[common.Address][]abi.ABI
+	names          map[string]bool
+	handlers       map[common.Hash][]*registeredHandler
+	addresses      map[common.Address]bool
+}
+
+func NewRegistry(ctx context.Context, defaultAddress string) *Registry {
+	return &Registry{
+		ctx:            ctx,
+		defaultAddress: common.HexToAddress(defaultAddress),
+		abis:           make(map[common.Address][]abi.ABI),
+		names:          make(map[string]bool),
+		handlers:       make(map[common.Hash][]*registeredHandler),
+		addresses:      make(map[common.Address]bool),
+	}
+}
+
+// AddContract 添加合约及其ABI，需要在注册监听该合约的处理器之前调用
+// 合约升级过时传入各版本的ABI，第一个为当前版本，处理器监听各版本中同名事件的所有签名
+func (r *Registry) AddContract(address string, parsedAbi abi.ABI, history ...abi.ABI) error {
+	if !common.IsHexAddress(address) {
+		return errors.Errorf("invalid contract address %s", address)
+	}
+	r.abis[common.HexToAddress(address)] = append([]abi.ABI{parsedAbi}, history...)
+	return nil
+}
+
+// withContracts 只复制已添加的合约和ABI，不包含处理器，用于把处理器重新绑定到其它服务实例
+func (r *Registry) withContracts() *Registry {
+	registry := NewRegistry(r.ctx, r.defaultAddress.Hex())
+	for address, parsedAbis := range r.abis {
+		registry.abis[address] = parsedAbis
+	}
+	return registry
+}
+
+// Abi 合约当前版本的ABI，用于处理器解析日志
+func (r *Registry) Abi(address common.Address) (abi.ABI, bool) {
+	parsedAbis, ok := r.abis[address]
+	if !ok {
+		return abi.ABI{}, false
+	}
+	return parsedAbis[0], true
+}
+
+// Register 注册处理器，监听的合约必须已添加，事件名必须存在于合约的ABI中，处理器名称不能重复
+func (r *Registry) Register(handler Handler) error {
+	if r.names[handler.Name()] {
+		return errors.Errorf("handler %s already registered", handler.Name())
+	}
+	var addresses []common.Address
+	for _, address := range handler.Addresses() {
+		if !common.IsHexAddress(address) {
+			return errors.Errorf("invalid address %s of handler %s", address, handler.Name())
+		}
+		addresses = append(addresses, common.HexToAddress(address))
+	}
+	if len(addresses) == 0 {
+		addresses = append(addresses, r.defaultAddress)
+	}
+	// 不同合约ABI中同名事件的签名可能不同，按签名分组
+	byTopic := make(map[common.Hash]map[common.Address]bool)
+	for _, address := range addresses {
+		parsedAbis, ok := r.abis[address]
+		if !ok {
+			return errors.Errorf("contract %s of handler %s not added", address.Hex(), handler.Name())
+		}
+		found := false
+		for _, parsedAbi := range parsedAbis {
+			event, ok := parsedAbi.Events[handler.Event()]
+			if !ok {
+				continue
+			}
+			found = true
+			if byTopic[event.ID] == nil {
+				byTopic[event.ID] = make(map[common.Address]bool)
+			}
+			byTopic[event.ID][address] = true
+		}
+		if !found {
+			return errors.Errorf("event %s of handler %s not found in abi of %s", handler.Event(), handler.Name(), address.Hex())
+		}
+	}
+	for topic, watched := range byTopic {
+		for address := range watched {
+			r.addresses[address] = true
+		}
+		r.handlers[topic] = append(r.handlers[topic], &registeredHandler{handler: handler, addresses: watched})
+	}
+	r.names[handler.Name()] = true
+	return nil
+}
+
+// Addresses 所有处理器监听的合约地址，用于构造日志过滤条件
+func (r *Registry) Addresses() []string {
+	addresses := make([]string, 0, len(r.addresses))
+	for address := range r.addresses {
+		addresses = append(addresses, address.Hex())
+	}
+	return addresses
+}
+
+// Dispatch 把日志分发给监听该事件和合约地址的所有处理器，返回处理的处理器数量
+// 每个处理器单独计时和统计，某个处理器出错或panic不影响其他处理器，全部执行后返回失败的处理器
+func (r *Registry) Dispatch(log ethereumTypes.Log) (int, error) {
+	if len(log.Topics) == 0 {
+		return 0, nil
+	}
+	handled := 0
+	var failed []string
+	for _, registered := range r.handlers[log.Topics[0]] {
+		if !registered.addresses[log.Address] {
+			continue
+		}
+		handled++
+		if !r.handle(registered.handler, log) {
+			failed = append(failed, registered.handler.Name())
+		}
+	}
+	if len(failed) > 0 {
+		return handled, errors.Errorf("handlers %s failed on log %s#%d", strings.Join(failed, ","), log.TxHash.Hex(), log.Index)
+	}
+	return handled, nil
+}
+
+// handle 执行单个处理器并记录监控指标，处理器返回错误或panic时返回false
+func (r *Registry) handle(handler Handler, log ethereumTypes.Log) (ok bool) {
+	start := time.Now()
+	result := "ok"
+	defer func() {
+		if p := recover(); p != nil {
+			result = "panic"
+			ok = false
+			xzap.WithContext(r.ctx).Error("event handler panic",
+				zap.String("handler", handler.Name()),
+				zap.String("panic", fmt.Sprint(p)),
+				zap.String("tx_hash", log.TxHash.Hex()),
+				zap.Uint64("block_number", log.BlockNumber),
+				zap.Uint("log_index", log.Index))
+		}
+		handledEvents.WithLabelValues(handler.Name(), result).Inc()
+		handleSeconds.WithLabelValues(handler.Name()).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := handler.Handle(log); err != nil {
+		result = "error"
+		xzap.WithContext(r.ctx).Error("event handler failed",
+			zap.String("handler", handler.Name()),
+			zap.Error(err),
+			zap.String("tx_hash", log.TxHash.Hex()),
+			zap.Uint64("block_number", log.BlockNumber),
+			zap.Uint("log_index", log.Index))
+		return false
+	}
+	return true
+}
+
+// Handlers 已注册的处理器名称，按事件名分组
+func (r *Registry) Handlers() map[string][]string {
+	handlers := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, registered := range r.handlers {
+		for _, h := range registered {
+			// 多个版本ABI中签名不同的同名事件会注册在多个签名下
+			if seen[h.handler.Name()] {
+				continue
+			}
+			seen[h.handler.Name()] = true
+			handlers[h.handler.Event()] = append(handlers[h.handler.Event()], h.handler.Name())
+		}
+	}
+	return handlers
+}
@@ -0,0 +1,92 @@
+package event
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	"aave_schedule/logger/xzap"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const registryTestAbi = `[{"type":"event","name":"Ping","anonymous":false,"inputs":[{"name":"value","type":"uint256","indexed":false}]}]`
+
+func TestRegistryDispatchIsolatesHandlers(t *testing.T) {
+	pool := "0x0000000000000000000000000000000000000001"
+	parsedAbi := abis.MustParse(registryTestAbi)
+	registry := NewRegistry(xzap.ToContext(context.Background(), zap.NewNop()), pool)
+	if err := registry.AddContract(pool, parsedAbi); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	handlers := []Handler{
+		NewHandler("ping_failed", "Ping", func(log ethereumTypes.Log) error {
+			calls = append(calls, "ping_failed")
+			return errors.New("boom")
+		}),
+		NewHandler("ping_panic", "Ping", func(log ethereumTypes.Log) error {
+			calls = append(calls, "ping_panic")
+			panic("boom")
+		}),
+		NewHandler("ping_ok", "Ping", func(log ethereumTypes.Log) error {
+			calls = append(calls, "ping_ok")
+			return nil
+		}),
+	}
+	for _, handler := range handlers {
+		if err := registry.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := ethereumTypes.Log{Address: common.HexToAddress(pool), Topics: []common.Hash{parsedAbi.Events["Ping"].ID}}
+	handled, err := registry.Dispatch(log)
+	if handled != 3 || len(calls) != 3 {
+		t.Fatalf("handled = %d, calls = %v, want 3 handlers called", handled, calls)
+	}
+	if err == nil || !strings.Contains(err.Error(), "ping_failed,ping_panic") || strings.Contains(err.Error(), "ping_ok") {
+		t.Fatalf("err = %v, want ping_failed and ping_panic reported", err)
+	}
+
+	// 其它合约地址的同名事件不分发
+	log.Address = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	if handled, err := registry.Dispatch(log); handled != 0 || err != nil {
+		t.Fatalf("handled = %d, err = %v, want no handler", handled, err)
+	}
+}
+
+// 重放使用的注册表复制合约但不复制处理器，处理器可以用相同名称重新注册
+func TestRegistryWithContracts(t *testing.T) {
+	pool := "0x0000000000000000000000000000000000000001"
+	parsedAbi := abis.MustParse(registryTestAbi)
+	registry := NewRegistry(xzap.ToContext(context.Background(), zap.NewNop()), pool)
+	if err := registry.AddContract(pool, parsedAbi); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	if err := registry.Register(NewHandler("ping", "Ping", func(log ethereumTypes.Log) error {
+		calls = append(calls, "origin")
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	replay := registry.withContracts()
+	if err := replay.Register(NewHandler("ping", "Ping", func(log ethereumTypes.Log) error {
+		calls = append(calls, "replay")
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	log := ethereumTypes.Log{Address: common.HexToAddress(pool), Topics: []common.Hash{parsedAbi.Events["Ping"].ID}}
+	if handled, err := replay.Dispatch(log); err != nil || handled != 1 {
+		t.Fatalf("handled = %d, err = %v", handled, err)
+	}
+	if strings.Join(calls, ",") != "replay" {
+		t.Fatalf("calls = %v", calls)
+	}
+}
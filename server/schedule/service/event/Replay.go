@@ -0,0 +1,79 @@
+package event
+
+import (
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultReplayBatchBlocks 重新同步时每次查询日志的区块数
+const defaultReplayBatchBlocks = 5
+
+// ReplayActivities 重新同步 [fromBlock, toBlock] 区间内的事件和指数快照，用于修复漏同步或处理错误的事件
+// 对账推算使用的用户操作记录按批次在一个事务中先删除再重建，错误的记录也能被修正；
+// 其它处理器写入的记录按(tx_hash, log_index)去重，只补齐缺失的记录
+func (s *Service) ReplayActivities(fromBlock, toBlock, batchBlocks uint64) error {
+	if batchBlocks == 0 {
+		batchBlocks = defaultReplayBatchBlocks
+	}
+	for begin := fromBlock; begin <= toBlock; begin += batchBlocks {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		default:
+		}
+		end := begin + batchBlocks - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		filterLogs, err := s.chainClient.FilterLogs(s.ctx, chainTypes.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(begin),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: s.registry.Addresses(),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed on filter logs from %d to %d", begin, end)
+		}
+		// 删除和重建在同一个事务中，失败时回滚，对账不会读到批次内缺失的记录
+		if err := s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+			replay, err := s.withDB(tx)
+			if err != nil {
+				return err
+			}
+			if err := tx.Table(types.GetUserActivityTableName()).
+				Where("block_number BETWEEN ? AND ?", begin, end).
+				Delete(&types.UserActivity{}).Error; err != nil {
+				return errors.Wrap(err, "failed on delete user activities")
+			}
+			for _, log := range filterLogs {
+				if _, err := replay.registry.Dispatch(log.(ethereumTypes.Log)); err != nil {
+					return err
+				}
+			}
+			replay.recordInterestIndexes(filterLogs)
+			return nil
+		}); err != nil {
+			return errors.Wrapf(err, "failed on replay logs from %d to %d", begin, end)
+		}
+		xzap.WithContext(s.ctx).Info("replay activities",
+			zap.Uint64("from_block", begin), zap.Uint64("to_block", end), zap.Int("logs", len(filterLogs)))
+	}
+	return nil
+}
+
+// withDB 返回读写使用db的服务副本，处理器重新绑定到副本上，用于在事务中重放日志
+func (s *Service) withDB(db *gorm.DB) (*Service, error) {
+	replay := *s
+	replay.db = db
+	replay.registry = s.registry.withContracts()
+	if err := replay.registerHandlers(); err != nil {
+		return nil, errors.Wrap(err, "failed on register replay handlers")
+	}
+	return &replay, nil
+}
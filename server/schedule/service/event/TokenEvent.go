@@ -0,0 +1,96 @@
+package event
+
+import (
+	"aave_schedule/types"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+// unpackTokenEvent 解析 (address indexed, address indexed, uint256) 结构的ERC-20事件
+func (s *Service) unpackTokenEvent(log ethereumTypes.Log, eventName string) (common.Address, common.Address, *big.Int, error) {
+	if len(log.Topics) != 3 {
+		return common.Address{}, common.Address{}, nil, errors.Errorf("unexpected topics count %d of %s event", len(log.Topics), eventName)
+	}
+	parsedAbi, ok := s.registry.Abi(log.Address)
+	if !ok {
+		return common.Address{}, common.Address{}, nil, errors.Errorf("abi of %s not found", log.Address.Hex())
+	}
+	values, err := parsedAbi.Unpack(eventName, log.Data)
+	if err != nil {
+		return common.Address{}, common.Address{}, nil, errors.Wrapf(err, "failed on unpack %s event", eventName)
+	}
+	if len(values) != 1 {
+		return common.Address{}, common.Address{}, nil, errors.Errorf("unexpected %s event data", eventName)
+	}
+	value, ok := values[0].(*big.Int)
+	if !ok {
+		return common.Address{}, common.Address{}, nil, errors.Errorf("unexpected value type of %s event", eventName)
+	}
+	return common.BytesToAddress(log.Topics[1].Bytes()), common.BytesToAddress(log.Topics[2].Bytes()), value, nil
+}
+
+// handleTransferEvent 保存ERC-20 Transfer 记录
+func (s *Service) handleTransferEvent(log ethereumTypes.Log) error {
+	from, to, value, err := s.unpackTokenEvent(log, "Transfer")
+	if err != nil {
+		return err
+	}
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	return s.db.WithContext(s.ctx).
+		Table(types.GetTokenTransferTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.TokenTransfer{
+			TokenAddress: strings.ToLower(log.Address.Hex()),
+			FromAddress:  strings.ToLower(from.Hex()),
+			ToAddress:    strings.ToLower(to.Hex()),
+			Amount:       value.String(),
+			TxHash:       log.TxHash.Hex(),
+			LogIndex:     int(log.Index),
+			BlockNumber:  int64(log.BlockNumber),
+			BlockTime:    int64(blockTime),
+			CreateTime:   now,
+			UpdateTime:   now,
+			Creator:      "system",
+			Updater:      "system",
+		}).Error
+}
+
+// handleApprovalEvent 保存ERC-20 Approval 记录
+func (s *Service) handleApprovalEvent(log ethereumTypes.Log) error {
+	owner, spender, value, err := s.unpackTokenEvent(log, "Approval")
+	if err != nil {
+		return err
+	}
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	return s.db.WithContext(s.ctx).
+		Table(types.GetTokenApprovalTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.TokenApproval{
+			TokenAddress:   strings.ToLower(log.Address.Hex()),
+			OwnerAddress:   strings.ToLower(owner.Hex()),
+			SpenderAddress: strings.ToLower(spender.Hex()),
+			Amount:         value.String(),
+			TxHash:         log.TxHash.Hex(),
+			LogIndex:       int(log.Index),
+			BlockNumber:    int64(log.BlockNumber),
+			BlockTime:      int64(blockTime),
+			CreateTime:     now,
+			UpdateTime:     now,
+			Creator:        "system",
+			Updater:        "system",
+		}).Error
+}
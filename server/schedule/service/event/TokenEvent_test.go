@@ -0,0 +1,147 @@
+package event
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+)
+
+// memoryKV 只实现 Get、Set、Setex 的内存键值存储
+type memoryKV struct {
+	kv.Store
+	values map[string]string
+}
+
+func (m *memoryKV) Get(key string) (string, error) {
+	return m.values[key], nil
+}
+
+func (m *memoryKV) Set(key, value string) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryKV) Setex(key, value string, seconds int) error {
+	m.values[key] = value
+	return nil
+}
+
+// ERC-20 Transfer 按代币合约的ABI解析，地址统一小写保存
+func TestHandleTransferEvent(t *testing.T) {
+	s, _, created := newTestPoolService(t, 100)
+	erc20Abi, err := abis.Load("../../config/abi/erc20.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := "0x00000000000000000000000000000000000000Aa"
+	s.registry = NewRegistry(s.ctx, s.cfg.ContractCfg.AavePoolAddress)
+	if err := s.registry.AddContract(token, erc20Abi); err != nil {
+		t.Fatal(err)
+	}
+	from := common.HexToAddress("0x00000000000000000000000000000000000000Bb")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000Cc")
+	data, err := erc20Abi.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := ethereumTypes.Log{
+		Address:     common.HexToAddress(token),
+		Topics:      []common.Hash{erc20Abi.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        data,
+		BlockNumber: 100,
+		Index:       3,
+	}
+	if err := s.handleTransferEvent(log); err != nil {
+		t.Fatal(err)
+	}
+	if len(*created) != 1 {
+		t.Fatalf("created %d transfers, want 1", len(*created))
+	}
+	transfer := (*created)[0].(*types.TokenTransfer)
+	if transfer.TokenAddress != "0x00000000000000000000000000000000000000aa" ||
+		transfer.FromAddress != "0x00000000000000000000000000000000000000bb" ||
+		transfer.ToAddress != "0x00000000000000000000000000000000000000cc" ||
+		transfer.Amount != "12345" || transfer.LogIndex != 3 || transfer.BlockTime != 1700000100 {
+		t.Fatalf("transfer = %+v", transfer)
+	}
+
+	// 缺少 indexed 参数的日志不保存
+	log.Topics = log.Topics[:2]
+	if err := s.handleTransferEvent(log); err == nil {
+		t.Fatal("expected error on missing topics")
+	}
+}
+
+// oracleClient 按代币返回预言机价格，未设置价格的代币返回revert
+type oracleClient struct {
+	logsClient
+	prices map[common.Address]*big.Int
+	blocks []uint64
+}
+
+func (c *oracleClient) BatchCallContract(ctx context.Context, msgs []ethereum.CallMsg, blockNumber *big.Int) ([]chainTypes.CallResult, error) {
+	c.blocks = append(c.blocks, blockNumber.Uint64())
+	oracleAbi := abis.MustParse(`[{"inputs":[{"name":"token","type":"address"}],"name":"getTokenPrice","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`)
+	method := oracleAbi.Methods["getTokenPrice"]
+	results := make([]chainTypes.CallResult, len(msgs))
+	for i, msg := range msgs {
+		args, err := method.Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		price, ok := c.prices[args[0].(common.Address)]
+		if !ok {
+			results[i].Err = errors.New("execution reverted: price not set")
+			continue
+		}
+		results[i].Data, _ = method.Outputs.Pack(price)
+	}
+	return results, nil
+}
+
+// 每个批次读取一次价格，未设置价格的代币跳过，价格不变时不重复保存
+func TestRecordOraclePrices(t *testing.T) {
+	s, _, created := newTestPoolService(t, 100, 200, 300)
+	oracleAbi, err := abis.Load("../../config/abi/chainlink.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weth := common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	usdc := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	client := &oracleClient{prices: map[common.Address]*big.Int{weth: big.NewInt(2000e6)}}
+	s.chainClient = client
+	s.tokenContracts = []string{weth.Hex(), usdc.Hex()}
+	s.oracleContracts = []*oracleContract{{name: "Chainlink", address: common.HexToAddress("0x00000000000000000000000000000000000000f1"), parsedAbi: oracleAbi}}
+
+	s.recordOraclePrices(100)
+	s.recordOraclePrices(200)
+	client.prices[weth] = big.NewInt(2100e6)
+	s.recordOraclePrices(300)
+
+	if len(client.blocks) != 3 || client.blocks[0] != 100 || client.blocks[2] != 300 {
+		t.Fatalf("read prices at blocks %v", client.blocks)
+	}
+	if len(*created) != 2 {
+		t.Fatalf("created %d prices, want 2", len(*created))
+	}
+	for i, want := range []struct {
+		block int64
+		price string
+	}{{100, "2000000000"}, {300, "2100000000"}} {
+		price := (*created)[i].(*types.TokenPrice)
+		if price.BlockNumber != want.block || price.Price != want.price ||
+			price.TokenAddress != "0x00000000000000000000000000000000000000e1" {
+			t.Fatalf("price %d = %+v", i, price)
+		}
+	}
+}
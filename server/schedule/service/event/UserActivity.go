@@ -0,0 +1,32 @@
+package event
+
+import (
+	"aave_schedule/types"
+	"time"
+
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm/clause"
+)
+
+// saveUserActivity 补全日志所在的交易、区块信息后保存用户操作记录
+// 以(tx_hash, log_index, user_address)去重，重复同步同一区块时不会产生重复记录
+func (s *Service) saveUserActivity(log ethereumTypes.Log, activity *types.UserActivity) error {
+	blockTime, err := s.blockTime(log.BlockNumber)
+	if err != nil {
+		return err
+	}
+
+	now := int(time.Now().Unix())
+	activity.TxHash = log.TxHash.Hex()
+	activity.LogIndex = int(log.Index)
+	activity.BlockNumber = int64(log.BlockNumber)
+	activity.BlockTime = int64(blockTime)
+	activity.CreateTime = now
+	activity.UpdateTime = now
+	activity.Creator = "system"
+	activity.Updater = "system"
+	return s.db.WithContext(s.ctx).
+		Table(types.GetUserActivityTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(activity).Error
+}
@@ -0,0 +1,47 @@
+package paramwatch
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// paramValue 风险参数的最新值，按合约精度
+	paramValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "param",
+		Name:      "value",
+		Help:      "Latest value of a pool risk parameter, by param and scope.",
+	}, []string{"param", "scope"})
+	// paramChanges 风险参数变化次数
+	paramChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "param",
+		Name:      "changes_total",
+		Help:      "Number of pool risk parameter changes, by param.",
+	}, []string{"param"})
+	// paramAlerts 相对变化超过告警阈值的参数变化次数
+	paramAlerts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aave",
+		Subsystem: "param",
+		Name:      "alerts_total",
+		Help:      "Number of pool risk parameter changes exceeding the alert ratio, by param.",
+	}, []string{"param"})
+	// lastCheckBlock 最近一次读取参数的区块
+	lastCheckBlock = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "param",
+		Name:      "last_check_block",
+		Help:      "Block number of the last pool risk parameter check.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(paramValue, paramChanges, paramAlerts, lastCheckBlock)
+}
+
+func bigToFloat(value *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
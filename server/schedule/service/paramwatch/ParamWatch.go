@@ -0,0 +1,203 @@
+package paramwatch
+
+import (
+	"aave_schedule/chain/chainclient"
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/service/poolstate"
+	"aave_schedule/types"
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/threading"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pollInterval 检查区块高度的间隔
+const pollInterval = 5 * time.Second
+
+// changeRatioPrecision 相对变化比例保留的小数位数
+const changeRatioPrecision = 6
+
+// Service 风险参数监控，owner修改参数不发出事件，按区块区间读取参数getter，值变化时记录新版本，变化过大时告警
+type Service struct {
+	ctx         context.Context
+	cfg         *config.Config
+	db          *gorm.DB
+	chainClient chainclient.ChainClient
+	reader      *poolstate.Reader
+	latest      map[paramKey]*types.PoolParam // 各参数的最新版本，第一次检查时从数据库加载
+	lastBlock   uint64                        // 最近一次读取参数的区块
+}
+
+// paramKey 参数名和作用范围
+type paramKey struct {
+	name  string
+	scope string
+}
+
+func New(ctx context.Context, cfg *config.Config, db *gorm.DB, chainClient chainclient.ChainClient, reader *poolstate.Reader) *Service {
+	return &Service{
+		ctx:         ctx,
+		cfg:         cfg,
+		db:          db,
+		chainClient: chainClient,
+		reader:      reader,
+	}
+}
+
+// Start 每隔配置的区块数读取一次参数，区块数为0时不开启
+func (s *Service) Start() {
+	blockRange := s.cfg.ParamCfg.BlockRange
+	if blockRange == 0 {
+		return
+	}
+	threading.GoSafe(func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				blockNumber, err := s.chainClient.BlockNumber()
+				if err != nil {
+					xzap.WithContext(s.ctx).Error("failed on get current block number", zap.Error(err))
+					continue
+				}
+				if s.lastBlock > 0 && blockNumber < s.lastBlock+blockRange {
+					continue
+				}
+				if _, err := s.Check(blockNumber); err != nil {
+					xzap.WithContext(s.ctx).Error("check pool params failed", zap.Uint64("block_number", blockNumber), zap.Error(err))
+				}
+			}
+		}
+	})
+}
+
+// Check 读取blockNumber区块的参数，和最新版本比较，值变化的参数记录新版本并返回
+func (s *Service) Check(blockNumber uint64) ([]*types.PoolParam, error) {
+	if s.latest == nil {
+		if err := s.loadLatest(); err != nil {
+			return nil, err
+		}
+	}
+	params, err := s.reader.Params(s.ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read pool params")
+	}
+	values := make(map[paramKey]*big.Int, len(params.Global)+2*len(params.Collaterals))
+	for name, value := range params.Global {
+		values[paramKey{name: name}] = value
+	}
+	for _, collateral := range params.Collaterals {
+		scope := strings.ToLower(collateral.Token.Hex())
+		values[paramKey{name: "liquidationThreshold", scope: scope}] = collateral.LiquidationThreshold
+		values[paramKey{name: "collateralizationRatio", scope: scope}] = collateral.CollateralizationRatio
+	}
+
+	var blockTime uint64
+	var changes []*types.PoolParam
+	for key, value := range values {
+		paramValue.WithLabelValues(key.name, key.scope).Set(bigToFloat(value))
+		previous := s.latest[key]
+		if previous != nil && previous.Value == value.String() {
+			continue
+		}
+		if blockTime == 0 {
+			if blockTime, err = s.chainClient.BlockTimeByNumber(s.ctx, new(big.Int).SetUint64(blockNumber)); err != nil {
+				return nil, err
+			}
+		}
+		change := s.newVersion(key, value, previous, blockNumber, blockTime)
+		if err := s.db.WithContext(s.ctx).
+			Table(types.GetPoolParamTableName()).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(change).Error; err != nil {
+			return nil, errors.Wrap(err, "failed on create pool param")
+		}
+		s.latest[key] = change
+		changes = append(changes, change)
+		if previous == nil {
+			continue
+		}
+		paramChanges.WithLabelValues(key.name).Inc()
+		if change.Alerted {
+			paramAlerts.WithLabelValues(key.name).Inc()
+			xzap.WithContext(s.ctx).Warn("pool param changed sharply",
+				zap.String("param", key.name), zap.String("scope", key.scope),
+				zap.String("previous_value", previous.Value), zap.String("value", change.Value),
+				zap.String("change_ratio", change.ChangeRatio), zap.Uint64("block_number", blockNumber))
+		} else {
+			xzap.WithContext(s.ctx).Info("pool param changed",
+				zap.String("param", key.name), zap.String("scope", key.scope),
+				zap.String("previous_value", previous.Value), zap.String("value", change.Value),
+				zap.Uint64("block_number", blockNumber))
+		}
+	}
+	s.lastBlock = blockNumber
+	lastCheckBlock.Set(float64(blockNumber))
+	return changes, nil
+}
+
+// newVersion 生成参数的新版本，第一次读取到的参数为版本1，不告警
+// 原值为0时相对变化比例无意义，只要新值不为0就告警
+func (s *Service) newVersion(key paramKey, value *big.Int, previous *types.PoolParam, blockNumber, blockTime uint64) *types.PoolParam {
+	now := int(time.Now().Unix())
+	param := &types.PoolParam{
+		ParamName:   key.name,
+		Scope:       key.scope,
+		Value:       value.String(),
+		Version:     1,
+		ChangeRatio: "0",
+		BlockNumber: int64(blockNumber),
+		BlockTime:   int64(blockTime),
+		CreateTime:  now,
+		UpdateTime:  now,
+		Creator:     "system",
+		Updater:     "system",
+	}
+	if previous == nil {
+		return param
+	}
+	param.PreviousValue = previous.Value
+	param.Version = previous.Version + 1
+	previousValue, ok := new(big.Int).SetString(previous.Value, 10)
+	if !ok || previousValue.Sign() == 0 {
+		param.Alerted = value.Sign() != 0
+		return param
+	}
+	ratio := new(big.Rat).SetFrac(new(big.Int).Sub(value, previousValue), previousValue)
+	param.ChangeRatio = ratio.FloatString(changeRatioPrecision)
+	alertRatio := new(big.Rat).SetFloat64(s.cfg.ParamCfg.AlertRatio)
+	if alertRatio != nil && alertRatio.Sign() > 0 && new(big.Rat).Abs(ratio).Cmp(alertRatio) >= 0 {
+		param.Alerted = true
+	}
+	return param
+}
+
+// loadLatest 从数据库加载各参数的最新版本
+func (s *Service) loadLatest() error {
+	var records []*types.PoolParam
+	latestVersions := s.db.WithContext(s.ctx).
+		Table(types.GetPoolParamTableName()).
+		Select("param_name, scope, MAX(version)").
+		Group("param_name, scope")
+	if err := s.db.WithContext(s.ctx).
+		Table(types.GetPoolParamTableName()).
+		Where("(param_name, scope, version) IN (?)", latestVersions).
+		Find(&records).Error; err != nil {
+		return errors.Wrap(err, "failed on get latest pool params")
+	}
+	s.latest = make(map[paramKey]*types.PoolParam, len(records))
+	for _, record := range records {
+		s.latest[paramKey{name: record.ParamName, scope: record.Scope}] = record
+	}
+	return nil
+}
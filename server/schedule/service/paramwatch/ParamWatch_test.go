@@ -0,0 +1,144 @@
+package paramwatch
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"aave_schedule/chain/abis"
+	"aave_schedule/chain/chainclient"
+	chainTypes "aave_schedule/chain/types"
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/service/poolstate"
+	"aave_schedule/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// paramsClient 按 Multicall 中的方法返回参数值，只有一个抵押代币
+type paramsClient struct {
+	chainclient.ChainClient
+	parsedAbi abi.ABI
+	token     common.Address
+	global    map[string]*big.Int
+	threshold *big.Int
+}
+
+func (c *paramsClient) Multicall(ctx context.Context, calls []chainTypes.MulticallCall, blockNumber *big.Int) ([]chainTypes.MulticallResult, error) {
+	results := make([]chainTypes.MulticallResult, len(calls))
+	for i, call := range calls {
+		method, err := c.parsedAbi.MethodById(call.CallData[:4])
+		if err != nil {
+			return nil, err
+		}
+		args, err := method.Inputs.Unpack(call.CallData[4:])
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		switch method.Name {
+		case "supportedCollateralAddresses":
+			// 只有下标0存在，其它下标越界revert
+			if args[0].(*big.Int).Sign() != 0 {
+				continue
+			}
+			out = []interface{}{c.token}
+		case "collaterals":
+			out = []interface{}{c.token, big.NewInt(0), big.NewInt(0), c.threshold, big.NewInt(150)}
+		default:
+			out = []interface{}{c.global[method.Name]}
+		}
+		data, err := method.Outputs.Pack(out...)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = chainTypes.MulticallResult{Success: true, ReturnData: data}
+	}
+	return results, nil
+}
+
+func (c *paramsClient) BlockTimeByNumber(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	return 1700000000 + blockNumber.Uint64(), nil
+}
+
+// 参数值不变时不产生新版本，变化时版本加一，相对变化超过告警比例时告警
+func TestCheckVersionsAndAlerts(t *testing.T) {
+	ctx := xzap.ToContext(context.Background(), zap.NewNop())
+	parsedAbi, err := abis.Load("../../config/abi/Aave2PoolV3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := "0x0000000000000000000000000000000000000001"
+	client := &paramsClient{
+		parsedAbi: parsedAbi,
+		token:     common.HexToAddress("0x00000000000000000000000000000000000000Aa"),
+		global: map[string]*big.Int{
+			"reserveFactor":                     big.NewInt(100000),
+			"safeHealthFactor":                  big.NewInt(1500000),
+			"closeFactor":                       big.NewInt(500000),
+			"liquidationPenaltyFeeRate4Cleaner": big.NewInt(50000),
+		},
+		threshold: big.NewInt(800000),
+	}
+	reader, err := poolstate.NewReader(client, parsedAbi, pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:3306)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(ctx, &config.Config{ParamCfg: config.ParamCfg{AlertRatio: 0.2}}, db, client, reader)
+	s.latest = make(map[paramKey]*types.PoolParam)
+
+	// 第一次读取的参数都是版本1，不告警
+	changes, err := s.Check(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 6 {
+		t.Fatalf("first check got %d versions, want 6", len(changes))
+	}
+	for _, change := range changes {
+		if change.Version != 1 || change.Alerted {
+			t.Fatalf("first version = %+v", change)
+		}
+	}
+
+	// 值不变时不产生新版本
+	if changes, err = s.Check(200); err != nil || len(changes) != 0 {
+		t.Fatalf("unchanged check got %d versions, err = %v", len(changes), err)
+	}
+
+	// reserveFactor 变化10%不告警，清算阈值变化25%告警
+	client.global["reserveFactor"] = big.NewInt(110000)
+	client.threshold = big.NewInt(600000)
+	changes, err = s.Check(300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changed check got %d versions, want 2", len(changes))
+	}
+	byName := make(map[string]*types.PoolParam)
+	for _, change := range changes {
+		byName[change.ParamName] = change
+	}
+	reserve := byName["reserveFactor"]
+	if reserve == nil || reserve.Version != 2 || reserve.PreviousValue != "100000" || reserve.ChangeRatio != "0.100000" || reserve.Alerted {
+		t.Fatalf("reserveFactor version = %+v", reserve)
+	}
+	threshold := byName["liquidationThreshold"]
+	if threshold == nil || threshold.Version != 2 || threshold.Scope != "0x00000000000000000000000000000000000000aa" ||
+		threshold.ChangeRatio != "-0.250000" || !threshold.Alerted || threshold.BlockTime != 1700000300 {
+		t.Fatalf("liquidationThreshold version = %+v", threshold)
+	}
+}
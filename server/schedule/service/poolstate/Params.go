@@ -0,0 +1,92 @@
+package poolstate
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// globalParams 没有事件的全局风险参数getter，只由owner修改，ABI中不存在的参数（旧版本合约）跳过
+var globalParams = []string{
+	"reserveFactor",
+	"safeHealthFactor",
+	"closeFactor",
+	"liquidationPenaltyFeeRate4Cleaner",
+}
+
+// Params 池子合约在某个区块的风险参数
+type Params struct {
+	BlockNumber uint64
+	Global      map[string]*big.Int // 参数名 -> 值
+	Collaterals []*CollateralParams
+}
+
+// CollateralParams 单个抵押代币的风险参数，对应合约 collaterals(token)
+type CollateralParams struct {
+	Token                  common.Address
+	LiquidationThreshold   *big.Int
+	CollateralizationRatio *big.Int
+}
+
+// Params 读取blockNumber区块的全局风险参数和各抵押代币的清算阈值、抵押率，blockNumber为nil时固定到当前最新区块
+func (r *Reader) Params(ctx context.Context, blockNumber *big.Int) (*Params, error) {
+	if blockNumber == nil {
+		latest, err := r.client.BlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = new(big.Int).SetUint64(latest)
+	}
+	params := &Params{BlockNumber: blockNumber.Uint64(), Global: make(map[string]*big.Int)}
+
+	tokenLists, err := r.probeAddresses(ctx, blockNumber, 1, func(_ int, i int64) *call {
+		return &call{method: "supportedCollateralAddresses", args: []interface{}{big.NewInt(i)}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	tokens := tokenLists[0]
+
+	// 全局参数和抵押代币配置合并在一次请求中
+	var calls []*call
+	for _, method := range globalParams {
+		if _, ok := r.parsedAbi.Methods[method]; ok {
+			calls = append(calls, &call{method: method})
+		}
+	}
+	globals := len(calls)
+	for _, token := range tokens {
+		calls = append(calls, &call{method: "collaterals", args: []interface{}{token}})
+	}
+	if err := r.multicall(ctx, blockNumber, calls); err != nil {
+		return nil, err
+	}
+	for _, c := range calls[:globals] {
+		if !c.ok {
+			return nil, errors.Errorf("failed on call %s", c.method)
+		}
+		value, err := firstBigInt(c)
+		if err != nil {
+			return nil, err
+		}
+		params.Global[c.method] = value
+	}
+	for i, token := range tokens {
+		c := calls[globals+i]
+		if !c.ok {
+			return nil, errors.Errorf("failed on call collaterals(%s)", token.Hex())
+		}
+		fields, err := r.collateralOutputs(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed on decode collaterals(%s)", token.Hex())
+		}
+		params.Collaterals = append(params.Collaterals, &CollateralParams{
+			Token:                  token,
+			LiquidationThreshold:   fields["liquidationThreshold"],
+			CollateralizationRatio: fields["collateralizationRatio"],
+		})
+	}
+	return params, nil
+}
@@ -0,0 +1,354 @@
+package poolstate
+
+import (
+	"aave_schedule/chain/abis"
+	"aave_schedule/chain/chainclient"
+	logTypes "aave_schedule/chain/types"
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// probePageSize 合约的public数组没有长度getter，每轮探测的下标数量，调用失败（越界）即表示到达数组末尾
+const probePageSize = 100
+
+// Snapshot 池子合约在某个区块的完整状态
+type Snapshot struct {
+	BlockNumber          uint64
+	TotalPrincipalLend   *big.Int
+	TotalPrincipalBorrow *big.Int
+	Collaterals          []*CollateralState
+}
+
+// CollateralState 单个抵押代币的状态，对应合约 collaterals(token)
+type CollateralState struct {
+	Token                  common.Address
+	TotalCollateral        *big.Int
+	TotalBorrowPrincipal   *big.Int
+	LiquidationThreshold   *big.Int
+	CollateralizationRatio *big.Int
+	Borrowers              []*BorrowerState // tokenBorrower(token) 中的借款人
+}
+
+// BorrowerState 借款人在某个抵押代币下的仓位
+type BorrowerState struct {
+	User          common.Address
+	DepositAmount *big.Int // userDepositTokenAmount(token, user)
+	BorrowTotal   *big.Int // calculateUserBorrowTotal(user, token)，含利息
+}
+
+// Reader 通过 Multicall 读取池子合约状态，整个快照固定在同一区块
+type Reader struct {
+	client          chainclient.ChainClient
+	parsedAbi       abi.ABI
+	pool            common.Address
+	collateralIndex map[string]int // collaterals(token) 返回值名称 -> 下标
+}
+
+// requiredMethods 读取快照用到的pool合约方法
+var requiredMethods = []string{
+	"totalPrincipalLend",
+	"totalPrincipalBorrow",
+	"supportedCollateralAddresses",
+	"collaterals",
+	"tokenBorrower",
+	"userDepositTokenAmount",
+	"calculateUserBorrowTotal",
+}
+
+// collateralFields 读取快照和风险参数用到的 collaterals(token) 返回值，按名称定位，
+// 不同版本合约的返回值布局不同，ABI与合约不一致时在启动时报错而不是每次读取失败
+var collateralFields = []string{
+	"totalCollateral",
+	"totalBorrowPrincipal",
+	"liquidationThreshold",
+	"collateralizationRatio",
+}
+
+// NewReader 创建快照读取器，ABI缺少需要的方法或 collaterals 的返回值时返回错误
+func NewReader(client chainclient.ChainClient, parsedAbi abi.ABI, poolAddress string) (*Reader, error) {
+	if err := abis.Require(parsedAbi, nil, requiredMethods); err != nil {
+		return nil, errors.Wrap(err, "invalid pool abi for snapshot")
+	}
+	collateralIndex, err := outputIndex(parsedAbi.Methods["collaterals"], collateralFields)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pool abi for snapshot")
+	}
+	return &Reader{
+		client:          client,
+		parsedAbi:       parsedAbi,
+		pool:            common.HexToAddress(poolAddress),
+		collateralIndex: collateralIndex,
+	}, nil
+}
+
+// outputIndex 按名称定位方法的返回值下标，一次返回全部缺失的返回值
+func outputIndex(method abi.Method, names []string) (map[string]int, error) {
+	index := make(map[string]int, len(names))
+	for i, output := range method.Outputs {
+		index[output.Name] = i
+	}
+	var missing []string
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("method %s missing outputs %s", method.Name, strings.Join(missing, ", "))
+	}
+	return index, nil
+}
+
+// call 一个待执行的view调用，out为解码后的返回值
+type call struct {
+	method string
+	args   []interface{}
+	out    []interface{}
+	ok     bool
+}
+
+// Snapshot 读取blockNumber区块的完整池子状态，blockNumber为nil时固定到当前最新区块
+// 请求次数与抵押代币、借款人数量基本无关，数组长度不超过一页时共5次请求
+func (r *Reader) Snapshot(ctx context.Context, blockNumber *big.Int) (*Snapshot, error) {
+	if blockNumber == nil {
+		latest, err := r.client.BlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = new(big.Int).SetUint64(latest)
+	}
+	snapshot := &Snapshot{BlockNumber: blockNumber.Uint64()}
+
+	// 第一轮：全局本金和抵押代币列表
+	totals := []*call{
+		{method: "totalPrincipalLend"},
+		{method: "totalPrincipalBorrow"},
+	}
+	if err := r.multicall(ctx, blockNumber, totals); err != nil {
+		return nil, err
+	}
+	for _, c := range totals {
+		if !c.ok {
+			return nil, errors.Errorf("failed on call %s", c.method)
+		}
+	}
+	var err error
+	if snapshot.TotalPrincipalLend, err = firstBigInt(totals[0]); err != nil {
+		return nil, err
+	}
+	if snapshot.TotalPrincipalBorrow, err = firstBigInt(totals[1]); err != nil {
+		return nil, err
+	}
+
+	tokenLists, err := r.probeAddresses(ctx, blockNumber, 1, func(_ int, i int64) *call {
+		return &call{method: "supportedCollateralAddresses", args: []interface{}{big.NewInt(i)}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	tokens := tokenLists[0]
+
+	// 第二轮：每个抵押代币的配置和借款人列表
+	configs := make([]*call, len(tokens))
+	for i, token := range tokens {
+		configs[i] = &call{method: "collaterals", args: []interface{}{token}}
+	}
+	if err := r.multicall(ctx, blockNumber, configs); err != nil {
+		return nil, err
+	}
+	borrowerLists, err := r.probeAddresses(ctx, blockNumber, len(tokens), func(list int, i int64) *call {
+		return &call{method: "tokenBorrower", args: []interface{}{tokens[list], big.NewInt(i)}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range tokens {
+		if !configs[i].ok {
+			return nil, errors.Errorf("failed on call collaterals(%s)", token.Hex())
+		}
+		fields, err := r.collateralOutputs(configs[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed on decode collaterals(%s)", token.Hex())
+		}
+		collateral := &CollateralState{
+			Token:                  token,
+			TotalCollateral:        fields["totalCollateral"],
+			TotalBorrowPrincipal:   fields["totalBorrowPrincipal"],
+			LiquidationThreshold:   fields["liquidationThreshold"],
+			CollateralizationRatio: fields["collateralizationRatio"],
+		}
+		for _, borrower := range borrowerLists[i] {
+			collateral.Borrowers = append(collateral.Borrowers, &BorrowerState{User: borrower})
+		}
+		snapshot.Collaterals = append(snapshot.Collaterals, collateral)
+	}
+
+	// 第三轮：所有借款人的抵押物数量和借款本息
+	var positions []*call
+	for _, collateral := range snapshot.Collaterals {
+		for _, borrower := range collateral.Borrowers {
+			positions = append(positions,
+				&call{method: "userDepositTokenAmount", args: []interface{}{collateral.Token, borrower.User}},
+				&call{method: "calculateUserBorrowTotal", args: []interface{}{borrower.User, collateral.Token}},
+			)
+		}
+	}
+	if err := r.multicall(ctx, blockNumber, positions); err != nil {
+		return nil, err
+	}
+	i := 0
+	for _, collateral := range snapshot.Collaterals {
+		for _, borrower := range collateral.Borrowers {
+			if !positions[i].ok || !positions[i+1].ok {
+				return nil, errors.Errorf("failed on read position of %s", borrower.User.Hex())
+			}
+			if borrower.DepositAmount, err = firstBigInt(positions[i]); err != nil {
+				return nil, err
+			}
+			if borrower.BorrowTotal, err = firstBigInt(positions[i+1]); err != nil {
+				return nil, err
+			}
+			i += 2
+		}
+	}
+	return snapshot, nil
+}
+
+// probeAddresses 按页探测lists个public地址数组，某个下标调用失败（越界）即表示该数组到达末尾
+// 所有数组的同一页合并在一次请求中，只有上一页全部成功的数组才继续探测下一页
+func (r *Reader) probeAddresses(ctx context.Context, blockNumber *big.Int, lists int, newCall func(list int, i int64) *call) ([][]common.Address, error) {
+	addresses := make([][]common.Address, lists)
+	open := make([]bool, lists)
+	for list := range open {
+		open[list] = true
+	}
+	for start := int64(0); ; start += probePageSize {
+		var calls []*call
+		var owners []int
+		for list := range open {
+			if !open[list] {
+				continue
+			}
+			for i := int64(0); i < probePageSize; i++ {
+				calls = append(calls, newCall(list, start+i))
+				owners = append(owners, list)
+			}
+		}
+		if len(calls) == 0 {
+			return addresses, nil
+		}
+		if err := r.multicall(ctx, blockNumber, calls); err != nil {
+			return nil, err
+		}
+		for i, c := range calls {
+			list := owners[i]
+			if !open[list] {
+				continue
+			}
+			if !c.ok || len(c.out) != 1 {
+				open[list] = false
+				continue
+			}
+			address, ok := c.out[0].(common.Address)
+			if !ok {
+				return nil, errors.Errorf("unexpected %s output type", c.method)
+			}
+			addresses[list] = append(addresses[list], address)
+		}
+	}
+}
+
+// multicall 编码并聚合执行calls，单个调用失败时只把对应的ok置为false
+func (r *Reader) multicall(ctx context.Context, blockNumber *big.Int, calls []*call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	multicalls := make([]logTypes.MulticallCall, len(calls))
+	for i, c := range calls {
+		data, err := r.parsedAbi.Pack(c.method, c.args...)
+		if err != nil {
+			return errors.Wrapf(err, "failed on pack %s", c.method)
+		}
+		multicalls[i] = logTypes.MulticallCall{Target: r.pool, AllowFailure: true, CallData: data}
+	}
+	results, err := r.client.Multicall(ctx, multicalls, blockNumber)
+	if err != nil {
+		return err
+	}
+	for i, result := range results {
+		if !result.Success {
+			continue
+		}
+		out, err := r.parsedAbi.Unpack(calls[i].method, result.ReturnData)
+		if err != nil {
+			continue
+		}
+		calls[i].out = out
+		calls[i].ok = true
+	}
+	return nil
+}
+
+// firstBigInt 取调用的第一个uint256返回值
+func firstBigInt(c *call) (*big.Int, error) {
+	return bigIntOutput(c, 0)
+}
+
+// bigIntOutput 取调用第index个返回值，不存在或不是uint256时返回错误
+func bigIntOutput(c *call, index int) (*big.Int, error) {
+	if index >= len(c.out) {
+		return nil, errors.Errorf("%s returned %d outputs, want more than %d", c.method, len(c.out), index)
+	}
+	value, ok := c.out[index].(*big.Int)
+	if !ok {
+		return nil, errors.Errorf("unexpected %s output %d type %T", c.method, index, c.out[index])
+	}
+	return value, nil
+}
+
+// collateralOutputs 按名称取 collaterals(token) 中 collateralFields 的返回值
+func (r *Reader) collateralOutputs(c *call) (map[string]*big.Int, error) {
+	fields := make(map[string]*big.Int, len(collateralFields))
+	for _, name := range collateralFields {
+		value, err := bigIntOutput(c, r.collateralIndex[name])
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// TokenUser 抵押代币和用户
+type TokenUser struct {
+	Token common.Address
+	User  common.Address
+}
+
+// UserDeposits 批量读取 userDepositTokenAmount(token, user)，结果与pairs一一对应
+func (r *Reader) UserDeposits(ctx context.Context, blockNumber *big.Int, pairs []TokenUser) ([]*big.Int, error) {
+	calls := make([]*call, len(pairs))
+	for i, pair := range pairs {
+		calls[i] = &call{method: "userDepositTokenAmount", args: []interface{}{pair.Token, pair.User}}
+	}
+	if err := r.multicall(ctx, blockNumber, calls); err != nil {
+		return nil, err
+	}
+	deposits := make([]*big.Int, len(pairs))
+	for i, c := range calls {
+		if !c.ok {
+			return nil, errors.Errorf("failed on read deposit of %s", pairs[i].User.Hex())
+		}
+		deposit, err := firstBigInt(c)
+		if err != nil {
+			return nil, err
+		}
+		deposits[i] = deposit
+	}
+	return deposits, nil
+}
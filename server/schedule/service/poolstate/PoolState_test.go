@@ -0,0 +1,63 @@
+package poolstate
+
+import (
+	"aave_schedule/chain/abis"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNewReaderCollateralLayout(t *testing.T) {
+	v3, err := abis.Load("../../config/abi/Aave2PoolV3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewReader(nil, v3, "0x0000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("v3 abi rejected: %v", err)
+	}
+	want := map[string]int{"totalCollateral": 1, "totalBorrowPrincipal": 2, "liquidationThreshold": 3, "collateralizationRatio": 4}
+	for name, index := range want {
+		if reader.collateralIndex[name] != index {
+			t.Errorf("collateralIndex[%s] = %d, want %d", name, reader.collateralIndex[name], index)
+		}
+	}
+
+	// user-031时ABI中collaterals的返回值布局与合约不一致，启动时就应该报错
+	data, err := os.ReadFile("../../config/abi/Aave2PoolV3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := strings.Replace(string(data),
+		`{"internalType":"uint256","name":"totalCollateral","type":"uint256"},{"internalType":"uint256","name":"totalBorrowPrincipal","type":"uint256"}`,
+		`{"internalType":"uint256","name":"utilizationRate","type":"uint256"},{"internalType":"uint256","name":"borrowed","type":"uint256"},{"internalType":"uint256","name":"borrowable","type":"uint256"},{"internalType":"uint256","name":"healthFactor","type":"uint256"}`, 1)
+	if stale == string(data) {
+		t.Fatal("collaterals outputs not found in v3 abi")
+	}
+	parsed, err := abis.Parse([]byte(stale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReader(nil, parsed, "0x0000000000000000000000000000000000000001"); err == nil || !strings.Contains(err.Error(), "totalCollateral") {
+		t.Fatalf("expected missing outputs error, got %v", err)
+	}
+}
+
+func TestBigIntOutput(t *testing.T) {
+	c := &call{method: "collaterals", out: []interface{}{common.Address{}, big.NewInt(7)}}
+	if value, err := bigIntOutput(c, 1); err != nil || value.Int64() != 7 {
+		t.Fatalf("bigIntOutput(1) = %v, %v", value, err)
+	}
+	if _, err := bigIntOutput(c, 0); err == nil {
+		t.Fatal("expected type error for address output")
+	}
+	if _, err := bigIntOutput(c, 2); err == nil {
+		t.Fatal("expected error for missing output")
+	}
+	if _, err := firstBigInt(&call{method: "totalPrincipalLend"}); err == nil {
+		t.Fatal("expected error for empty outputs")
+	}
+}
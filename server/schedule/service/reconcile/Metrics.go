@@ -0,0 +1,47 @@
+package reconcile
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// driftItems 每次对账各对账项的偏差条数
+	driftItems = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "reconcile",
+		Name:      "drift_items",
+		Help:      "Number of drifted items found by the last reconciliation, by scope.",
+	}, []string{"scope"})
+	// driftValue 全局和抵押代币对账项的偏差值（链上值 - 同步值），按链上精度
+	driftValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "reconcile",
+		Name:      "drift_value",
+		Help:      "Chain value minus indexed value of the last reconciliation, by scope and token.",
+	}, []string{"scope", "token"})
+	// lastRunBlock 最近一次对账所在区块
+	lastRunBlock = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "reconcile",
+		Name:      "last_run_block",
+		Help:      "Block number of the last reconciliation.",
+	})
+	// lastRunTimestamp 最近一次对账完成时间
+	lastRunTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "aave",
+		Subsystem: "reconcile",
+		Name:      "last_run_timestamp",
+		Help:      "Unix timestamp of the last reconciliation.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(driftItems, driftValue, lastRunBlock, lastRunTimestamp)
+}
+
+func bigToFloat(value *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
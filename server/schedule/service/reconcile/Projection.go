@@ -0,0 +1,167 @@
+package reconcile
+
+import (
+	"aave_schedule/logger/xzap"
+	"aave_schedule/types"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ray 合约指数精度 1e27
+var ray = new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+
+// tokenUser 抵押代币和用户地址（小写）
+type tokenUser struct {
+	token string
+	user  string
+}
+
+// projection 根据已同步的事件推算出的合约状态
+type projection struct {
+	totalPrincipalLend   *big.Int
+	totalPrincipalBorrow *big.Int
+	collateralTotal      map[string]*big.Int // collaterals(token).totalCollateral
+	collateralBorrow     map[string]*big.Int // collaterals(token).totalBorrowPrincipal
+	userDeposit          map[tokenUser]*big.Int
+}
+
+func newProjection() *projection {
+	return &projection{
+		totalPrincipalLend:   new(big.Int),
+		totalPrincipalBorrow: new(big.Int),
+		collateralTotal:      make(map[string]*big.Int),
+		collateralBorrow:     make(map[string]*big.Int),
+		userDeposit:          make(map[tokenUser]*big.Int),
+	}
+}
+
+// loadProjection 按链上顺序回放 blockNumber 及之前的用户操作记录，推算合约中的本金和抵押物数量
+// 推算规则与合约中对应函数修改状态的方式保持一致，包括合约本身的特殊处理，
+// 这样偏差只会来自漏同步或处理错误的事件
+func (s *Service) loadProjection(blockNumber uint64) (*projection, error) {
+	var activities []*types.UserActivity
+	err := s.db.WithContext(s.ctx).
+		Table(types.GetUserActivityTableName()).
+		Where("block_number <= ?", blockNumber).
+		Order("block_number ASC, log_index ASC").
+		Find(&activities).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query user activities")
+	}
+	borrowIndexes, err := s.loadLiquidationBorrowIndexes(activities)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newProjection()
+	for _, activity := range activities {
+		borrowIndex := borrowIndexes[activity.BlockNumber]
+		if activity.Action == types.ActionLiquidated && (borrowIndex == nil || borrowIndex.Sign() == 0) {
+			xzap.WithContext(s.ctx).Warn("missing borrow index for liquidation",
+				zap.String("tx_hash", activity.TxHash), zap.Int64("block_number", activity.BlockNumber))
+		}
+		if err := p.apply(activity, borrowIndex); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// apply 按合约中对应函数修改状态的方式回放一条用户操作记录
+// 清算需要所在区块的借款指数，缺失时只扣减抵押物，借款本金不变
+func (p *projection) apply(activity *types.UserActivity, borrowIndex *big.Int) error {
+	amount, ok := new(big.Int).SetString(activity.Amount, 10)
+	if !ok {
+		return errors.Errorf("invalid amount of tx %s", activity.TxHash)
+	}
+	key := tokenUser{token: activity.TokenAddress, user: activity.UserAddress}
+	switch activity.Action {
+	case types.ActionLendDeposit:
+		// depositLend: totalPrincipalLend += amount
+		p.totalPrincipalLend.Add(p.totalPrincipalLend, amount)
+	case types.ActionLendWithdraw:
+		// depositLendWithdraw: totalPrincipalLend -= amount - interest
+		interest, _ := new(big.Int).SetString(activity.Interest, 10)
+		if interest == nil {
+			interest = new(big.Int)
+		}
+		p.totalPrincipalLend.Sub(p.totalPrincipalLend, new(big.Int).Sub(amount, interest))
+	case types.ActionCollateralDeposit:
+		add(p.collateralTotal, activity.TokenAddress, amount)
+		addUser(p.userDeposit, key, amount)
+	case types.ActionCollateralWithdraw:
+		add(p.collateralTotal, activity.TokenAddress, new(big.Int).Neg(amount))
+		addUser(p.userDeposit, key, new(big.Int).Neg(amount))
+	case types.ActionBorrow:
+		p.totalPrincipalBorrow.Add(p.totalPrincipalBorrow, amount)
+		add(p.collateralBorrow, activity.TokenAddress, amount)
+	case types.ActionRepay:
+		// borrowRepay: totalCollateral -= 还款金额，userDepositTokenAmount += 返还的抵押物，借款本金不变
+		add(p.collateralTotal, activity.TokenAddress, new(big.Int).Neg(amount))
+		if returned, ok := new(big.Int).SetString(activity.CollateralAmount, 10); ok {
+			addUser(p.userDeposit, key, returned)
+		}
+	case types.ActionLiquidated:
+		// liquidate: 借款本金减少 amount × 1e27 / borrowIndex，totalCollateral -= 扣押的抵押物，
+		// 合约不扣减被清算用户的 userDepositTokenAmount
+		if seized, ok := new(big.Int).SetString(activity.CollateralAmount, 10); ok {
+			add(p.collateralTotal, activity.TokenAddress, new(big.Int).Neg(seized))
+		}
+		if borrowIndex == nil || borrowIndex.Sign() == 0 {
+			return nil
+		}
+		reduction := new(big.Int).Mul(amount, ray)
+		reduction.Div(reduction, borrowIndex)
+		p.totalPrincipalBorrow.Sub(p.totalPrincipalBorrow, reduction)
+		add(p.collateralBorrow, activity.TokenAddress, new(big.Int).Neg(reduction))
+	}
+	return nil
+}
+
+// loadLiquidationBorrowIndexes 读取清算所在区块的借款指数快照
+func (s *Service) loadLiquidationBorrowIndexes(activities []*types.UserActivity) (map[int64]*big.Int, error) {
+	var blocks []int64
+	for _, activity := range activities {
+		if activity.Action == types.ActionLiquidated {
+			blocks = append(blocks, activity.BlockNumber)
+		}
+	}
+	indexes := make(map[int64]*big.Int)
+	if len(blocks) == 0 {
+		return indexes, nil
+	}
+	var snapshots []*types.InterestIndex
+	err := s.db.WithContext(s.ctx).
+		Table(types.GetInterestIndexTableName()).
+		Where("block_number IN ?", blocks).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query interest index")
+	}
+	for _, snapshot := range snapshots {
+		if index, ok := new(big.Int).SetString(snapshot.BorrowIndex, 10); ok {
+			indexes[snapshot.BlockNumber] = index
+		}
+	}
+	return indexes, nil
+}
+
+func add(values map[string]*big.Int, key string, delta *big.Int) {
+	value, ok := values[key]
+	if !ok {
+		value = new(big.Int)
+		values[key] = value
+	}
+	value.Add(value, delta)
+}
+
+func addUser(values map[tokenUser]*big.Int, key tokenUser, delta *big.Int) {
+	value, ok := values[key]
+	if !ok {
+		value = new(big.Int)
+		values[key] = value
+	}
+	value.Add(value, delta)
+}
@@ -0,0 +1,107 @@
+package reconcile
+
+import (
+	"math/big"
+	"testing"
+
+	"aave_schedule/types"
+)
+
+const (
+	testToken = "0x00000000000000000000000000000000000000aa"
+	testUser  = "0x00000000000000000000000000000000000000bb"
+)
+
+func activity(action, amount, collateralAmount string) *types.UserActivity {
+	return &types.UserActivity{
+		Action:           action,
+		TokenAddress:     testToken,
+		UserAddress:      testUser,
+		Amount:           amount,
+		CollateralAmount: collateralAmount,
+	}
+}
+
+func expectInt(t *testing.T, name string, got *big.Int, want int64) {
+	t.Helper()
+	if got == nil {
+		got = new(big.Int)
+	}
+	if got.Cmp(big.NewInt(want)) != 0 {
+		t.Fatalf("%s = %s, want %d", name, got, want)
+	}
+}
+
+// 还款时合约按还款金额扣减 totalCollateral，并把返还的抵押物记回 userDepositTokenAmount，借款本金不变
+func TestProjectionRepayQuirk(t *testing.T) {
+	p := newProjection()
+	key := tokenUser{token: testToken, user: testUser}
+	for _, a := range []*types.UserActivity{
+		activity(types.ActionCollateralDeposit, "1000", "0"),
+		activity(types.ActionBorrow, "300", "0"),
+		activity(types.ActionRepay, "100", "40"),
+	} {
+		if err := p.apply(a, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectInt(t, "totalCollateral", p.collateralTotal[testToken], 900)
+	expectInt(t, "userDepositTokenAmount", p.userDeposit[key], 1040)
+	expectInt(t, "totalPrincipalBorrow", p.totalPrincipalBorrow, 300)
+	expectInt(t, "totalBorrowPrincipal", p.collateralBorrow[testToken], 300)
+}
+
+// 清算时合约扣减 totalCollateral 和按借款指数折算的借款本金，但不扣减被清算用户的 userDepositTokenAmount
+func TestProjectionLiquidateQuirk(t *testing.T) {
+	p := newProjection()
+	key := tokenUser{token: testToken, user: testUser}
+	for _, a := range []*types.UserActivity{
+		activity(types.ActionCollateralDeposit, "1000", "0"),
+		activity(types.ActionBorrow, "500", "0"),
+	} {
+		if err := p.apply(a, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// borrowIndex = 1.25 ray，还款 250 折算本金 200
+	borrowIndex := new(big.Int).Div(new(big.Int).Mul(ray, big.NewInt(5)), big.NewInt(4))
+	if err := p.apply(activity(types.ActionLiquidated, "250", "300"), borrowIndex); err != nil {
+		t.Fatal(err)
+	}
+	expectInt(t, "totalCollateral", p.collateralTotal[testToken], 700)
+	expectInt(t, "userDepositTokenAmount", p.userDeposit[key], 1000)
+	expectInt(t, "totalPrincipalBorrow", p.totalPrincipalBorrow, 300)
+	expectInt(t, "totalBorrowPrincipal", p.collateralBorrow[testToken], 300)
+}
+
+// 缺少清算区块的借款指数时只扣减抵押物
+func TestProjectionLiquidateWithoutIndex(t *testing.T) {
+	p := newProjection()
+	for _, a := range []*types.UserActivity{
+		activity(types.ActionCollateralDeposit, "1000", "0"),
+		activity(types.ActionBorrow, "500", "0"),
+		activity(types.ActionLiquidated, "250", "300"),
+	} {
+		if err := p.apply(a, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectInt(t, "totalCollateral", p.collateralTotal[testToken], 700)
+	expectInt(t, "totalPrincipalBorrow", p.totalPrincipalBorrow, 500)
+}
+
+// 取款时合约按 amount - interest 扣减 totalPrincipalLend
+func TestProjectionLendWithdraw(t *testing.T) {
+	p := newProjection()
+	withdraw := activity(types.ActionLendWithdraw, "120", "0")
+	withdraw.Interest = "20"
+	for _, a := range []*types.UserActivity{activity(types.ActionLendDeposit, "500", "0"), withdraw} {
+		if err := p.apply(a, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectInt(t, "totalPrincipalLend", p.totalPrincipalLend, 400)
+	if err := p.apply(activity(types.ActionLendDeposit, "x", "0"), nil); err == nil {
+		t.Fatal("expected error on invalid amount")
+	}
+}
@@ -0,0 +1,253 @@
+package reconcile
+
+import (
+	"aave_schedule/config"
+	"aave_schedule/logger/xzap"
+	"aave_schedule/service/event"
+	"aave_schedule/service/poolstate"
+	"aave_schedule/types"
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/threading"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service 对账服务，比较根据已同步事件推算的数据和链上合约状态，发现处理事件时的遗漏和错误
+type Service struct {
+	ctx          context.Context
+	cfg          *config.Config
+	db           *gorm.DB
+	reader       *poolstate.Reader
+	eventService *event.Service
+}
+
+// Report 一次对账的结果
+type Report struct {
+	RunID       int64
+	BlockNumber uint64
+	Checked     int
+	Drifts      []*types.ReconcileDrift
+	Repaired    bool
+	Remaining   int // 修复后重新对账仍存在的偏差条数
+}
+
+// New 创建对账服务，开启自动修复时必须配置部署区块，否则修复会从创世区块开始重新同步
+func New(ctx context.Context, cfg *config.Config, db *gorm.DB, reader *poolstate.Reader, eventService *event.Service) (*Service, error) {
+	if cfg.ReconcileCfg.Repair && cfg.ReconcileCfg.DeployBlock == 0 {
+		return nil, errors.New("reconcile_cfg.deploy_block is required when repair is enabled")
+	}
+	return &Service{
+		ctx:          ctx,
+		cfg:          cfg,
+		db:           db,
+		reader:       reader,
+		eventService: eventService,
+	}, nil
+}
+
+// Start 按配置的间隔定时对账，间隔为0时不开启
+func (s *Service) Start() {
+	interval := s.cfg.ReconcileCfg.Interval
+	if interval <= 0 {
+		return
+	}
+	threading.GoSafe(func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Run(s.cfg.ReconcileCfg.Repair); err != nil {
+					xzap.WithContext(s.ctx).Error("reconcile failed", zap.Error(err))
+				}
+			}
+		}
+	})
+}
+
+// Run 执行一次对账，repair为true且存在偏差时从部署区块重建用户操作记录，然后重新对账
+func (s *Service) Run(repair bool) (*Report, error) {
+	if repair && s.cfg.ReconcileCfg.DeployBlock == 0 {
+		return nil, errors.New("reconcile_cfg.deploy_block is required to repair")
+	}
+	report, err := s.check()
+	if err != nil {
+		return nil, err
+	}
+	if repair && len(report.Drifts) > 0 {
+		if err := s.eventService.ReplayActivities(s.cfg.ReconcileCfg.DeployBlock, report.BlockNumber, s.cfg.ReconcileCfg.RepairBatchBlocks); err != nil {
+			return report, errors.Wrap(err, "failed on replay activities")
+		}
+		report.Repaired = true
+		if err := s.db.WithContext(s.ctx).
+			Table(types.GetReconcileDriftTableName()).
+			Where("run_id = ?", report.RunID).
+			Updates(map[string]interface{}{"repaired": true, "update_time": time.Now().Unix()}).Error; err != nil {
+			xzap.WithContext(s.ctx).Error("failed on mark drift repaired", zap.Error(err))
+		}
+		after, err := s.check()
+		if err != nil {
+			return report, errors.Wrap(err, "failed on reconcile after repair")
+		}
+		report.Remaining = len(after.Drifts)
+	}
+	xzap.WithContext(s.ctx).Info("reconcile finished",
+		zap.Int64("run_id", report.RunID),
+		zap.Uint64("block_number", report.BlockNumber),
+		zap.Int("checked", report.Checked),
+		zap.Int("drifts", len(report.Drifts)),
+		zap.Bool("repaired", report.Repaired),
+		zap.Int("remaining", report.Remaining))
+	return report, nil
+}
+
+// check 在已同步完成的最新区块上比较推算值和链上值，记录偏差并更新监控指标
+func (s *Service) check() (*Report, error) {
+	blockNumber, err := s.indexedBlock()
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{RunID: time.Now().UnixMilli(), BlockNumber: blockNumber}
+
+	p, err := s.loadProjection(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	block := new(big.Int).SetUint64(blockNumber)
+	snapshot, err := s.reader.Snapshot(s.ctx, block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read pool snapshot")
+	}
+
+	compare := func(scope, token, user string, indexed, chain *big.Int) {
+		report.Checked++
+		diff := new(big.Int).Sub(chain, indexed)
+		if scope != types.ReconcileScopeUserDeposit {
+			driftValue.WithLabelValues(scope, token).Set(bigToFloat(diff))
+		}
+		if diff.Sign() == 0 {
+			return
+		}
+		report.Drifts = append(report.Drifts, &types.ReconcileDrift{
+			RunID:        report.RunID,
+			BlockNumber:  int64(blockNumber),
+			Scope:        scope,
+			TokenAddress: token,
+			UserAddress:  user,
+			IndexedValue: indexed.String(),
+			ChainValue:   chain.String(),
+			Diff:         diff.String(),
+		})
+	}
+
+	compare(types.ReconcileScopeTotalPrincipalLend, "", "", p.totalPrincipalLend, snapshot.TotalPrincipalLend)
+	compare(types.ReconcileScopeTotalPrincipalBorrow, "", "", p.totalPrincipalBorrow, snapshot.TotalPrincipalBorrow)
+
+	chainDeposits := make(map[tokenUser]*big.Int)
+	for _, collateral := range snapshot.Collaterals {
+		token := strings.ToLower(collateral.Token.Hex())
+		compare(types.ReconcileScopeCollateralTotal, token, "", valueOf(p.collateralTotal[token]), collateral.TotalCollateral)
+		compare(types.ReconcileScopeCollateralBorrow, token, "", valueOf(p.collateralBorrow[token]), collateral.TotalBorrowPrincipal)
+		delete(p.collateralTotal, token)
+		delete(p.collateralBorrow, token)
+		for _, borrower := range collateral.Borrowers {
+			chainDeposits[tokenUser{token: token, user: strings.ToLower(borrower.User.Hex())}] = borrower.DepositAmount
+		}
+	}
+	// 同步数据中存在但链上已不支持的抵押代币
+	for token, value := range p.collateralTotal {
+		compare(types.ReconcileScopeCollateralTotal, token, "", value, new(big.Int))
+	}
+	for token, value := range p.collateralBorrow {
+		compare(types.ReconcileScopeCollateralBorrow, token, "", value, new(big.Int))
+	}
+
+	// 只抵押未借款的用户不在 tokenBorrower 中，需要单独读取
+	var pairs []poolstate.TokenUser
+	var keys []tokenUser
+	for key := range p.userDeposit {
+		if _, ok := chainDeposits[key]; !ok {
+			pairs = append(pairs, poolstate.TokenUser{Token: common.HexToAddress(key.token), User: common.HexToAddress(key.user)})
+			keys = append(keys, key)
+		}
+	}
+	deposits, err := s.reader.UserDeposits(s.ctx, block, pairs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read user deposits")
+	}
+	for i, key := range keys {
+		chainDeposits[key] = deposits[i]
+	}
+	for key, chainValue := range chainDeposits {
+		compare(types.ReconcileScopeUserDeposit, key.token, key.user, valueOf(p.userDeposit[key]), chainValue)
+	}
+
+	if err := s.saveDrifts(report.Drifts); err != nil {
+		return nil, err
+	}
+	counts := map[string]int{
+		types.ReconcileScopeTotalPrincipalLend:   0,
+		types.ReconcileScopeTotalPrincipalBorrow: 0,
+		types.ReconcileScopeCollateralTotal:      0,
+		types.ReconcileScopeCollateralBorrow:     0,
+		types.ReconcileScopeUserDeposit:          0,
+	}
+	for _, drift := range report.Drifts {
+		counts[drift.Scope]++
+	}
+	for scope, count := range counts {
+		driftItems.WithLabelValues(scope).Set(float64(count))
+	}
+	lastRunBlock.Set(float64(blockNumber))
+	lastRunTimestamp.Set(float64(time.Now().Unix()))
+	return report, nil
+}
+
+// indexedBlock 同步任务已完整处理的最新区块，同步任务会先把 last_indexed_block 更新为正在处理的起始区块
+func (s *Service) indexedBlock() (uint64, error) {
+	var indexedStatus types.IndexedStatus
+	err := s.db.WithContext(s.ctx).
+		Select("chain_id,last_indexed_block,last_indexed_time").
+		Table(types.GetIndexedStatusTableName()).
+		Where("chain_id = ?", s.cfg.ChainCfg.ID).
+		First(&indexedStatus).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "failed on get indexed status")
+	}
+	if indexedStatus.LastIndexedBlock <= 0 {
+		return 0, errors.New("nothing indexed yet")
+	}
+	return uint64(indexedStatus.LastIndexedBlock - 1), nil
+}
+
+func (s *Service) saveDrifts(drifts []*types.ReconcileDrift) error {
+	if len(drifts) == 0 {
+		return nil
+	}
+	now := int(time.Now().Unix())
+	for _, drift := range drifts {
+		drift.CreateTime = now
+		drift.UpdateTime = now
+		drift.Creator = "system"
+		drift.Updater = "system"
+	}
+	if err := s.db.WithContext(s.ctx).Table(types.GetReconcileDriftTableName()).CreateInBatches(drifts, 100).Error; err != nil {
+		return errors.Wrap(err, "failed on save reconcile drifts")
+	}
+	return nil
+}
+
+func valueOf(value *big.Int) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value
+}
@@ -2,15 +2,21 @@ package service
 
 import (
 	"aave_schedule/chain"
+	"aave_schedule/chain/abis"
 	"aave_schedule/chain/chainclient"
 	"aave_schedule/config"
 	"aave_schedule/model"
 	"aave_schedule/service/event"
+	"aave_schedule/service/paramwatch"
+	"aave_schedule/service/poolstate"
+	"aave_schedule/service/reconcile"
 	"aave_schedule/stores/xkv"
 	"context"
 	"fmt"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
 	"github.com/pkg/errors"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/kv"
@@ -25,6 +31,8 @@ type Service struct {
 	db           *gorm.DB
 	wg           *sync.WaitGroup
 	eventService *event.Service
+	reconcile    *reconcile.Service
+	paramWatch   *paramwatch.Service
 }
 
 func New(ctx context.Context, cfg *config.Config) (*Service, error) {
@@ -45,6 +53,7 @@ func New(ctx context.Context, cfg *config.Config) (*Service, error) {
 	var err error
 	db := model.NewDB(&cfg.DB)
 	var eventService *event.Service
+	var poolAbis map[string]abi.ABI
 	var chainClient chainclient.ChainClient
 	fmt.Println("chainClient url:" + cfg.AnkrCfg.HttpsUrl + cfg.AnkrCfg.ApiKey)
 
@@ -55,7 +64,28 @@ func New(ctx context.Context, cfg *config.Config) (*Service, error) {
 
 	switch cfg.ChainCfg.ID {
 	case chain.EthChainID, chain.OptimismChainID, chain.SepoliaChainID:
-		eventService = event.New(ctx, cfg, db, kvStore, chainClient, cfg.ChainCfg.ID, cfg.ChainCfg.Name)
+		poolAbis, err = loadPoolAbis(&cfg.ContractCfg)
+		if err != nil {
+			return nil, err
+		}
+		eventService, err = event.New(ctx, cfg, db, kvStore, chainClient, cfg.ChainCfg.ID, cfg.ChainCfg.Name, poolAbis)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create event service")
+		}
+	}
+
+	var reconcileService *reconcile.Service
+	var paramWatchService *paramwatch.Service
+	if eventService != nil {
+		reader, err := poolstate.NewReader(chainClient, poolAbis[cfg.ContractCfg.AbiVersion], cfg.ContractCfg.AavePoolAddress)
+		if err != nil {
+			return nil, err
+		}
+		reconcileService, err = reconcile.New(ctx, cfg, db, reader, eventService)
+		if err != nil {
+			return nil, err
+		}
+		paramWatchService = paramwatch.New(ctx, cfg, db, chainClient, reader)
 	}
 
 	serviceContext := Service{
@@ -64,13 +94,59 @@ func New(ctx context.Context, cfg *config.Config) (*Service, error) {
 		db:           db,
 		kvStore:      kvStore,
 		eventService: eventService,
+		reconcile:    reconcileService,
+		paramWatch:   paramWatchService,
 		wg:           &sync.WaitGroup{},
 	}
 	return &serviceContext, nil
 }
 
+// loadPoolAbis 读取pool合约各版本的ABI，返回版本到ABI的映射，abi_version 对应的版本必须配置
+// 未配置 abi_files 时使用内联的 abijson 作为 abi_version 版本
+func loadPoolAbis(cfg *config.ContractCfg) (map[string]abi.ABI, error) {
+	poolAbis := make(map[string]abi.ABI)
+	if len(cfg.AbiFiles) > 0 {
+		if _, ok := cfg.AbiFiles[cfg.AbiVersion]; !ok {
+			return nil, errors.Errorf("abi file of pool version %q not configured", cfg.AbiVersion)
+		}
+		for version, file := range cfg.AbiFiles {
+			parsedAbi, err := abis.Load(file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed on load pool abi %s", version)
+			}
+			poolAbis[version] = parsedAbi
+		}
+		return poolAbis, nil
+	}
+	if cfg.AbiJson == "" {
+		return nil, errors.New("pool abi not configured")
+	}
+	parsedAbi, err := abis.Parse([]byte(cfg.AbiJson))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on parse pool abijson")
+	}
+	poolAbis[cfg.AbiVersion] = parsedAbi
+	return poolAbis, nil
+}
+
 func (s *Service) Start() error {
 	// event activities
 	s.eventService.Start()
+	// 定时对账
+	if s.reconcile != nil {
+		s.reconcile.Start()
+	}
+	// 风险参数监控
+	if s.paramWatch != nil {
+		s.paramWatch.Start()
+	}
 	return nil
 }
+
+// Reconcile 执行一次对账
+func (s *Service) Reconcile(repair bool) (*reconcile.Report, error) {
+	if s.reconcile == nil {
+		return nil, errors.Errorf("reconcile not supported on chain %d", s.config.ChainCfg.ID)
+	}
+	return s.reconcile.Run(repair)
+}
@@ -14,10 +14,15 @@ create table collateral
     interest_rate          int          default 0   not null comment '动态年化利率',
     liquidation_threshold  int          default 0   not null,
     collateralization_rate int          default 0   not null,
-    create_time            int                      not null comment '创建时间',
+    block_number           bigint       default 0   not null comment '事件所在区块号',
+    tx_hash                char(66)     default ''  not null comment '事件所在交易哈希',
+    log_index              int          default 0   not null comment '日志序号',
+    create_time            int                      not null comment '创建时间，事件所在区块的时间',
     update_time            int                      not null comment '更新时间',
     creator                char(42)                 not null comment '创建人',
-    updater                char(42)                 not null comment '更新人'
+    updater                char(42)                 not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_block_number (block_number)
 );
 */
 
@@ -33,6 +38,9 @@ type Collateral struct {
 	HealthFactor          int    `gorm:"column:health_factor;default:0;not null" json:"health_factor"`
 	LiquidationThreshold  int    `gorm:"column:liquidation_threshold;default:0;not null" json:"liquidation_threshold"`
 	CollateralizationRate int    `gorm:"column:collateralization_rate;not null" json:"collateralization_rate"`
+	BlockNumber           int64  `gorm:"column:block_number;not null;default:0" json:"block_number"`
+	TxHash                string `gorm:"column:tx_hash;not null;default:''" json:"tx_hash"`
+	LogIndex              int    `gorm:"column:log_index;not null;default:0" json:"log_index"`
 	CreateTime            int    `gorm:"column:create_time;not null" json:"create_time"`
 	UpdateTime            int    `gorm:"column:update_time;not null" json:"update_time"`
 	Creator               string `gorm:"column:creator;not null" json:"creator"`
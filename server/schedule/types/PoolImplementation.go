@@ -0,0 +1,50 @@
+package types
+
+/**
+create table aave.pool_implementation
+(
+    id                     bigint auto_increment primary key not null comment '主键ID,自增',
+    proxy_address          char(42)                          not null comment '代理合约地址，小写',
+    implementation_address char(42)                          not null comment '实现合约地址，小写',
+    abi_version            varchar(32)                       not null comment '解析该实现合约日志使用的ABI版本',
+    source                 varchar(16)                       not null comment '发现方式，event为Upgraded事件，slot为读取ERC-1967存储槽',
+    tx_hash                char(66)     default ''           not null comment '升级交易哈希，slot方式为空',
+    log_index              int          default 0            not null comment '日志序号，slot方式为0',
+    block_number           bigint                            not null comment '生效区块号，slot方式为发现变化的区块',
+    block_time             bigint                            not null comment '区块时间戳',
+    create_time            bigint                            not null comment '创建时间',
+    update_time            bigint                            not null comment '更新时间',
+    creator                char(42)                          not null comment '创建人',
+    updater                char(42)                          not null comment '更新人',
+    unique key uk_proxy_implementation_block (proxy_address, implementation_address, block_number),
+    key idx_proxy_block (proxy_address, block_number)
+);
+*/
+
+const (
+	// ImplementationSourceEvent 通过 Upgraded 事件发现的升级
+	ImplementationSourceEvent = "event"
+	// ImplementationSourceSlot 通过读取ERC-1967实现合约存储槽发现的升级
+	ImplementationSourceSlot = "slot"
+)
+
+// PoolImplementation pool代理合约的实现合约历史，按生效区块选择解析日志的ABI版本
+type PoolImplementation struct {
+	ID                    int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ProxyAddress          string `gorm:"column:proxy_address;not null" json:"proxy_address"`
+	ImplementationAddress string `gorm:"column:implementation_address;not null" json:"implementation_address"`
+	AbiVersion            string `gorm:"column:abi_version;not null" json:"abi_version"`
+	Source                string `gorm:"column:source;not null" json:"source"`
+	TxHash                string `gorm:"column:tx_hash;not null;default:''" json:"tx_hash"`
+	LogIndex              int    `gorm:"column:log_index;not null;default:0" json:"log_index"`
+	BlockNumber           int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime             int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime            int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime            int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator               string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater               string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetPoolImplementationTableName() string {
+	return "pool_implementation"
+}
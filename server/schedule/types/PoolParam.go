@@ -0,0 +1,45 @@
+package types
+
+/**
+create table aave.pool_param
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    param_name     varchar(64)                       not null comment '参数名，与合约getter同名，抵押代币参数为liquidationThreshold、collateralizationRatio',
+    scope          char(42)     default ''           not null comment '参数作用范围，全局参数为空，抵押代币参数为代币地址，小写',
+    value          varchar(100)                      not null comment '参数值，合约精度',
+    previous_value varchar(100) default ''           not null comment '变化前的值，第一个版本为空',
+    version        int                               not null comment '参数版本，同一参数从1开始递增',
+    change_ratio   varchar(32)  default '0'          not null comment '相对变化比例，(value - previous_value) / previous_value',
+    alerted        tinyint(1)   default 0            not null comment '变化是否超过告警阈值',
+    block_number   bigint                            not null comment '读取到变化的区块号',
+    block_time     bigint                            not null comment '读取到变化的区块时间戳',
+    create_time    bigint                            not null comment '创建时间',
+    update_time    bigint                            not null comment '更新时间',
+    creator        char(42)                          not null comment '创建人',
+    updater        char(42)                          not null comment '更新人',
+    unique key uk_param_scope_version (param_name, scope, version),
+    key idx_block_number (block_number)
+);
+*/
+
+// PoolParam pool合约风险参数的版本历史，owner修改参数没有事件，按区块区间读取getter，值变化时记录一个新版本
+type PoolParam struct {
+	ID            int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ParamName     string `gorm:"column:param_name;not null" json:"param_name"`
+	Scope         string `gorm:"column:scope;not null;default:''" json:"scope"`
+	Value         string `gorm:"column:value;not null" json:"value"`
+	PreviousValue string `gorm:"column:previous_value;not null;default:''" json:"previous_value"`
+	Version       int    `gorm:"column:version;not null" json:"version"`
+	ChangeRatio   string `gorm:"column:change_ratio;not null;default:'0'" json:"change_ratio"`
+	Alerted       bool   `gorm:"column:alerted;not null;default:0" json:"alerted"`
+	BlockNumber   int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime     int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime    int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime    int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator       string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater       string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetPoolParamTableName() string {
+	return "pool_param"
+}
@@ -0,0 +1,64 @@
+package types
+
+/**
+create table aave.pool_transaction
+(
+    id                  bigint auto_increment primary key not null comment '主键ID,自增',
+    tx_hash             char(66)                          not null comment '交易哈希',
+    tx_index            int                               not null comment '交易在区块中的序号',
+    from_address        char(42)                          not null comment '交易发送方地址，小写',
+    to_address          char(42)     default ''           not null comment '交易接收方地址，小写，创建合约时为空',
+    method_selector     char(10)     default ''           not null comment '调用数据的前4字节，0x开头',
+    method              varchar(64)  default ''           not null comment 'pool合约ABI中的方法名，不是直接调用pool合约或未知方法时为空',
+    status              tinyint                           not null comment '交易状态，1成功，0失败',
+    log_count           int          default 0            not null comment '交易中pool合约发出的日志数量',
+    gas_limit           bigint                            not null comment '交易的gas上限',
+    gas_used            bigint                            not null comment '交易实际消耗的gas',
+    effective_gas_price varchar(100)                      not null comment '实际gas价格，wei',
+    fee                 varchar(100)                      not null comment '交易手续费，gas_used * effective_gas_price，wei',
+    block_number        bigint                            not null comment '区块号',
+    block_time          bigint                            not null comment '区块时间戳',
+    create_time         bigint                            not null comment '创建时间',
+    update_time         bigint                            not null comment '更新时间',
+    creator             char(42)                          not null comment '创建人',
+    updater             char(42)                          not null comment '更新人',
+    unique key uk_tx_hash (tx_hash),
+    key idx_method_time (method, block_time),
+    key idx_status_time (status, block_time),
+    key idx_from_address (from_address)
+);
+*/
+
+const (
+	// TxStatusFailed 交易失败
+	TxStatusFailed = 0
+	// TxStatusSuccess 交易成功
+	TxStatusSuccess = 1
+)
+
+// PoolTransaction 与pool合约交互的交易及其gas消耗，包括直接调用pool合约的失败交易和发出pool合约日志的交易
+type PoolTransaction struct {
+	ID                int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TxHash            string `gorm:"column:tx_hash;not null" json:"tx_hash"`
+	TxIndex           int    `gorm:"column:tx_index;not null" json:"tx_index"`
+	FromAddress       string `gorm:"column:from_address;not null" json:"from_address"`
+	ToAddress         string `gorm:"column:to_address;not null;default:''" json:"to_address"`
+	MethodSelector    string `gorm:"column:method_selector;not null;default:''" json:"method_selector"`
+	Method            string `gorm:"column:method;not null;default:''" json:"method"`
+	Status            int    `gorm:"column:status;not null" json:"status"`
+	LogCount          int    `gorm:"column:log_count;not null;default:0" json:"log_count"`
+	GasLimit          int64  `gorm:"column:gas_limit;not null" json:"gas_limit"`
+	GasUsed           int64  `gorm:"column:gas_used;not null" json:"gas_used"`
+	EffectiveGasPrice string `gorm:"column:effective_gas_price;not null" json:"effective_gas_price"`
+	Fee               string `gorm:"column:fee;not null" json:"fee"`
+	BlockNumber       int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime         int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime        int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime        int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator           string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater           string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetPoolTransactionTableName() string {
+	return "pool_transaction"
+}
@@ -0,0 +1,58 @@
+package types
+
+/**
+create table aave.reconcile_drift
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    run_id         bigint                            not null comment '对账批次，对账开始时间戳（毫秒）',
+    block_number   bigint                            not null comment '对账所在区块',
+    scope          varchar(32)                       not null comment '对账项',
+    token_address  char(42)     default ''           not null comment '抵押代币地址，全局项为空',
+    user_address   char(42)     default ''           not null comment '用户地址，非用户项为空',
+    indexed_value  varchar(100)                      not null comment '根据已同步事件计算的值',
+    chain_value    varchar(100)                      not null comment '链上读取的值',
+    diff           varchar(100)                      not null comment 'chain_value - indexed_value',
+    repaired       tinyint      default 0            not null comment '是否已触发修复',
+    create_time    bigint                            not null comment '创建时间',
+    update_time    bigint                            not null comment '更新时间',
+    creator        char(42)                          not null comment '创建人',
+    updater        char(42)                          not null comment '更新人',
+    key idx_run_id (run_id),
+    key idx_scope_token (scope, token_address)
+);
+*/
+
+const (
+	// ReconcileScopeTotalPrincipalLend 全局存款本金 totalPrincipalLend
+	ReconcileScopeTotalPrincipalLend = "total_principal_lend"
+	// ReconcileScopeTotalPrincipalBorrow 全局借款本金 totalPrincipalBorrow
+	ReconcileScopeTotalPrincipalBorrow = "total_principal_borrow"
+	// ReconcileScopeCollateralTotal 抵押代币总抵押数量 collaterals(token).totalCollateral
+	ReconcileScopeCollateralTotal = "collateral_total"
+	// ReconcileScopeCollateralBorrow 抵押代币总借款本金 collaterals(token).totalBorrowPrincipal
+	ReconcileScopeCollateralBorrow = "collateral_borrow_principal"
+	// ReconcileScopeUserDeposit 用户抵押物数量 userDepositTokenAmount(token, user)
+	ReconcileScopeUserDeposit = "user_deposit"
+)
+
+// ReconcileDrift 根据上面的表结构，我们可以定义一个ReconcileDrift结构体
+type ReconcileDrift struct {
+	ID           int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RunID        int64  `gorm:"column:run_id;not null" json:"run_id"`
+	BlockNumber  int64  `gorm:"column:block_number;not null" json:"block_number"`
+	Scope        string `gorm:"column:scope;not null" json:"scope"`
+	TokenAddress string `gorm:"column:token_address;not null;default:''" json:"token_address"`
+	UserAddress  string `gorm:"column:user_address;not null;default:''" json:"user_address"`
+	IndexedValue string `gorm:"column:indexed_value;not null" json:"indexed_value"`
+	ChainValue   string `gorm:"column:chain_value;not null" json:"chain_value"`
+	Diff         string `gorm:"column:diff;not null" json:"diff"`
+	Repaired     bool   `gorm:"column:repaired;not null;default:0" json:"repaired"`
+	CreateTime   int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime   int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator      string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater      string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetReconcileDriftTableName() string {
+	return "reconcile_drift"
+}
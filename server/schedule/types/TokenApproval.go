@@ -0,0 +1,43 @@
+package types
+
+/**
+create table aave.token_approval
+(
+    id              bigint auto_increment primary key not null comment '主键ID,自增',
+    token_address   char(42)     not null comment '代币合约地址，小写',
+    owner_address   char(42)     not null comment '授权人地址，小写',
+    spender_address char(42)     not null comment '被授权地址，小写',
+    amount          varchar(100) not null comment '授权额度，链上精度',
+    tx_hash         char(66)     not null comment '交易哈希',
+    log_index       int          not null comment '日志序号',
+    block_number    bigint       not null comment '区块号',
+    block_time      bigint       not null comment '区块时间戳',
+    create_time     bigint       not null comment '创建时间',
+    update_time     bigint       not null comment '更新时间',
+    creator         char(42)     not null comment '创建人',
+    updater         char(42)     not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_token_owner_spender (token_address, owner_address, spender_address)
+);
+*/
+
+// TokenApproval ERC-20代币的 Approval 事件记录
+type TokenApproval struct {
+	ID             int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TokenAddress   string `gorm:"column:token_address;not null" json:"token_address"`
+	OwnerAddress   string `gorm:"column:owner_address;not null" json:"owner_address"`
+	SpenderAddress string `gorm:"column:spender_address;not null" json:"spender_address"`
+	Amount         string `gorm:"column:amount;not null" json:"amount"`
+	TxHash         string `gorm:"column:tx_hash;not null" json:"tx_hash"`
+	LogIndex       int    `gorm:"column:log_index;not null" json:"log_index"`
+	BlockNumber    int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime      int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime     int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime     int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator        string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater        string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetTokenApprovalTableName() string {
+	return "token_approval"
+}
@@ -0,0 +1,37 @@
+package types
+
+/**
+create table aave.token_price
+(
+    id             bigint auto_increment primary key not null comment '主键ID,自增',
+    oracle_address char(42)     not null comment '预言机合约地址，小写',
+    token_address  char(42)     not null comment '代币合约地址，小写',
+    price          varchar(100) not null comment '预言机价格，预言机精度',
+    block_number   bigint       not null comment '读取价格的区块号',
+    block_time     bigint       not null comment '读取价格的区块时间戳',
+    create_time    bigint       not null comment '创建时间',
+    update_time    bigint       not null comment '更新时间',
+    creator        char(42)     not null comment '创建人',
+    updater        char(42)     not null comment '更新人',
+    unique key uk_oracle_token_block (oracle_address, token_address, block_number),
+    key idx_token_block (token_address, block_number)
+);
+*/
+
+// TokenPrice 预言机价格变化记录，预言机不发出事件，按同步批次读取价格，价格变化时记录一条
+type TokenPrice struct {
+	ID            int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	OracleAddress string `gorm:"column:oracle_address;not null" json:"oracle_address"`
+	TokenAddress  string `gorm:"column:token_address;not null" json:"token_address"`
+	Price         string `gorm:"column:price;not null" json:"price"`
+	BlockNumber   int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime     int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime    int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime    int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator       string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater       string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetTokenPriceTableName() string {
+	return "token_price"
+}
@@ -0,0 +1,44 @@
+package types
+
+/**
+create table aave.token_transfer
+(
+    id            bigint auto_increment primary key not null comment '主键ID,自增',
+    token_address char(42)     not null comment '代币合约地址，小写',
+    from_address  char(42)     not null comment '转出地址，小写',
+    to_address    char(42)     not null comment '转入地址，小写',
+    amount        varchar(100) not null comment '转账数量，链上精度',
+    tx_hash       char(66)     not null comment '交易哈希',
+    log_index     int          not null comment '日志序号',
+    block_number  bigint       not null comment '区块号',
+    block_time    bigint       not null comment '区块时间戳',
+    create_time   bigint       not null comment '创建时间',
+    update_time   bigint       not null comment '更新时间',
+    creator       char(42)     not null comment '创建人',
+    updater       char(42)     not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_token_from (token_address, from_address),
+    key idx_token_to (token_address, to_address)
+);
+*/
+
+// TokenTransfer ERC-20代币的 Transfer 事件记录
+type TokenTransfer struct {
+	ID           int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TokenAddress string `gorm:"column:token_address;not null" json:"token_address"`
+	FromAddress  string `gorm:"column:from_address;not null" json:"from_address"`
+	ToAddress    string `gorm:"column:to_address;not null" json:"to_address"`
+	Amount       string `gorm:"column:amount;not null" json:"amount"`
+	TxHash       string `gorm:"column:tx_hash;not null" json:"tx_hash"`
+	LogIndex     int    `gorm:"column:log_index;not null" json:"log_index"`
+	BlockNumber  int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime    int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime   int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime   int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator      string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater      string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetTokenTransferTableName() string {
+	return "token_transfer"
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"aave_web/api/middleware"
+	"aave_web/errcode"
+	"aave_web/service"
+	"context"
+	"strings"
+	"sync"
+)
+
+type sessionKey struct{}
+
+// session 请求携带的session_id，第一次访问用户私有字段时才解析出登录地址
+type session struct {
+	id     string
+	svcCtx *service.ServerCtx
+
+	once      sync.Once
+	addresses map[string]bool
+	err       error
+}
+
+// withSession 记录请求的session_id，与HTTP接口使用相同的请求头
+func withSession(ctx context.Context, svcCtx *service.ServerCtx, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{id: sessionID, svcCtx: svcCtx})
+}
+
+// authorizeUser 用户的活动和清算记录只返回给已登录的地址本人，
+// 没有session或session失效时返回ErrTokenVerify，地址不属于该session时返回ErrPermissionDenied
+func authorizeUser(ctx context.Context, address string) error {
+	sess, _ := ctx.Value(sessionKey{}).(*session)
+	if sess == nil || sess.id == "" {
+		return errcode.ErrTokenVerify
+	}
+	sess.once.Do(func() {
+		addresses, err := middleware.GetSessionAddresses(sess.id, sess.svcCtx.KvStore)
+		if err != nil {
+			sess.err = err
+			return
+		}
+		sess.addresses = make(map[string]bool, len(addresses))
+		for _, addr := range addresses {
+			sess.addresses[strings.ToLower(addr)] = true
+		}
+	})
+	if sess.err != nil {
+		return errcode.ErrTokenVerify
+	}
+	if !sess.addresses[strings.ToLower(address)] {
+		return errcode.ErrPermissionDenied
+	}
+	return nil
+}
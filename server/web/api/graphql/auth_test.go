@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"aave_web/api/middleware"
+	"aave_web/errcode"
+	"aave_web/service"
+	"aave_web/stores/xkv"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	gql "github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// newSessionID 按登录服务的方式写入address的会话并返回session_id
+func newSessionID(t *testing.T, mr *miniredis.Miniredis, address string) string {
+	plain := []byte(middleware.CR_LOGIN_KEY + ":" + address)
+	block, err := aes.NewCipher([]byte(middleware.CR_LOGIN_SALT))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append(append([]byte{}, plain...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	out := make([]byte, len(data))
+	cipher.NewOFB(block, iv).XORKeyStream(out, data)
+	assert.NoError(t, mr.Set(string(plain), "1"))
+	return hex.EncodeToString(append(iv, out...))
+}
+
+func TestAuthorizeUser(t *testing.T) {
+	mr := miniredis.RunT(t)
+	svcCtx := &service.ServerCtx{KvStore: xkv.NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}})}
+	sessionID := newSessionID(t, mr, "0xAbC")
+
+	ctx := withSession(context.Background(), svcCtx, sessionID)
+	// 地址不区分大小写
+	assert.NoError(t, authorizeUser(ctx, "0xabc"))
+	assert.Equal(t, errcode.ErrPermissionDenied, authorizeUser(ctx, "0xdef"))
+
+	assert.Equal(t, errcode.ErrTokenVerify, authorizeUser(context.Background(), "0xabc"))
+	assert.Equal(t, errcode.ErrTokenVerify, authorizeUser(withSession(context.Background(), svcCtx, ""), "0xabc"))
+	assert.Equal(t, errcode.ErrTokenVerify, authorizeUser(withSession(context.Background(), svcCtx, "zz"), "0xabc"))
+
+	// 会话过期后拒绝
+	mr.FlushAll()
+	assert.Equal(t, errcode.ErrTokenVerify, authorizeUser(withSession(context.Background(), svcCtx, sessionID), "0xabc"))
+}
+
+func TestUserFieldsRequireSession(t *testing.T) {
+	schema, err := NewSchema(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// 校验在查询数据库之前，没有会话时每个私有字段都返回错误
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"user events", `{ user(address: "0x1111111111111111111111111111111111111111") { events { txHash } } }`},
+		{"user liquidations", `{ user(address: "0x1111111111111111111111111111111111111111") { liquidations { txHash } } }`},
+		{"events by user", `{ events(user: "0x1111111111111111111111111111111111111111") { items { txHash } } }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := gql.Do(gql.Params{
+				Schema:        schema,
+				RequestString: tt.query,
+				Context:       withLoaders(withSession(context.Background(), nil, ""), nil),
+			})
+			if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, errcode.ErrTokenVerify.Error(), result.Errors[0].Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package graphql
+
+import (
+	"aave_web/service"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/pkg/errors"
+)
+
+// Request GraphQL请求，GET请求时variables为JSON字符串
+type Request struct {
+	Query         string                 `json:"query" form:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+}
+
+// Handler GraphQL接口，支持GET和POST，响应为标准的GraphQL结果，不使用xhttp的响应结构
+type Handler struct {
+	svcCtx *service.ServerCtx
+	schema gql.Schema
+}
+
+// NewHandler 创建GraphQL接口，schema在启动时构建，定义有误时直接返回错误
+func NewHandler(svcCtx *service.ServerCtx) (*Handler, error) {
+	schema, err := NewSchema(svcCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on build graphql schema")
+	}
+	return &Handler{svcCtx: svcCtx, schema: schema}, nil
+}
+
+// Serve 执行查询，每个请求使用独立的loader合并同一层字段的数据库查询，
+// 用户的活动和清算记录需要在session_id请求头中携带该地址的登录会话
+func (h *Handler) Serve() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := parseRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": err.Error()}}})
+			return
+		}
+		if result := h.limit(req); result != nil {
+			c.JSON(http.StatusBadRequest, result)
+			return
+		}
+		result := gql.Do(gql.Params{
+			Schema:         h.schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        withLoaders(withSession(c.Request.Context(), h.svcCtx, c.GetHeader("session_id")), h.svcCtx),
+		})
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func parseRequest(c *gin.Context) (*Request, error) {
+	var req Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, errors.New("invalid variables")
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, errors.New("invalid request body")
+	}
+	if req.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	return &req, nil
+}
+
+// limit 解析并校验查询，超过深度或代价限制时返回错误结果，解析或校验失败时同样返回，不再执行
+func (h *Handler) limit(req *Request) *gql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := gql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		return &gql.Result{Errors: validation.Errors}
+	}
+	if err := checkLimits(h.schema, doc, req.OperationName, req.Variables); err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	return nil
+}
+
+// Subscribe 执行WebSocket中的订阅，payload与HTTP请求体相同，
+// 每次推送都用新的loader查询，返回的通道在ctx取消或订阅结束时关闭
+func (h *Handler) Subscribe(ctx context.Context, payload json.RawMessage) (<-chan interface{}, error) {
+	var req Request
+	if err := json.Unmarshal(payload, &req); err != nil || req.Query == "" {
+		return nil, errors.New("invalid subscription request")
+	}
+	if result := h.limit(&req); result != nil {
+		return nil, errors.New(result.Errors[0].Message)
+	}
+	results := gql.Subscribe(gql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(ctx, h.svcCtx),
+	})
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for result := range results {
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				// 继续读取直到执行器关闭结果通道，避免其阻塞
+				for range results {
+				}
+				return
+			}
+		}
+	}()
+	return out, nil
+}
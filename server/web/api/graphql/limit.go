@@ -0,0 +1,166 @@
+package graphql
+
+import (
+	"strconv"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxDepth 查询中字段嵌套的最大层数
+	maxDepth = 8
+	// maxCost 单次查询的最大代价，对象字段计1，列表字段的子字段代价乘以first
+	maxCost = 500
+	// positionCost User.position 的代价，每个用户读取一次链上仓位，每个抵押代币再读取两次
+	positionCost = 20
+)
+
+// fieldCosts 额外的字段代价，key为"类型.字段"
+var fieldCosts = map[string]int{
+	"User.position": positionCost,
+}
+
+// checkLimits 在执行前计算查询的深度和代价，超过限制时返回错误
+// Event.user、Liquidation.borrower 可以再次展开用户的列表字段，不限制时一次请求可以放大成上万次数据库和链上读取
+// 查询需要已经通过schema校验，片段不会循环引用
+func checkLimits(schema gql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeSubscription {
+		root = schema.SubscriptionType()
+	}
+	c := &costCounter{fragments: fragments, variables: variables, defaults: make(map[string]ast.Value)}
+	for _, def := range operation.VariableDefinitions {
+		if def.DefaultValue != nil {
+			c.defaults[def.Variable.Name.Value] = def.DefaultValue
+		}
+	}
+	cost, err := c.selectionSet(root, operation.SelectionSet, 1)
+	if err != nil {
+		return err
+	}
+	if cost > maxCost {
+		return errors.Errorf("query cost %d exceeds the limit of %d", cost, maxCost)
+	}
+	return nil
+}
+
+type costCounter struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	defaults  map[string]ast.Value
+}
+
+// selectionSet 计算一组字段的代价，depth为这些字段所在的层数
+func (c *costCounter) selectionSet(parent *gql.Object, set *ast.SelectionSet, depth int) (int, error) {
+	if set == nil || parent == nil {
+		return 0, nil
+	}
+	if depth > maxDepth {
+		return 0, errors.Errorf("query depth exceeds the limit of %d", maxDepth)
+	}
+	total := 0
+	for _, selection := range set.Selections {
+		var cost int
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost, err = c.field(parent, selection, depth)
+		case *ast.InlineFragment:
+			cost, err = c.selectionSet(parent, selection.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[selection.Name.Value]; ok {
+				cost, err = c.selectionSet(parent, fragment.SelectionSet, depth)
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
+
+// field 对象字段计1加上额外代价，带first参数的列表字段子字段代价乘以返回条数，标量字段和内省字段不计
+func (c *costCounter) field(parent *gql.Object, field *ast.Field, depth int) (int, error) {
+	def, ok := parent.Fields()[field.Name.Value]
+	if !ok || field.SelectionSet == nil {
+		return 0, nil
+	}
+	child, _ := gql.GetNamed(def.Type).(*gql.Object)
+	children, err := c.selectionSet(child, field.SelectionSet, depth+1)
+	if err != nil {
+		return 0, err
+	}
+	multiplier := 1
+	for _, arg := range def.Args {
+		if arg.PrivateName == "first" {
+			multiplier = c.first(field)
+		}
+	}
+	return 1 + fieldCosts[parent.Name()+"."+def.Name] + multiplier*children, nil
+}
+
+// first 查询中first参数的值，未指定时为默认值，超出范围时按最大值计算
+func (c *costCounter) first(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		value := arg.Value
+		if variable, ok := value.(*ast.Variable); ok {
+			name := variable.Name.Value
+			if v, ok := c.variables[name]; ok {
+				return clampFirst(variableInt(v))
+			}
+			if value, ok = c.defaults[name]; !ok {
+				return defaultFirst
+			}
+		}
+		if v, ok := value.(*ast.IntValue); ok {
+			n, err := strconv.Atoi(v.Value)
+			if err != nil {
+				return maxFirst
+			}
+			return clampFirst(n)
+		}
+		return maxFirst
+	}
+	return defaultFirst
+}
+
+// variableInt JSON中的数字解析为float64，无法识别时按最大值计算
+func variableInt(v interface{}) int {
+	switch v := v.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case nil:
+		return defaultFirst
+	}
+	return maxFirst
+}
+
+func clampFirst(n int) int {
+	if n <= 0 || n > maxFirst {
+		return maxFirst
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLimits(t *testing.T) {
+	schema, err := NewSchema(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		wantErr   string
+	}{
+		{"top level position", `{ user(address: "0x1") { address position { block } events { txHash } } }`, nil, ""},
+		{"list of events", `{ events(first: 100) { items { txHash collateral { price { price } } } } }`, nil, ""},
+		{"position in list", `{ events(first: 100) { items { user { position { block } } } } }`, nil, "query cost"},
+		{
+			"nested user lists",
+			`{ events(first: 100) { items { user { events(first: 100) { user { position { block } } } } } } }`,
+			nil, "query cost",
+		},
+		// 变量和片段中的字段同样计算代价
+		{
+			"position in fragment",
+			`query($n: Int) { events(first: $n) { items { ...u } } } fragment u on Event { user { position { block } } }`,
+			map[string]interface{}{"n": float64(50)}, "query cost",
+		},
+		{"small variable", `query($n: Int) { events(first: $n) { items { user { position { block } } } } }`, map[string]interface{}{"n": float64(2)}, ""},
+		{
+			"too deep",
+			`{ liquidations { items { borrower { events(first: 1) { user { events(first: 1) { user { events(first: 1) { txHash } } } } } } } } }`,
+			nil, "query depth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if !assert.NoError(t, err) {
+				return
+			}
+			err = checkLimits(schema, doc, "", tt.variables)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
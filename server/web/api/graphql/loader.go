@@ -0,0 +1,172 @@
+package graphql
+
+import (
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	types "aave_web/types/v1"
+	"context"
+	"strings"
+	"sync"
+)
+
+// loader 按请求合并同一层字段的查询，避免列表中每一项都单独查询数据库
+// graphql执行器先调用同一层所有字段的resolver，再依次调用返回的thunk，
+// load 只登记key，第一个thunk被调用时一次性查询所有已登记的key
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	seen    map[K]bool
+	pending []K
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		seen:    make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load 登记key并返回thunk，同一个请求内相同的key只查询一次，没有结果时返回nil
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.seen[key] {
+		l.seen[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			results, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else if v, ok := results[k]; ok {
+					l.results[k] = v
+				}
+			}
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		if v, ok := l.results[key]; ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+}
+
+// listKey 按用户批量查询最近记录的key，limit和actions相同的key合并为一次查询
+type listKey struct {
+	address string
+	limit   int
+	actions string // 逗号分隔的操作类型
+}
+
+// loaders 一次请求内使用的所有loader
+type loaders struct {
+	collateral      *loader[string, *types.Collateral]
+	price           *loader[string, *types.TokenPrice]
+	userEvents      *loader[listKey, []*types.UserActivity]
+	userLiquidation *loader[listKey, []*types.Liquidation]
+}
+
+type loadersKey struct{}
+
+// requestLoaders 保存在请求上下文中的loader，订阅每次推送前替换为新的loader，避免使用上一次推送的结果
+type requestLoaders struct {
+	svcCtx  *service.ServerCtx
+	current *loaders
+}
+
+// withLoaders 为每个请求创建新的loader，结果只在请求内复用
+func withLoaders(ctx context.Context, svcCtx *service.ServerCtx) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &requestLoaders{svcCtx: svcCtx, current: newLoaders(svcCtx)})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*requestLoaders).current
+}
+
+// resetLoaders 丢弃已缓存的结果，订阅的每次推送在同一个上下文中执行
+func resetLoaders(ctx context.Context) {
+	holder := ctx.Value(loadersKey{}).(*requestLoaders)
+	holder.current = newLoaders(holder.svcCtx)
+}
+
+func newLoaders(svcCtx *service.ServerCtx) *loaders {
+	return &loaders{
+		collateral: newLoader(func(ctx context.Context, tokens []string) (map[string]*types.Collateral, error) {
+			items, err := v1.GetLatestCollaterals(ctx, svcCtx, tokens)
+			if err != nil {
+				return nil, err
+			}
+			// collateral表中的地址不一定是小写
+			collaterals := make(map[string]*types.Collateral, len(items))
+			for _, item := range items {
+				collaterals[strings.ToLower(item.TokenAddress)] = item
+			}
+			return collaterals, nil
+		}),
+		price: newLoader(func(ctx context.Context, tokens []string) (map[string]*types.TokenPrice, error) {
+			return v1.GetLatestTokenPrices(ctx, svcCtx, tokens)
+		}),
+		userEvents: newLoader(func(ctx context.Context, keys []listKey) (map[listKey][]*types.UserActivity, error) {
+			results := make(map[listKey][]*types.UserActivity, len(keys))
+			for group, addresses := range groupListKeys(keys) {
+				var actions []string
+				if group.actions != "" {
+					actions = strings.Split(group.actions, ",")
+				}
+				grouped, err := v1.GetRecentUserActivitiesBatch(ctx, svcCtx, addresses, actions, group.limit)
+				if err != nil {
+					return nil, err
+				}
+				for _, address := range addresses {
+					items := grouped[address]
+					if items == nil {
+						items = []*types.UserActivity{}
+					}
+					results[listKey{address: address, limit: group.limit, actions: group.actions}] = items
+				}
+			}
+			return results, nil
+		}),
+		userLiquidation: newLoader(func(ctx context.Context, keys []listKey) (map[listKey][]*types.Liquidation, error) {
+			results := make(map[listKey][]*types.Liquidation, len(keys))
+			for group, addresses := range groupListKeys(keys) {
+				grouped, err := v1.GetRecentLiquidationsBatch(ctx, svcCtx, addresses, group.limit)
+				if err != nil {
+					return nil, err
+				}
+				for _, address := range addresses {
+					items := grouped[address]
+					if items == nil {
+						items = []*types.Liquidation{}
+					}
+					results[listKey{address: address, limit: group.limit}] = items
+				}
+			}
+			return results, nil
+		}),
+	}
+}
+
+// groupListKeys 按limit和actions分组，每组一次查询
+func groupListKeys(keys []listKey) map[listKey][]string {
+	groups := make(map[listKey][]string)
+	for _, key := range keys {
+		group := listKey{limit: key.limit, actions: key.actions}
+		groups[group] = append(groups[group], key.address)
+	}
+	return groups
+}
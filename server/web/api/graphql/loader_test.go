@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderBatchesKeys(t *testing.T) {
+	var calls [][]string
+	l := newLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		calls = append(calls, keys)
+		return map[string]int{"a": 1, "b": 2}, nil
+	})
+	ctx := context.Background()
+	// 先登记同一层的所有key，再依次调用thunk
+	thunks := []func() (interface{}, error){
+		l.load(ctx, "a"),
+		l.load(ctx, "b"),
+		l.load(ctx, "a"),
+		l.load(ctx, "c"),
+	}
+	var got []interface{}
+	for _, thunk := range thunks {
+		v, err := thunk()
+		assert.NoError(t, err)
+		got = append(got, v)
+	}
+	// 重复的key只查询一次，没有结果的key返回nil
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, calls)
+	assert.Equal(t, []interface{}{1, 2, 1, nil}, got)
+
+	// 已经查询过的key不再查询，新的key单独查询
+	v, err := l.load(ctx, "b")()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	_, _ = l.load(ctx, "d")()
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d"}}, calls)
+}
+
+func TestLoaderError(t *testing.T) {
+	l := newLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, errors.New("db down")
+	})
+	ctx := context.Background()
+	first, second := l.load(ctx, "a"), l.load(ctx, "b")
+	_, err := first()
+	assert.EqualError(t, err, "db down")
+	// 同一批的key都返回查询错误
+	_, err = second()
+	assert.EqualError(t, err, "db down")
+}
+
+func TestGroupListKeys(t *testing.T) {
+	groups := groupListKeys([]listKey{
+		{address: "0x1", limit: 20},
+		{address: "0x2", limit: 20},
+		{address: "0x1", limit: 5},
+		{address: "0x3", limit: 20, actions: "borrow,repay"},
+	})
+	assert.Equal(t, map[listKey][]string{
+		{limit: 20}:                          {"0x1", "0x2"},
+		{limit: 5}:                           {"0x1"},
+		{limit: 20, actions: "borrow,repay"}: {"0x3"},
+	}, groups)
+}
@@ -0,0 +1,630 @@
+package graphql
+
+import (
+	"aave_web/chain"
+	"aave_web/dao"
+	"aave_web/errcode"
+	"aave_web/logger/xzap"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	types "aave_web/types/v1"
+	"context"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultFirst 列表字段未指定first时的返回条数
+	defaultFirst = 20
+	// maxFirst 列表字段单次最多返回的条数，与REST接口的limit一致
+	maxFirst = 100
+	// feedBuffer 每个订阅的状态变化缓冲区大小
+	feedBuffer = 16
+)
+
+// user 用户节点，其它字段都按地址查询
+type user struct {
+	Address string
+}
+
+// NewSchema 创建GraphQL schema，字段的数据都通过service层读取，与REST接口保持一致
+func NewSchema(svcCtx *service.ServerCtx) (gql.Schema, error) {
+	s := &schema{svcCtx: svcCtx}
+	return gql.NewSchema(gql.SchemaConfig{
+		Query:        s.query(),
+		Subscription: s.subscription(),
+	})
+}
+
+type schema struct {
+	svcCtx *service.ServerCtx
+
+	pool           *gql.Object
+	collateral     *gql.Object
+	pricePoint     *gql.Object
+	user           *gql.Object
+	position       *gql.Object
+	borrowPosition *gql.Object
+	event          *gql.Object
+	liquidation    *gql.Object
+	eventAction    *gql.Enum
+}
+
+var eventActionValues = gql.EnumValueConfigMap{
+	"LEND_DEPOSIT":        &gql.EnumValueConfig{Value: types.ActionLendDeposit, Description: "存款"},
+	"LEND_WITHDRAW":       &gql.EnumValueConfig{Value: types.ActionLendWithdraw, Description: "取款"},
+	"COLLATERAL_DEPOSIT":  &gql.EnumValueConfig{Value: types.ActionCollateralDeposit, Description: "存入抵押物"},
+	"COLLATERAL_WITHDRAW": &gql.EnumValueConfig{Value: types.ActionCollateralWithdraw, Description: "取回抵押物"},
+	"BORROW":              &gql.EnumValueConfig{Value: types.ActionBorrow, Description: "借款"},
+	"REPAY":               &gql.EnumValueConfig{Value: types.ActionRepay, Description: "还款"},
+	"LIQUIDATED":          &gql.EnumValueConfig{Value: types.ActionLiquidated, Description: "被清算（借款人视角）"},
+	"LIQUIDATE":           &gql.EnumValueConfig{Value: types.ActionLiquidate, Description: "执行清算（清算人视角）"},
+}
+
+// types 创建所有对象类型，对象之间互相引用，字段使用thunk延迟定义
+func (s *schema) types() {
+	if s.pool != nil {
+		return
+	}
+	s.eventAction = gql.NewEnum(gql.EnumConfig{
+		Name:        "EventAction",
+		Description: "用户操作类型",
+		Values:      eventActionValues,
+	})
+	s.pricePoint = gql.NewObject(gql.ObjectConfig{
+		Name:        "PricePoint",
+		Description: "预言机价格记录，价格为预言机精度",
+		Fields: gql.Fields{
+			"oracleAddress": &gql.Field{Type: gql.String},
+			"tokenAddress":  &gql.Field{Type: gql.String},
+			"price":         &gql.Field{Type: gql.String},
+			"blockNumber":   &gql.Field{Type: gql.Int},
+			"blockTime":     &gql.Field{Type: gql.Int},
+		},
+	})
+	s.collateral = gql.NewObject(gql.ObjectConfig{
+		Name:        "Collateral",
+		Description: "抵押代币的最新借款状态",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"tokenAddress":          &gql.Field{Type: gql.String},
+				"type":                  &gql.Field{Type: gql.Int},
+				"borrowed":              &gql.Field{Type: gql.String},
+				"borrowable":            &gql.Field{Type: gql.String},
+				"utilizationRate":       &gql.Field{Type: gql.Int},
+				"interestRate":          &gql.Field{Type: gql.Int},
+				"liquidationThreshold":  &gql.Field{Type: gql.Int},
+				"collateralizationRate": &gql.Field{Type: gql.Int},
+				"blockNumber":           &gql.Field{Type: gql.Int},
+				"txHash":                &gql.Field{Type: gql.String},
+				"price": &gql.Field{
+					Type:        s.pricePoint,
+					Description: "预言机最新价格",
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						c := p.Source.(*types.Collateral)
+						return loadersFrom(p.Context).price.load(p.Context, strings.ToLower(c.TokenAddress)), nil
+					},
+				},
+			}
+		}),
+	})
+	s.pool = gql.NewObject(gql.ObjectConfig{
+		Name:        "Pool",
+		Description: "存款池最新状态",
+		Fields: gql.Fields{
+			"type":            &gql.Field{Type: gql.Int},
+			"totalBorrow":     &gql.Field{Type: gql.String},
+			"totalDeposits":   &gql.Field{Type: gql.String},
+			"utilizationRate": &gql.Field{Type: gql.Int},
+			"interestRate":    &gql.Field{Type: gql.Int},
+			"blockNumber":     &gql.Field{Type: gql.Int},
+			"txHash":          &gql.Field{Type: gql.String},
+			"collaterals": &gql.Field{
+				Type:        gql.NewList(s.collateral),
+				Description: "各抵押代币的最新状态",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return s.resolveCollaterals(p)
+				},
+			},
+		},
+	})
+	s.borrowPosition = gql.NewObject(gql.ObjectConfig{
+		Name:        "BorrowPosition",
+		Description: "用户在某个抵押代币下的借款本息和健康因子",
+		Fields: gql.Fields{
+			"collateralToken":      &gql.Field{Type: gql.String},
+			"totalWithInterest":    &gql.Field{Type: gql.String},
+			"healthFactor":         &gql.Field{Type: gql.String, Description: "6位精度"},
+			"totalCollateralValue": &gql.Field{Type: gql.String},
+			"totalDebtValue":       &gql.Field{Type: gql.String},
+			"isLiquidatable":       &gql.Field{Type: gql.Boolean},
+		},
+	})
+	s.position = gql.NewObject(gql.ObjectConfig{
+		Name:        "Position",
+		Description: "用户的链上仓位",
+		Fields: gql.Fields{
+			"block":                 &gql.Field{Type: gql.Int},
+			"lendTotalWithInterest": &gql.Field{Type: gql.String},
+			"borrows":               &gql.Field{Type: gql.NewList(s.borrowPosition)},
+		},
+	})
+	s.user = gql.NewObject(gql.ObjectConfig{
+		Name:        "User",
+		Description: "用户，按地址关联操作记录、清算记录和链上仓位",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"address": &gql.Field{Type: gql.String},
+				"events": &gql.Field{
+					Type:        gql.NewList(s.event),
+					Description: "最近的操作记录，按链上顺序倒序，需要该地址的登录会话",
+					Args: gql.FieldConfigArgument{
+						"first":   &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultFirst},
+						"actions": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(s.eventAction))},
+					},
+					Resolve: s.resolveUserEvents,
+				},
+				"liquidations": &gql.Field{
+					Type:        gql.NewList(s.liquidation),
+					Description: "最近的被清算记录，按链上顺序倒序，需要该地址的登录会话",
+					Args: gql.FieldConfigArgument{
+						"first": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultFirst},
+					},
+					Resolve: s.resolveUserLiquidations,
+				},
+				"position": &gql.Field{
+					Type:        s.position,
+					Description: "链上读取的当前仓位，需要配置链上读取",
+					Resolve:     s.resolvePosition,
+				},
+			}
+		}),
+	})
+	s.event = gql.NewObject(gql.ObjectConfig{
+		Name:        "Event",
+		Description: "用户的存取款、抵押物、借还款和清算记录",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"action":           &gql.Field{Type: s.eventAction},
+				"userAddress":      &gql.Field{Type: gql.String},
+				"tokenAddress":     &gql.Field{Type: gql.String},
+				"amount":           &gql.Field{Type: gql.String},
+				"interest":         &gql.Field{Type: gql.String},
+				"liquidityIndex":   &gql.Field{Type: gql.String},
+				"collateralAmount": &gql.Field{Type: gql.String},
+				"counterparty":     &gql.Field{Type: gql.String},
+				"txHash":           &gql.Field{Type: gql.String},
+				"logIndex":         &gql.Field{Type: gql.Int},
+				"blockNumber":      &gql.Field{Type: gql.Int},
+				"blockTime":        &gql.Field{Type: gql.Int},
+				"user": &gql.Field{
+					Type: s.user,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return &user{Address: p.Source.(*types.UserActivity).UserAddress}, nil
+					},
+				},
+				"collateral": &gql.Field{
+					Type:        s.collateral,
+					Description: "操作涉及的抵押代币，存取款记录为空",
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return s.loadCollateral(p, p.Source.(*types.UserActivity).TokenAddress), nil
+					},
+				},
+			}
+		}),
+	})
+	s.liquidation = gql.NewObject(gql.ObjectConfig{
+		Name:        "Liquidation",
+		Description: "清算记录，价格和美元价值都是预言机精度",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"borrower": &gql.Field{
+					Type: s.user,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return &user{Address: p.Source.(*types.Liquidation).Borrower}, nil
+					},
+				},
+				"liquidator": &gql.Field{
+					Type: s.user,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return &user{Address: p.Source.(*types.Liquidation).Liquidator}, nil
+					},
+				},
+				"collateralToken":  &gql.Field{Type: gql.String},
+				"liquidatedAmount": &gql.Field{Type: gql.String},
+				"collateralSeized": &gql.Field{Type: gql.String},
+				"bonusAmount":      &gql.Field{Type: gql.String},
+				"penaltyRate":      &gql.Field{Type: gql.String},
+				"collateralPrice":  &gql.Field{Type: gql.String},
+				"usdcPrice":        &gql.Field{Type: gql.String},
+				"debtValueUsd":     &gql.Field{Type: gql.String},
+				"seizedValueUsd":   &gql.Field{Type: gql.String},
+				"bonusValueUsd":    &gql.Field{Type: gql.String},
+				"txHash":           &gql.Field{Type: gql.String},
+				"logIndex":         &gql.Field{Type: gql.Int},
+				"blockNumber":      &gql.Field{Type: gql.Int},
+				"blockTime":        &gql.Field{Type: gql.Int},
+				"collateral": &gql.Field{
+					Type: s.collateral,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return s.loadCollateral(p, p.Source.(*types.Liquidation).CollateralToken), nil
+					},
+				},
+			}
+		}),
+	})
+}
+
+// connection 分页列表类型，nextCursor为空表示没有更多数据
+func connection(name string, item *gql.Object) *gql.Object {
+	return gql.NewObject(gql.ObjectConfig{
+		Name: name,
+		Fields: gql.Fields{
+			"items":      &gql.Field{Type: gql.NewList(item)},
+			"nextCursor": &gql.Field{Type: gql.String},
+		},
+	})
+}
+
+func (s *schema) query() *gql.Object {
+	s.types()
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"pool": &gql.Field{
+				Type:        s.pool,
+				Description: "存款池最新状态",
+				Resolve:     s.resolvePool,
+			},
+			"collaterals": &gql.Field{
+				Type:        gql.NewList(s.collateral),
+				Description: "各抵押代币的最新状态",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return s.resolveCollaterals(p)
+				},
+			},
+			"user": &gql.Field{
+				Type: s.user,
+				Args: gql.FieldConfigArgument{
+					"address": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					address, err := addressArg(p, "address")
+					if err != nil {
+						return nil, err
+					}
+					return &user{Address: address}, nil
+				},
+			},
+			"events": &gql.Field{
+				Type:        connection("EventConnection", s.event),
+				Description: "全部用户的操作记录，按链上顺序倒序，翻页时把nextCursor作为after传入，按user筛选时需要该地址的登录会话",
+				Args: gql.FieldConfigArgument{
+					"user":      &gql.ArgumentConfig{Type: gql.String},
+					"actions":   &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(s.eventAction))},
+					"token":     &gql.ArgumentConfig{Type: gql.String},
+					"fromBlock": &gql.ArgumentConfig{Type: gql.Int},
+					"toBlock":   &gql.ArgumentConfig{Type: gql.Int},
+					"fromTime":  &gql.ArgumentConfig{Type: gql.Int},
+					"toTime":    &gql.ArgumentConfig{Type: gql.Int},
+					"first":     &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultFirst},
+					"after":     &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: s.resolveEvents,
+			},
+			"liquidations": &gql.Field{
+				Type:        connection("LiquidationConnection", s.liquidation),
+				Description: "全协议清算记录，按链上顺序倒序，翻页时把nextCursor作为after传入",
+				Args: gql.FieldConfigArgument{
+					"borrower":        &gql.ArgumentConfig{Type: gql.String},
+					"liquidator":      &gql.ArgumentConfig{Type: gql.String},
+					"collateralToken": &gql.ArgumentConfig{Type: gql.String},
+					"fromTime":        &gql.ArgumentConfig{Type: gql.Int},
+					"toTime":          &gql.ArgumentConfig{Type: gql.Int},
+					"first":           &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultFirst},
+					"after":           &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: s.resolveLiquidations,
+			},
+			"prices": &gql.Field{
+				Type:        connection("PricePointConnection", s.pricePoint),
+				Description: "代币的预言机价格记录，按区块倒序，翻页时把nextCursor作为after传入",
+				Args: gql.FieldConfigArgument{
+					"token":    &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"fromTime": &gql.ArgumentConfig{Type: gql.Int},
+					"toTime":   &gql.ArgumentConfig{Type: gql.Int},
+					"first":    &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultFirst},
+					"after":    &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: s.resolvePrices,
+			},
+		},
+	})
+}
+
+func (s *schema) subscription() *gql.Object {
+	s.types()
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Subscription",
+		Fields: gql.Fields{
+			"poolUpdated": &gql.Field{
+				Type:        s.pool,
+				Description: "存款池状态变化时推送最新状态",
+				Subscribe:   s.subscribePool,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					resetLoaders(p.Context)
+					return p.Source, nil
+				},
+			},
+		},
+	})
+}
+
+func (s *schema) resolvePool(p gql.ResolveParams) (interface{}, error) {
+	lend, err := v1.GetLendData(p.Context, s.svcCtx)
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get lend data", err, "Get Lend Data failed.")
+	}
+	return lend, nil
+}
+
+func (s *schema) resolveCollaterals(p gql.ResolveParams) ([]*types.Collateral, error) {
+	collaterals, err := v1.GetLatestCollaterals(p.Context, s.svcCtx, nil)
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get latest collaterals", err, "Get Borrow Data failed.")
+	}
+	return collaterals, nil
+}
+
+// loadCollateral 按代币地址合并查询抵押代币，不是抵押代币时返回空
+func (s *schema) loadCollateral(p gql.ResolveParams, token string) interface{} {
+	if token == "" {
+		return nil
+	}
+	return loadersFrom(p.Context).collateral.load(p.Context, strings.ToLower(token))
+}
+
+func (s *schema) resolveUserEvents(p gql.ResolveParams) (interface{}, error) {
+	if err := authorizeUser(p.Context, p.Source.(*user).Address); err != nil {
+		return nil, err
+	}
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	key := listKey{
+		address: p.Source.(*user).Address,
+		limit:   first,
+		actions: strings.Join(stringsArg(p, "actions"), ","),
+	}
+	return loadersFrom(p.Context).userEvents.load(p.Context, key), nil
+}
+
+func (s *schema) resolveUserLiquidations(p gql.ResolveParams) (interface{}, error) {
+	if err := authorizeUser(p.Context, p.Source.(*user).Address); err != nil {
+		return nil, err
+	}
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	key := listKey{address: p.Source.(*user).Address, limit: first}
+	return loadersFrom(p.Context).userLiquidation.load(p.Context, key), nil
+}
+
+// resolvePosition 链上读取存款本息和各抵押代币下的借款，所有读取固定在最新的同一个区块
+func (s *schema) resolvePosition(p gql.ResolveParams) (interface{}, error) {
+	if s.svcCtx.Pool == nil {
+		return nil, errcode.NewCustomErr("Read user position from chain failed.")
+	}
+	result, err := v1.GetChainUserPosition(p.Context, s.svcCtx, 0, p.Source.(*user).Address)
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get chain user position", err, "Read user position from chain failed.")
+	}
+	return result, nil
+}
+
+func (s *schema) resolveEvents(p gql.ResolveParams) (interface{}, error) {
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	userAddress, err := optionalAddressArg(p, "user")
+	if err != nil {
+		return nil, err
+	}
+	if userAddress != "" {
+		if err := authorizeUser(p.Context, userAddress); err != nil {
+			return nil, err
+		}
+	}
+	token, err := optionalAddressArg(p, "token")
+	if err != nil {
+		return nil, err
+	}
+	filter := &dao.ActivityFilter{
+		Actions:      stringsArg(p, "actions"),
+		TokenAddress: token,
+		FromBlock:    int64Arg(p, "fromBlock"),
+		ToBlock:      int64Arg(p, "toBlock"),
+		FromTime:     int64Arg(p, "fromTime"),
+		ToTime:       int64Arg(p, "toTime"),
+		Limit:        first,
+	}
+	if filter.FromBlock < 0 || filter.ToBlock < 0 || (filter.ToBlock > 0 && filter.FromBlock > filter.ToBlock) {
+		return nil, errors.New("invalid block range")
+	}
+	if err := checkTimeRange(filter.FromTime, filter.ToTime); err != nil {
+		return nil, err
+	}
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		filter.CursorBlock, filter.CursorLogIndex, filter.CursorID, err = v1.DecodeActivityCursor(after)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+	var page *types.ActivityPage
+	if userAddress != "" {
+		page, err = v1.GetUserActivities(p.Context, s.svcCtx, userAddress, filter)
+	} else {
+		page, err = v1.GetActivities(p.Context, s.svcCtx, filter)
+	}
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get activities", err, "Get user activity failed.")
+	}
+	return page, nil
+}
+
+func (s *schema) resolveLiquidations(p gql.ResolveParams) (interface{}, error) {
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	filter := &dao.LiquidationFilter{
+		FromTime: int64Arg(p, "fromTime"),
+		ToTime:   int64Arg(p, "toTime"),
+		Limit:    first,
+	}
+	if filter.Borrower, err = optionalAddressArg(p, "borrower"); err != nil {
+		return nil, err
+	}
+	if filter.Liquidator, err = optionalAddressArg(p, "liquidator"); err != nil {
+		return nil, err
+	}
+	if filter.CollateralToken, err = optionalAddressArg(p, "collateralToken"); err != nil {
+		return nil, err
+	}
+	if err := checkTimeRange(filter.FromTime, filter.ToTime); err != nil {
+		return nil, err
+	}
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		filter.CursorBlock, filter.CursorLogIndex, err = v1.DecodeLogCursor(after)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+	page, err := v1.GetLiquidations(p.Context, s.svcCtx, filter)
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get liquidations", err, "Get liquidations failed.")
+	}
+	return page, nil
+}
+
+func (s *schema) resolvePrices(p gql.ResolveParams) (interface{}, error) {
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	token, err := addressArg(p, "token")
+	if err != nil {
+		return nil, err
+	}
+	filter := &dao.TokenPriceFilter{
+		TokenAddress: token,
+		FromTime:     int64Arg(p, "fromTime"),
+		ToTime:       int64Arg(p, "toTime"),
+		Limit:        first,
+	}
+	if err := checkTimeRange(filter.FromTime, filter.ToTime); err != nil {
+		return nil, err
+	}
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		filter.CursorBlock, err = strconv.ParseInt(after, 10, 64)
+		if err != nil || filter.CursorBlock <= 0 {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+	page, err := v1.GetTokenPrices(p.Context, s.svcCtx, filter)
+	if err != nil {
+		return nil, internalErr(p.Context, "failed on get token prices", err, "Get token prices failed.")
+	}
+	return page, nil
+}
+
+// subscribePool 订阅存款池状态变化，客户端取消订阅或服务退出时关闭通道
+func (s *schema) subscribePool(p gql.ResolveParams) (interface{}, error) {
+	feed := s.svcCtx.Feed.Subscribe(feedBuffer)
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		defer s.svcCtx.Feed.Unsubscribe(feed)
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case change, ok := <-feed:
+				if !ok {
+					return
+				}
+				if change.Lend == nil {
+					continue
+				}
+				select {
+				case out <- change.Lend:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// internalErr 记录内部错误，返回给客户端的只有业务错误信息
+func internalErr(ctx context.Context, msg string, err error, customMsg string) error {
+	xzap.WithContext(ctx).Error(msg, zap.Error(err))
+	return errcode.NewCustomErr(customMsg)
+}
+
+// firstArg 列表返回条数，范围 1~100
+func firstArg(p gql.ResolveParams) (int, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > maxFirst {
+		return 0, errors.Errorf("first must be between 1 and %d", maxFirst)
+	}
+	return first, nil
+}
+
+// addressArg 必填地址参数，统一转为小写
+func addressArg(p gql.ResolveParams, name string) (string, error) {
+	address, _ := p.Args[name].(string)
+	if !chain.IsHexAddress(address) {
+		return "", errors.Errorf("invalid %s address", name)
+	}
+	return strings.ToLower(address), nil
+}
+
+// optionalAddressArg 可选地址参数，未传时返回空字符串
+func optionalAddressArg(p gql.ResolveParams, name string) (string, error) {
+	if address, _ := p.Args[name].(string); address == "" {
+		return "", nil
+	}
+	return addressArg(p, name)
+}
+
+func stringsArg(p gql.ResolveParams, name string) []string {
+	values, _ := p.Args[name].([]interface{})
+	var items []string
+	for _, value := range values {
+		if item, ok := value.(string); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func int64Arg(p gql.ResolveParams, name string) int64 {
+	value, _ := p.Args[name].(int)
+	return int64(value)
+}
+
+func checkTimeRange(fromTime, toTime int64) error {
+	if fromTime < 0 || toTime < 0 || (toTime > 0 && fromTime > toTime) {
+		return errors.New("invalid time range")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"aave_web/xhttp"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"strings"
@@ -67,8 +68,27 @@ func AuthMiddleWare(ctx *xkv.Store) gin.HandlerFunc {
 	}
 }
 
+// AdminHeader 管理接口令牌请求头
+const AdminHeader = "X-Admin-Token"
+
+// AdminAuth 管理接口认证中间件，请求头中的令牌与配置不一致时返回ErrPermissionDenied
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminHeader)), []byte(token)) != 1 {
+			xhttp.Error(c, errcode.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func GetAuthUserAddress(c *gin.Context, ctx *xkv.Store) ([]string, error) {
-	values := c.Request.Header.Get("session_id")
+	return GetSessionAddresses(c.Request.Header.Get("session_id"), ctx)
+}
+
+// GetSessionAddresses 解析逗号分隔的session_id，返回登录的用户地址，WebSocket的auth消息等不经过请求头的认证也使用这里的校验
+func GetSessionAddresses(values string, ctx *xkv.Store) ([]string, error) {
 	if values == "" {
 		return nil, errors.New("failed on get token")
 	}
@@ -88,9 +108,12 @@ func GetAuthUserAddress(c *gin.Context, ctx *xkv.Store) ([]string, error) {
 		}
 		//从redis里取数据
 		result, err := ctx.Get(string(decrptCode))
-		if result == "" || err != nil {
+		if err != nil {
 			return nil, errors.Wrap(err, "failed on read cookie from cache")
 		}
+		if result == "" {
+			return nil, errors.New("session expired")
+		}
 		arr := strings.Split(string(decrptCode), CR_LOGIN_KEY+":")
 		if len(arr) != 2 {
 			return nil, errors.New("user cache info format err")
@@ -107,6 +130,10 @@ func GetAuthUserAddress(c *gin.Context, ctx *xkv.Store) ([]string, error) {
 
 func AesDecryptOFB(data []byte, key []byte) ([]byte, error) {
 	block, _ := aes.NewCipher([]byte(key))
+	// 限流中间件对所有请求解析session_id，过短的数据直接返回错误
+	if len(data) <= aes.BlockSize {
+		return nil, fmt.Errorf("data is too short")
+	}
 	iv := data[:aes.BlockSize]
 	data = data[aes.BlockSize:]
 	if len(data)%aes.BlockSize != 0 {
@@ -117,6 +144,10 @@ func AesDecryptOFB(data []byte, key []byte) ([]byte, error) {
 	mode := cipher.NewOFB(block, iv)
 	mode.XORKeyStream(out, data)
 
+	// 密钥不匹配或数据被篡改时填充不合法
+	if padding := int(out[len(out)-1]); padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("invalid padding")
+	}
 	out = PKCS7UnPadding(out)
 	return out, nil
 }
@@ -125,5 +156,8 @@ func AesDecryptOFB(data []byte, key []byte) ([]byte, error) {
 func PKCS7UnPadding(origData []byte) []byte {
 	length := len(origData)
 	unpadding := int(origData[length-1])
+	if unpadding > length {
+		return nil
+	}
 	return origData[:(length - unpadding)]
 }
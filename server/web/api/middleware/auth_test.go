@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encryptOFB 与登录服务写入session_id的方式一致：随机iv + OFB加密的PKCS7填充数据
+func encryptOFB(t *testing.T, plain, key, iv []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append(append([]byte{}, plain...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	out := make([]byte, len(data))
+	cipher.NewOFB(block, iv).XORKeyStream(out, data)
+	return append(append([]byte{}, iv...), out...)
+}
+
+func TestAesDecryptOFB(t *testing.T) {
+	key := []byte(CR_LOGIN_SALT)
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	plain := []byte(CR_LOGIN_KEY + ":0xabc")
+	valid := encryptOFB(t, plain, key, iv)
+
+	got, err := AesDecryptOFB(valid, key)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, got)
+
+	// 最后一个字节的填充值改为0
+	zeroPadding := encryptOFB(t, []byte("0123456789abcdef"), key, iv)
+	zeroPadding[len(zeroPadding)-1] ^= aes.BlockSize
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"iv only", iv},
+		{"shorter than iv", []byte("short")},
+		{"not block aligned", valid[:len(valid)-1]},
+		{"zero padding", zeroPadding},
+		{"wrong key", encryptOFB(t, plain, []byte("another_salt&$%!"), iv)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				got, err := AesDecryptOFB(tt.data, key)
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			})
+		})
+	}
+}
@@ -12,12 +12,50 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 const CacheApiPrefix = "apicache:"
 
+// 缓存标签，调度服务通知状态变化时按标签清除缓存
+const (
+	CacheTagLend             = "lend"        // 存款池数据
+	CacheTagCollateralPrefix = "collateral:" // 抵押资产数据，后接抵押资产类型
+	CacheTagUserPrefix       = "user:"       // 用户数据，后接小写用户地址
+)
+
+// CacheTagFunc 根据请求生成缓存标签
+type CacheTagFunc func(c *gin.Context) []string
+
+// StaticTags 固定的缓存标签
+func StaticTags(tags ...string) CacheTagFunc {
+	return func(c *gin.Context) []string {
+		return tags
+	}
+}
+
+// QueryTag 以查询参数为后缀的缓存标签，参数为空时不打标签
+func QueryTag(prefix, query string) CacheTagFunc {
+	return func(c *gin.Context) []string {
+		if value := c.Query(query); value != "" {
+			return []string{prefix + value}
+		}
+		return nil
+	}
+}
+
+// ParamTag 以路径参数为后缀的缓存标签，地址等参数统一转为小写
+func ParamTag(prefix, param string) CacheTagFunc {
+	return func(c *gin.Context) []string {
+		if value := c.Param(param); value != "" {
+			return []string{prefix + strings.ToLower(value)}
+		}
+		return nil
+	}
+}
+
 type responseCache struct {
 	Status int
 	Header http.Header
@@ -29,8 +67,8 @@ type responseCache struct {
 // 1. 接收一个 xkv.Store 存储实例和过期时间作为参数
 // 2. 检查请求是否有缓存,如果有且状态码为200则直接返回缓存数据
 // 3. 如果没有缓存,则继续处理请求
-// 4. 请求处理完成后,如果响应状态码为200,则将响应数据缓存起来
-func CacheApi(store *xkv.Store, expireSeconds int) gin.HandlerFunc {
+// 4. 请求处理完成后,如果响应状态码为200,则将响应数据缓存起来,并按 tagFuncs 生成的标签记录缓存key
+func CacheApi(store *xkv.Store, expireSeconds int, tagFuncs ...CacheTagFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var data xhttp.Response
 		// 创建响应体写入器用于获取响应内容
@@ -42,6 +80,7 @@ func CacheApi(store *xkv.Store, expireSeconds int) gin.HandlerFunc {
 		if cacheKey == "" {
 			xhttp.Error(c, errcode.NewCustomErr("cache error:no cache"))
 			c.Abort()
+			return
 		}
 
 		// 尝试获取缓存数据
@@ -50,17 +89,17 @@ func CacheApi(store *xkv.Store, expireSeconds int) gin.HandlerFunc {
 			cache := unserialize(cacheData)
 			if cache != nil {
 				// 如果有缓存,则直接返回缓存的响应
-				bodyLogWriter.ResponseWriter.WriteHeader(cache.Status)
-				for k, vals := range cache.Header {
-					for _, v := range vals {
-						bodyLogWriter.ResponseWriter.Header().Set(k, v)
-					}
-				}
-
 				if err := json.Unmarshal(cache.Data, &data); err == nil {
 					if data.Code == http.StatusOK {
+						for k, vals := range cache.Header {
+							for _, v := range vals {
+								bodyLogWriter.ResponseWriter.Header().Set(k, v)
+							}
+						}
+						bodyLogWriter.ResponseWriter.WriteHeader(cache.Status)
 						bodyLogWriter.ResponseWriter.Write(cache.Data)
 						c.Abort()
+						return
 					}
 				}
 			}
@@ -81,6 +120,12 @@ func CacheApi(store *xkv.Store, expireSeconds int) gin.HandlerFunc {
 					Data:   responseBody,
 				}
 				store.SetnxEx(cacheKey, serialize(storeCache), expireSeconds)
+				var tags []string
+				for _, tagFunc := range tagFuncs {
+					tags = append(tags, tagFunc(c)...)
+				}
+				// 标签集合比缓存多保留一个周期，保证清除时能找到仍然有效的缓存
+				_ = store.TagKey(cacheKey, 2*expireSeconds, tags...)
 			}
 		}
 
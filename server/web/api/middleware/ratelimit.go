@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"aave_web/config"
+	"aave_web/errcode"
+	"aave_web/logger/xzap"
+	"aave_web/stores/xkv"
+	v1 "aave_web/types/v1"
+	"aave_web/xhttp"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// ApiKeyHeader API key请求头
+	ApiKeyHeader = "X-API-Key"
+	// RateLimitPrefix 令牌桶key前缀
+	RateLimitPrefix = "ratelimit:"
+)
+
+// ApiKeyStore API key的查询和用量统计
+type ApiKeyStore interface {
+	// GetActiveApiKey 根据明文查询可用的API key，不存在或已吊销时返回nil
+	GetActiveApiKey(ctx context.Context, key string) (*v1.ApiKey, error)
+	// RecordUsage 记录API key对某个路由的一次调用
+	RecordUsage(id int64, route string) error
+}
+
+// RateLimit 是一个限流中间件函数,按客户端和路由计算Redis令牌桶
+// 主要功能包括:
+// 1. 客户端标识优先使用 X-API-Key 请求头中的API key,其次为登录地址,最后为客户端IP
+// 2. 配额优先使用API key单独设置的配额,其次为路由配额,最后为默认配额
+// 3. 携带无效的API key时返回ErrInvalidApiKey,令牌不足时返回ErrTooManyRequests
+// 4. Redis不可用时放行请求,不影响正常访问
+func RateLimit(store *xkv.Store, conf config.RateLimitConf, apiKeys ApiKeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		route := c.FullPath()
+		if route == "" {
+			// 未匹配的路由共用一个令牌桶
+			route = "*"
+		}
+		quota := config.RateQuota{Rate: conf.Rate, Burst: conf.Burst}
+		if routeQuota, ok := conf.Routes[route]; ok {
+			quota = routeQuota
+		}
+
+		var client string
+		if key := c.GetHeader(ApiKeyHeader); key != "" {
+			apiKey, err := apiKeys.GetActiveApiKey(ctx, key)
+			if err != nil {
+				xzap.WithContext(ctx).Warn("failed on get api key, limit by client ip", zap.Error(err))
+			} else if apiKey == nil {
+				xhttp.Error(c, errcode.ErrInvalidApiKey)
+				c.Abort()
+				return
+			} else {
+				client = "key:" + strconv.FormatInt(apiKey.ID, 10)
+				if apiKey.Rate > 0 && apiKey.Burst > 0 {
+					quota = config.RateQuota{Rate: apiKey.Rate, Burst: apiKey.Burst}
+				}
+				if err := apiKeys.RecordUsage(apiKey.ID, route); err != nil {
+					xzap.WithContext(ctx).Warn("failed on record api key usage", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+				}
+			}
+		}
+		if client == "" {
+			if addrs, err := GetAuthUserAddress(c, store); err == nil && len(addrs) > 0 {
+				client = "addr:" + strings.ToLower(addrs[0])
+			} else {
+				client = "ip:" + xhttp.GetClientIP(c.Request)
+			}
+		}
+		if quota.Rate <= 0 || quota.Burst <= 0 {
+			c.Next()
+			return
+		}
+
+		allowed, remaining, err := store.TakeToken(RateLimitPrefix+route+":"+client, quota.Rate, quota.Burst)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on take rate limit token", zap.String("route", route), zap.Error(err))
+			c.Next()
+			return
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(quota.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(1/quota.Rate))))
+			xhttp.Error(c, errcode.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
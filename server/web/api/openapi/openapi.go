@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version OpenAPI规范版本
+const Version = "3.0.3"
+
+// ginParamRegex gin路径参数，如 :address
+var ginParamRegex = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Operation 一个接口的文档描述
+// Request 为请求参数结构体，uri 标签生成路径参数，form 标签生成查询参数，json 标签生成请求体
+// Response 为响应中 data 字段的类型，nil 表示没有数据
+type Operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tags     []string
+	Security []string // 需要的认证方式，对应 SecuritySchemes 中的名称
+	Request  interface{}
+	Response interface{}
+}
+
+// SecurityScheme 请求头认证方式
+type SecurityScheme struct {
+	Header      string
+	Description string
+}
+
+// Spec OpenAPI文档生成器，注册路由时记录接口，第一次请求文档时生成
+type Spec struct {
+	title           string
+	version         string
+	securitySchemes map[string]SecurityScheme
+	optional        []string
+	operations      []Operation
+
+	once     sync.Once
+	document map[string]interface{}
+}
+
+// New 创建OpenAPI文档生成器
+func New(title, version string) *Spec {
+	return &Spec{
+		title:           title,
+		version:         version,
+		securitySchemes: make(map[string]SecurityScheme),
+	}
+}
+
+// AddSecurityScheme 添加请求头认证方式
+func (s *Spec) AddSecurityScheme(name string, scheme SecurityScheme) {
+	s.securitySchemes[name] = scheme
+}
+
+// AddOptionalSecurity 所有接口都可选的认证方式，如用于提高限流配额的API key
+func (s *Spec) AddOptionalSecurity(names ...string) {
+	s.optional = append(s.optional, names...)
+}
+
+// Handle 在路由组中注册路由并记录接口文档，op.Path 为相对路由组的路径
+func (s *Spec) Handle(g *gin.RouterGroup, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(op.Method, op.Path, handlers...)
+	op.Path = path.Join(g.BasePath(), op.Path)
+	s.operations = append(s.operations, op)
+}
+
+// Handler 返回OpenAPI文档的接口
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.Document())
+	}
+}
+
+// Document 生成OpenAPI文档，只生成一次
+func (s *Spec) Document() map[string]interface{} {
+	s.once.Do(func() {
+		s.document = s.build()
+	})
+	return s.document
+}
+
+func (s *Spec) build() map[string]interface{} {
+	components := newComponents()
+	paths := make(map[string]interface{})
+	for _, op := range s.operations {
+		openapiPath := ginParamRegex.ReplaceAllString(op.Path, "{$1}")
+		item, ok := paths[openapiPath].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[openapiPath] = item
+		}
+		item[strings.ToLower(op.Method)] = components.operation(op)
+	}
+
+	securitySchemes := make(map[string]interface{}, len(s.securitySchemes))
+	for name, scheme := range s.securitySchemes {
+		securitySchemes[name] = map[string]interface{}{
+			"type":        "apiKey",
+			"in":          "header",
+			"name":        scheme.Header,
+			"description": scheme.Description,
+		}
+	}
+	document := map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":   s.title,
+			"version": s.version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         components.schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+	if len(s.optional) > 0 {
+		// 空的认证要求表示不带认证也可以访问
+		security := []interface{}{map[string]interface{}{}}
+		for _, name := range s.optional {
+			security = append(security, map[string]interface{}{name: []string{}})
+		}
+		document["security"] = security
+	}
+	return document
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPageRequest struct {
+	Limit int `form:"limit,default=20" validate:"gte=1,lte=100" label:"limit" desc:"返回条数"`
+}
+
+type testUserRequest struct {
+	Address string `uri:"address" validate:"required,eth_addr" label:"address" desc:"用户地址"`
+	Format  string `form:"format" validate:"omitempty,oneof=json csv" label:"format"`
+	testPageRequest
+}
+
+type testCreateRequest struct {
+	Name  string  `json:"name" validate:"required,max=64" desc:"名称"`
+	Rate  float64 `json:"rate" validate:"gte=0"`
+	Owner string  `json:"-"`
+}
+
+type testNode struct {
+	Value    string      `json:"value" example:"1"`
+	Children []*testNode `json:"children"`
+}
+
+func testSpec() map[string]interface{} {
+	gin.SetMode(gin.TestMode)
+	spec := New("test", "1.0")
+	spec.AddSecurityScheme("session", SecurityScheme{Header: "session_id"})
+	spec.AddOptionalSecurity("api_key")
+	group := gin.New().Group("/api/v1")
+	noop := func(c *gin.Context) {}
+	spec.Handle(group, Operation{Method: http.MethodGet, Path: "/user/:address", Summary: "user", Tags: []string{"user"}, Security: []string{"session"}, Request: testUserRequest{}, Response: testNode{}}, noop)
+	spec.Handle(group, Operation{Method: http.MethodPost, Path: "/user/:address", Summary: "create", Request: &testCreateRequest{}}, noop)
+	return spec.Document()
+}
+
+func TestDocumentPaths(t *testing.T) {
+	doc := testSpec()
+	assert.Equal(t, Version, doc["openapi"])
+	paths := doc["paths"].(map[string]interface{})
+	item, ok := paths["/api/v1/user/{address}"].(map[string]interface{})
+	assert.True(t, ok, "gin path params converted")
+	assert.Contains(t, item, "get")
+	assert.Contains(t, item, "post")
+
+	get := item["get"].(map[string]interface{})
+	assert.Equal(t, []string{"user"}, get["tags"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"session": []string{}}}, get["security"])
+	assert.NotContains(t, get, "requestBody")
+
+	params := map[string]map[string]interface{}{}
+	for _, p := range get["parameters"].([]interface{}) {
+		param := p.(map[string]interface{})
+		params[param["name"].(string)] = param
+	}
+	assert.Equal(t, "path", params["address"]["in"])
+	assert.Equal(t, true, params["address"]["required"])
+	assert.Equal(t, ethAddressPattern, params["address"]["schema"].(map[string]interface{})["pattern"])
+	assert.Equal(t, "query", params["format"]["in"])
+	assert.Equal(t, false, params["format"]["required"])
+	assert.Equal(t, []interface{}{"json", "csv"}, params["format"]["schema"].(map[string]interface{})["enum"])
+
+	limit := params["limit"]["schema"].(map[string]interface{})
+	assert.Equal(t, int64(20), limit["default"], "embedded struct flattened with typed default")
+	assert.Equal(t, int64(1), limit["minimum"])
+	assert.Equal(t, int64(100), limit["maximum"])
+}
+
+func TestDocumentRequestBody(t *testing.T) {
+	doc := testSpec()
+	post := doc["paths"].(map[string]interface{})["/api/v1/user/{address}"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.NotContains(t, post, "parameters")
+	body := post["requestBody"].(map[string]interface{})
+	ref := body["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/testCreateRequest", ref["$ref"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	create := schemas["testCreateRequest"].(map[string]interface{})
+	props := create["properties"].(map[string]interface{})
+	assert.Equal(t, []string{"name"}, create["required"])
+	assert.Equal(t, 64, props["name"].(map[string]interface{})["maxLength"])
+	assert.Equal(t, float64(0), props["rate"].(map[string]interface{})["minimum"])
+	assert.NotContains(t, props, "Owner", "json:\"-\" skipped")
+	assert.NotContains(t, props, "-")
+}
+
+func TestDocumentResponseAndSecurity(t *testing.T) {
+	doc := testSpec()
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	// 自引用的结构体只生成一次
+	node := schemas["testNode"].(map[string]interface{})
+	children := node["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/testNode", children["items"].(map[string]interface{})["$ref"])
+	assert.Equal(t, "1", node["properties"].(map[string]interface{})["value"].(map[string]interface{})["example"])
+	assert.Contains(t, schemas, "Response")
+
+	schemes := doc["components"].(map[string]interface{})["securitySchemes"].(map[string]interface{})
+	assert.Equal(t, "session_id", schemes["session"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{map[string]interface{}{}, map[string]interface{}{"api_key": []string{}}}, doc["security"])
+}
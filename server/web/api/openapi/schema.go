@@ -0,0 +1,303 @@
+package openapi
+
+import (
+	"aave_web/xhttp"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ethAddressPattern eth_addr 校验规则对应的正则
+const ethAddressPattern = "^0x[0-9a-fA-F]{40}$"
+
+// components 生成文档时收集的具名结构体
+type components struct {
+	schemas map[string]interface{}
+}
+
+func newComponents() *components {
+	return &components{schemas: make(map[string]interface{})}
+}
+
+// operation 生成一个接口的文档
+func (c *components) operation(op Operation) map[string]interface{} {
+	doc := map[string]interface{}{
+		"summary": op.Summary,
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "成功，code为200",
+				"content":     jsonContent(c.envelope(op.Response)),
+			},
+			"default": map[string]interface{}{
+				"description": "失败，code为业务错误码，如10002参数错误、10006请求过于频繁",
+				"content":     jsonContent(c.schema(reflect.TypeOf(xhttp.Response{}))),
+			},
+		},
+	}
+	if len(op.Tags) > 0 {
+		doc["tags"] = op.Tags
+	}
+	if len(op.Security) > 0 {
+		var security []interface{}
+		for _, name := range op.Security {
+			security = append(security, map[string]interface{}{name: []string{}})
+		}
+		doc["security"] = security
+	}
+	if op.Request == nil {
+		return doc
+	}
+	t := indirect(reflect.TypeOf(op.Request))
+	var parameters []interface{}
+	hasBody := false
+	eachField(t, func(field reflect.StructField) {
+		if name := tagName(field.Tag.Get("uri")); name != "" {
+			parameters = append(parameters, c.parameter(field, name, "path"))
+		} else if name := tagName(field.Tag.Get("form")); name != "" {
+			parameters = append(parameters, c.parameter(field, name, "query"))
+		} else if tagName(field.Tag.Get("json")) != "" {
+			hasBody = true
+		}
+	})
+	if len(parameters) > 0 {
+		doc["parameters"] = parameters
+	}
+	if hasBody {
+		doc["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(c.schema(t)),
+		}
+	}
+	return doc
+}
+
+// envelope xhttp.Response 响应结构，data 字段替换为实际的数据类型
+func (c *components) envelope(data interface{}) map[string]interface{} {
+	response := c.schema(reflect.TypeOf(xhttp.Response{}))
+	if data == nil {
+		return response
+	}
+	return map[string]interface{}{
+		"allOf": []interface{}{
+			response,
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"data": c.schema(reflect.TypeOf(data)),
+				},
+			},
+		},
+	}
+}
+
+// parameter 路径参数或查询参数
+func (c *components) parameter(field reflect.StructField, name, in string) map[string]interface{} {
+	schema := c.fieldSchema(field)
+	parameter := map[string]interface{}{
+		"name":     name,
+		"in":       in,
+		"required": in == "path" || hasRule(field, "required"),
+		"schema":   schema,
+	}
+	if desc := field.Tag.Get("desc"); desc != "" {
+		parameter["description"] = desc
+	}
+	if value, ok := tagOption(field.Tag.Get("form"), "default"); ok {
+		schema["default"] = typedValue(indirect(field.Type), value)
+	}
+	return parameter
+}
+
+// schema 类型对应的schema，具名结构体放入components并返回引用
+func (c *components) schema(t reflect.Type) map[string]interface{} {
+	t = indirect(t)
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": c.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": c.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.structSchema(t)
+		}
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := c.schemas[t.Name()]; !ok {
+			// 先占位，避免结构体自引用时无限递归
+			c.schemas[t.Name()] = nil
+			c.schemas[t.Name()] = c.structSchema(t)
+		}
+		return ref
+	default:
+		// interface{} 等任意类型
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema 按json标签生成结构体的属性，只有uri、form标签的请求参数字段不属于请求体
+func (c *components) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	eachField(t, func(field reflect.StructField) {
+		jsonTag := field.Tag.Get("json")
+		name := tagName(jsonTag)
+		if jsonTag == "" && (field.Tag.Get("uri") != "" || field.Tag.Get("form") != "") {
+			return
+		}
+		if name == "-" {
+			return
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := c.fieldSchema(field)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			schema["description"] = desc
+		}
+		if example := field.Tag.Get("example"); example != "" {
+			schema["example"] = typedValue(indirect(field.Type), example)
+		}
+		properties[name] = schema
+		if hasRule(field, "required") {
+			required = append(required, name)
+		}
+	})
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldSchema 字段类型对应的schema，加上validate标签中的约束
+// 引用类型的schema不能附加约束，只有基础类型才处理
+func (c *components) fieldSchema(field reflect.StructField) map[string]interface{} {
+	schema := c.schema(field.Type)
+	if _, ok := schema["$ref"]; ok {
+		return schema
+	}
+	t := indirect(field.Type)
+	numeric := schema["type"] == "integer" || schema["type"] == "number"
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch {
+		case key == "eth_addr":
+			schema["pattern"] = ethAddressPattern
+		case key == "oneof":
+			var enum []interface{}
+			for _, item := range strings.Fields(value) {
+				enum = append(enum, typedValue(t, item))
+			}
+			schema["enum"] = enum
+		case numeric && (key == "min" || key == "gte" || key == "gt"):
+			schema["minimum"] = typedValue(t, value)
+			if key == "gt" {
+				schema["exclusiveMinimum"] = true
+			}
+		case numeric && (key == "max" || key == "lte" || key == "lt"):
+			schema["maximum"] = typedValue(t, value)
+			if key == "lt" {
+				schema["exclusiveMaximum"] = true
+			}
+		case t.Kind() == reflect.String && (key == "min" || key == "gte"):
+			schema["minLength"], _ = strconv.Atoi(value)
+		case t.Kind() == reflect.String && (key == "max" || key == "lte"):
+			schema["maxLength"], _ = strconv.Atoi(value)
+		case t.Kind() == reflect.String && key == "len":
+			schema["minLength"], _ = strconv.Atoi(value)
+			schema["maxLength"] = schema["minLength"]
+		}
+	}
+	return schema
+}
+
+// eachField 遍历结构体的可导出字段，没有标签名的匿名字段展开
+func eachField(t reflect.Type, fn func(field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && tagName(field.Tag.Get("json")) == "" && indirect(field.Type).Kind() == reflect.Struct {
+			eachField(indirect(field.Type), fn)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fn(field)
+	}
+}
+
+// jsonContent application/json 内容
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// hasRule validate标签是否包含某个规则
+func hasRule(field reflect.StructField, name string) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName 标签中的名称部分
+func tagName(tag string) string {
+	return strings.SplitN(tag, ",", 2)[0]
+}
+
+// tagOption 标签中 key=value 形式的选项，如 form:"limit,default=20"
+func tagOption(tag, key string) (string, bool) {
+	for _, option := range strings.Split(tag, ",")[1:] {
+		if k, v, ok := strings.Cut(option, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// typedValue 按字段类型转换标签中的值，转换失败时保留字符串
+func typedValue(t reflect.Type, value string) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"aave_web/api/middleware"
+	v1 "aave_web/api/v1"
 	"aave_web/service"
 	"time"
 
@@ -9,7 +10,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(svcCtx *service.ServerCtx) *gin.Engine {
+// NewRouter 创建路由，GraphQL schema构建失败时返回错误
+func NewRouter(svcCtx *service.ServerCtx) (*gin.Engine, error) {
 	gin.ForceConsoleColor()
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()                        // 新建一个gin引擎实例
@@ -18,11 +20,17 @@ func NewRouter(svcCtx *service.ServerCtx) *gin.Engine {
 	r.Use(cors.New(cors.Config{           // 使用cors中间件
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-CSRF-Token", "Authorization", "AccessToken", "Token"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "X-GW-Error-Code", "X-GW-Error-Message"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-CSRF-Token", "Authorization", "AccessToken", "Token", middleware.ApiKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "X-GW-Error-Code", "X-GW-Error-Message", "X-RateLimit-Limit", "X-RateLimit-Remaining", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           1 * time.Hour,
 	}))
-	loadV1(r, svcCtx) // 加载v1路由
-	return r
+	// 探针不经过限流和缓存
+	r.GET("/healthz", v1.HealthzHandler(svcCtx)) // 存活检查
+	r.GET("/readyz", v1.ReadyzHandler(svcCtx))   // 就绪检查
+	// 加载v1路由
+	if err := loadV1(r, svcCtx); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
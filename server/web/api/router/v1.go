@@ -1,33 +1,164 @@
 package router
 
 import (
+	"aave_web/api/graphql"
+	"aave_web/api/middleware"
+	"aave_web/api/openapi"
 	v1 "aave_web/api/v1"
 	"aave_web/service"
 	v2 "aave_web/service/v1"
+	types "aave_web/types/v1"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
-func loadV1(r *gin.Engine, svcCtx *service.ServerCtx) {
+// 接口文档中的认证方式
+const (
+	securityApiKey  = "ApiKey"
+	securitySession = "Session"
+	securityAdmin   = "AdminToken"
+)
+
+func loadV1(r *gin.Engine, svcCtx *service.ServerCtx) error {
 	apiV1 := r.Group("/api/v1")
+	if svcCtx.C.RateLimit.Enable {
+		apiV1.Use(middleware.RateLimit(svcCtx.KvStore, svcCtx.C.RateLimit, v2.NewApiKeyStore(svcCtx)))
+	}
+
+	// 接口文档，注册路由时同时记录接口的参数和响应
+	doc := openapi.New("Aave API", "1.0.0")
+	doc.AddSecurityScheme(securityApiKey, openapi.SecurityScheme{Header: middleware.ApiKeyHeader, Description: "API key，使用独立的限流配额"})
+	doc.AddSecurityScheme(securitySession, openapi.SecurityScheme{Header: "session_id", Description: "登录后获得的会话"})
+	doc.AddSecurityScheme(securityAdmin, openapi.SecurityScheme{Header: middleware.AdminHeader, Description: "管理令牌"})
+	if svcCtx.C.RateLimit.Enable {
+		doc.AddOptionalSecurity(securityApiKey)
+	}
+
+	doc.Handle(apiV1.Group(""), openapi.Operation{Method: http.MethodGet, Path: "/status", Summary: "获取事件同步状态", Tags: []string{"status"}, Response: types.Status{}},
+		v1.GetStatusHandler(svcCtx))
 
 	lend := apiV1.Group("/lend")
 	{
-		lend.GET("/detail", v1.GetLendDataHandler(svcCtx)) // 获取lend详情
+		doc.Handle(lend, openapi.Operation{Method: http.MethodGet, Path: "/detail", Summary: "获取lend详情", Tags: []string{"lend"}, Response: types.Lend{}},
+			apiCache(svcCtx, middleware.StaticTags(middleware.CacheTagLend)), v1.GetLendDataHandler(svcCtx))
 	}
 
 	borrow := apiV1.Group("/borrow")
 	{
-		borrow.GET("/detail", v1.GetBorrowDataHandler(svcCtx)) // 获取borrow详情
+		doc.Handle(borrow, openapi.Operation{Method: http.MethodGet, Path: "/detail", Summary: "获取borrow详情", Tags: []string{"borrow"}, Request: v1.BorrowDetailRequest{}, Response: types.Collateral{}},
+			apiCache(svcCtx, middleware.QueryTag(middleware.CacheTagCollateralPrefix, "collateralAsset")), v1.GetBorrowDataHandler(svcCtx))
 	}
 
+	rates := apiV1.Group("/rates")
+	{
+		doc.Handle(rates, openapi.Operation{Method: http.MethodGet, Path: "/realized", Summary: "获取区间实际年化", Tags: []string{"rates"}, Request: v1.RealizedRatesRequest{}, Response: types.RealizedRates{}},
+			v1.GetRealizedRatesHandler(svcCtx))
+	}
+
+	liquidations := apiV1.Group("/liquidations")
+	{
+		doc.Handle(liquidations, openapi.Operation{Method: http.MethodGet, Path: "", Summary: "获取全协议清算记录", Tags: []string{"liquidations"}, Request: v1.LiquidationPageRequest{}, Response: types.LiquidationPage{}},
+			v1.GetLiquidationsHandler(svcCtx))
+		doc.Handle(liquidations, openapi.Operation{Method: http.MethodGet, Path: "/liquidators", Summary: "获取清算人排行", Tags: []string{"liquidations"}, Request: v1.LiquidationFilterRequest{}, Response: []*types.LiquidationStats{}},
+			v1.GetLiquidatorLeaderboardHandler(svcCtx))
+		doc.Handle(liquidations, openapi.Operation{Method: http.MethodGet, Path: "/collaterals", Summary: "获取各抵押代币清算统计", Tags: []string{"liquidations"}, Request: v1.LiquidationFilterRequest{}, Response: []*types.LiquidationStats{}},
+			v1.GetCollateralLiquidationStatsHandler(svcCtx))
+	}
+
+	params := apiV1.Group("/params")
+	{
+		doc.Handle(params, openapi.Operation{Method: http.MethodGet, Path: "", Summary: "获取风险参数最新版本", Tags: []string{"params"}, Response: types.PoolParams{}},
+			v1.GetPoolParamsHandler(svcCtx))
+		doc.Handle(params, openapi.Operation{Method: http.MethodGet, Path: "/history", Summary: "获取风险参数历史版本", Tags: []string{"params"}, Request: v1.PoolParamHistoryRequest{}, Response: types.PoolParamPage{}},
+			v1.GetPoolParamHistoryHandler(svcCtx))
+	}
+
+	transactions := apiV1.Group("/transactions")
+	{
+		doc.Handle(transactions, openapi.Operation{Method: http.MethodGet, Path: "/stats", Summary: "按方法统计交易数量、失败率和gas消耗", Tags: []string{"transactions"}, Request: v1.PoolTransactionFilterRequest{}, Response: []*types.PoolTransactionStats{}},
+			v1.GetPoolTransactionStatsHandler(svcCtx))
+		doc.Handle(transactions, openapi.Operation{Method: http.MethodGet, Path: "/failed", Summary: "获取失败的pool合约交易", Tags: []string{"transactions"}, Request: v1.PoolTransactionPageRequest{}, Response: types.PoolTransactionPage{}},
+			v1.GetFailedPoolTransactionsHandler(svcCtx))
+	}
+
+	chainState := apiV1.Group("/chain")
+	{
+		doc.Handle(chainState, openapi.Operation{Method: http.MethodGet, Path: "/lend", Summary: "链上读取全局存借款信息", Tags: []string{"chain"}, Request: v1.ChainBlockRequest{}, Response: types.ChainLendInfo{}},
+			v1.GetChainLendInfoHandler(svcCtx))
+		doc.Handle(chainState, openapi.Operation{Method: http.MethodGet, Path: "/borrow/:token", Summary: "链上读取抵押代币借款信息", Tags: []string{"chain"}, Request: v1.ChainTokenRequest{}, Response: types.ChainTokenBorrowInfo{}},
+			v1.GetChainTokenBorrowInfoHandler(svcCtx))
+		doc.Handle(chainState, openapi.Operation{Method: http.MethodGet, Path: "/user/:address/lend", Summary: "链上读取用户存款本息", Tags: []string{"chain"}, Request: v1.ChainUserRequest{}, Response: types.ChainUserLend{}},
+			v1.GetChainUserLendHandler(svcCtx))
+		doc.Handle(chainState, openapi.Operation{Method: http.MethodGet, Path: "/user/:address/borrow/:token", Summary: "链上读取用户借款本息", Tags: []string{"chain"}, Request: v1.ChainUserTokenRequest{}, Response: types.ChainUserBorrow{}},
+			v1.GetChainUserBorrowHandler(svcCtx))
+		doc.Handle(chainState, openapi.Operation{Method: http.MethodGet, Path: "/user/:address/health/:token", Summary: "链上读取用户健康因子", Tags: []string{"chain"}, Request: v1.ChainUserTokenRequest{}, Response: types.ChainUserHealth{}},
+			v1.GetChainUserHealthHandler(svcCtx))
+	}
+
+	user := apiV1.Group("/user")
+	user.Use(middleware.AuthMiddleWare(svcCtx.KvStore))
+	{
+		// 收益按最新利率指数计算，存款池状态变化时也需要清除；用户接口都先校验地址属于当前登录用户再读缓存
+		doc.Handle(user, openapi.Operation{Method: http.MethodGet, Path: "/:address/earnings", Summary: "获取用户存款收益报表", Tags: []string{"user"}, Security: []string{securitySession}, Request: v1.UserEarningsRequest{}, Response: types.UserEarnings{}},
+			v1.UserOwnerRequired(svcCtx), apiCache(svcCtx, middleware.ParamTag(middleware.CacheTagUserPrefix, "address"), middleware.StaticTags(middleware.CacheTagLend)), v1.GetUserEarningsHandler(svcCtx))
+		doc.Handle(user, openapi.Operation{Method: http.MethodGet, Path: "/:address/activity", Summary: "获取用户操作记录", Tags: []string{"user"}, Security: []string{securitySession}, Request: v1.UserActivityRequest{}, Response: types.ActivityPage{}},
+			v1.UserOwnerRequired(svcCtx), apiCache(svcCtx, middleware.ParamTag(middleware.CacheTagUserPrefix, "address")), v1.GetUserActivityHandler(svcCtx))
+	}
+
+	// 管理接口，未配置管理令牌时不开放
+	if svcCtx.C.Api.AdminToken != "" {
+		admin := apiV1.Group("/admin")
+		admin.Use(middleware.AdminAuth(svcCtx.C.Api.AdminToken))
+		{
+			doc.Handle(admin, openapi.Operation{Method: http.MethodPost, Path: "/api-keys", Summary: "新建API key", Tags: []string{"admin"}, Security: []string{securityAdmin}, Request: v1.CreateApiKeyRequest{}, Response: types.ApiKeyCreated{}},
+				v1.CreateApiKeyHandler(svcCtx))
+			doc.Handle(admin, openapi.Operation{Method: http.MethodGet, Path: "/api-keys", Summary: "获取所有API key", Tags: []string{"admin"}, Security: []string{securityAdmin}, Response: []*types.ApiKey{}},
+				v1.ListApiKeysHandler(svcCtx))
+			doc.Handle(admin, openapi.Operation{Method: http.MethodPost, Path: "/api-keys/:id/revoke", Summary: "吊销API key", Tags: []string{"admin"}, Security: []string{securityAdmin}, Request: v1.ApiKeyRequest{}},
+				v1.RevokeApiKeyHandler(svcCtx))
+			doc.Handle(admin, openapi.Operation{Method: http.MethodGet, Path: "/api-keys/:id/usage", Summary: "获取API key用量", Tags: []string{"admin"}, Security: []string{securityAdmin}, Request: v1.ApiKeyUsageRequest{}, Response: []*types.ApiKeyUsage{}},
+				v1.GetApiKeyUsageHandler(svcCtx))
+		}
+	}
+
+	// 接口文档
+	apiV1.GET("/openapi.json", doc.Handler())
+
+	// GraphQL有自己的schema，不记录到接口文档，订阅通过WebSocket的graphql_subscribe消息发起
+	gqlHandler, err := graphql.NewHandler(svcCtx)
+	if err != nil {
+		return err
+	}
+	apiV1.GET("/graphql", gqlHandler.Serve())
+	apiV1.POST("/graphql", gqlHandler.Serve())
+
+	// websocket，SSE与其共用同一个广播和主题
+	wsServer := v2.NewWSServer(svcCtx)
+	wsServer.SetSubscriber(gqlHandler)
+	// 与HTTP接口相同的session_id校验，认证后可以订阅自己的 user:<地址> 主题
+	wsServer.SetAuthenticator(func(sessionID string) ([]string, error) {
+		return middleware.GetSessionAddresses(sessionID, svcCtx.KvStore)
+	})
+	// 创建推送广播
+	v2.PushEvent(wsServer, svcCtx)
+
 	// 添加WebSocket路由
 	ws := apiV1.Group("/ws")
 	{
-		// websocket
-		wsServer := v2.NewWSServer(svcCtx)
-		// 创建推送广播
-		v2.PushEvent(wsServer, svcCtx)
 		ws.GET("", v2.HandleWebSocket(wsServer))
 	}
+	// 代理不支持WebSocket时使用SSE
+	apiV1.GET("/stream", v2.HandleStream(wsServer))
+	return nil
+}
 
+// apiCache 按配置的缓存时间缓存接口响应，并打上标签供状态变化时清除，缓存时间为0时不缓存
+func apiCache(svcCtx *service.ServerCtx, tagFuncs ...middleware.CacheTagFunc) gin.HandlerFunc {
+	if svcCtx.C.Api.CacheSeconds <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return middleware.CacheApi(svcCtx.KvStore, svcCtx.C.Api.CacheSeconds, tagFuncs...)
 }
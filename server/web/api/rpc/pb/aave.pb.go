@@ -0,0 +1,790 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.27.1
+// source: aave.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetLendDetailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLendDetailRequest) Reset() {
+	*x = GetLendDetailRequest{}
+	mi := &file_aave_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLendDetailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLendDetailRequest) ProtoMessage() {}
+
+func (x *GetLendDetailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLendDetailRequest.ProtoReflect.Descriptor instead.
+func (*GetLendDetailRequest) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{0}
+}
+
+type LendDetail struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            int32                  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	TotalBorrow     string                 `protobuf:"bytes,2,opt,name=total_borrow,json=totalBorrow,proto3" json:"total_borrow,omitempty"`
+	TotalDeposits   string                 `protobuf:"bytes,3,opt,name=total_deposits,json=totalDeposits,proto3" json:"total_deposits,omitempty"`
+	UtilizationRate int32                  `protobuf:"varint,4,opt,name=utilization_rate,json=utilizationRate,proto3" json:"utilization_rate,omitempty"`
+	InterestRate    int32                  `protobuf:"varint,5,opt,name=interest_rate,json=interestRate,proto3" json:"interest_rate,omitempty"`
+	BlockNumber     int64                  `protobuf:"varint,6,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxHash          string                 `protobuf:"bytes,7,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	LogIndex        int32                  `protobuf:"varint,8,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`
+	UpdateTime      int64                  `protobuf:"varint,9,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LendDetail) Reset() {
+	*x = LendDetail{}
+	mi := &file_aave_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LendDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LendDetail) ProtoMessage() {}
+
+func (x *LendDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LendDetail.ProtoReflect.Descriptor instead.
+func (*LendDetail) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LendDetail) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *LendDetail) GetTotalBorrow() string {
+	if x != nil {
+		return x.TotalBorrow
+	}
+	return ""
+}
+
+func (x *LendDetail) GetTotalDeposits() string {
+	if x != nil {
+		return x.TotalDeposits
+	}
+	return ""
+}
+
+func (x *LendDetail) GetUtilizationRate() int32 {
+	if x != nil {
+		return x.UtilizationRate
+	}
+	return 0
+}
+
+func (x *LendDetail) GetInterestRate() int32 {
+	if x != nil {
+		return x.InterestRate
+	}
+	return 0
+}
+
+func (x *LendDetail) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *LendDetail) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *LendDetail) GetLogIndex() int32 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *LendDetail) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+type GetBorrowDetailRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CollateralAsset int32                  `protobuf:"varint,1,opt,name=collateral_asset,json=collateralAsset,proto3" json:"collateral_asset,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetBorrowDetailRequest) Reset() {
+	*x = GetBorrowDetailRequest{}
+	mi := &file_aave_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBorrowDetailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBorrowDetailRequest) ProtoMessage() {}
+
+func (x *GetBorrowDetailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBorrowDetailRequest.ProtoReflect.Descriptor instead.
+func (*GetBorrowDetailRequest) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBorrowDetailRequest) GetCollateralAsset() int32 {
+	if x != nil {
+		return x.CollateralAsset
+	}
+	return 0
+}
+
+type BorrowDetail struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress          string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Type                  int32                  `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Borrowed              string                 `protobuf:"bytes,3,opt,name=borrowed,proto3" json:"borrowed,omitempty"`
+	Borrowable            string                 `protobuf:"bytes,4,opt,name=borrowable,proto3" json:"borrowable,omitempty"`
+	UtilizationRate       int32                  `protobuf:"varint,5,opt,name=utilization_rate,json=utilizationRate,proto3" json:"utilization_rate,omitempty"`
+	InterestRate          int32                  `protobuf:"varint,6,opt,name=interest_rate,json=interestRate,proto3" json:"interest_rate,omitempty"`
+	HealthFactor          int32                  `protobuf:"varint,7,opt,name=health_factor,json=healthFactor,proto3" json:"health_factor,omitempty"`
+	LiquidationThreshold  int32                  `protobuf:"varint,8,opt,name=liquidation_threshold,json=liquidationThreshold,proto3" json:"liquidation_threshold,omitempty"`
+	CollateralizationRate int32                  `protobuf:"varint,9,opt,name=collateralization_rate,json=collateralizationRate,proto3" json:"collateralization_rate,omitempty"`
+	BlockNumber           int64                  `protobuf:"varint,10,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxHash                string                 `protobuf:"bytes,11,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	LogIndex              int32                  `protobuf:"varint,12,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`
+	UpdateTime            int64                  `protobuf:"varint,13,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *BorrowDetail) Reset() {
+	*x = BorrowDetail{}
+	mi := &file_aave_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BorrowDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BorrowDetail) ProtoMessage() {}
+
+func (x *BorrowDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BorrowDetail.ProtoReflect.Descriptor instead.
+func (*BorrowDetail) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BorrowDetail) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *BorrowDetail) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetBorrowed() string {
+	if x != nil {
+		return x.Borrowed
+	}
+	return ""
+}
+
+func (x *BorrowDetail) GetBorrowable() string {
+	if x != nil {
+		return x.Borrowable
+	}
+	return ""
+}
+
+func (x *BorrowDetail) GetUtilizationRate() int32 {
+	if x != nil {
+		return x.UtilizationRate
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetInterestRate() int32 {
+	if x != nil {
+		return x.InterestRate
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetHealthFactor() int32 {
+	if x != nil {
+		return x.HealthFactor
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetLiquidationThreshold() int32 {
+	if x != nil {
+		return x.LiquidationThreshold
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetCollateralizationRate() int32 {
+	if x != nil {
+		return x.CollateralizationRate
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *BorrowDetail) GetLogIndex() int32 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *BorrowDetail) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+type GetUserPositionRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	User             string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	CollateralTokens []string               `protobuf:"bytes,2,rep,name=collateral_tokens,json=collateralTokens,proto3" json:"collateral_tokens,omitempty"`
+	Block            int64                  `protobuf:"varint,3,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetUserPositionRequest) Reset() {
+	*x = GetUserPositionRequest{}
+	mi := &file_aave_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserPositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserPositionRequest) ProtoMessage() {}
+
+func (x *GetUserPositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserPositionRequest.ProtoReflect.Descriptor instead.
+func (*GetUserPositionRequest) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserPositionRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GetUserPositionRequest) GetCollateralTokens() []string {
+	if x != nil {
+		return x.CollateralTokens
+	}
+	return nil
+}
+
+func (x *GetUserPositionRequest) GetBlock() int64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type UserPosition struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Block                 int64                  `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	User                  string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	LendTotalWithInterest string                 `protobuf:"bytes,3,opt,name=lend_total_with_interest,json=lendTotalWithInterest,proto3" json:"lend_total_with_interest,omitempty"`
+	Borrows               []*BorrowPosition      `protobuf:"bytes,4,rep,name=borrows,proto3" json:"borrows,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *UserPosition) Reset() {
+	*x = UserPosition{}
+	mi := &file_aave_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserPosition) ProtoMessage() {}
+
+func (x *UserPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserPosition.ProtoReflect.Descriptor instead.
+func (*UserPosition) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserPosition) GetBlock() int64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *UserPosition) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *UserPosition) GetLendTotalWithInterest() string {
+	if x != nil {
+		return x.LendTotalWithInterest
+	}
+	return ""
+}
+
+func (x *UserPosition) GetBorrows() []*BorrowPosition {
+	if x != nil {
+		return x.Borrows
+	}
+	return nil
+}
+
+type BorrowPosition struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	CollateralToken      string                 `protobuf:"bytes,1,opt,name=collateral_token,json=collateralToken,proto3" json:"collateral_token,omitempty"`
+	TotalWithInterest    string                 `protobuf:"bytes,2,opt,name=total_with_interest,json=totalWithInterest,proto3" json:"total_with_interest,omitempty"`
+	HealthFactor         string                 `protobuf:"bytes,3,opt,name=health_factor,json=healthFactor,proto3" json:"health_factor,omitempty"`
+	TotalCollateralValue string                 `protobuf:"bytes,4,opt,name=total_collateral_value,json=totalCollateralValue,proto3" json:"total_collateral_value,omitempty"`
+	TotalDebtValue       string                 `protobuf:"bytes,5,opt,name=total_debt_value,json=totalDebtValue,proto3" json:"total_debt_value,omitempty"`
+	IsLiquidatable       bool                   `protobuf:"varint,6,opt,name=is_liquidatable,json=isLiquidatable,proto3" json:"is_liquidatable,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *BorrowPosition) Reset() {
+	*x = BorrowPosition{}
+	mi := &file_aave_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BorrowPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BorrowPosition) ProtoMessage() {}
+
+func (x *BorrowPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BorrowPosition.ProtoReflect.Descriptor instead.
+func (*BorrowPosition) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BorrowPosition) GetCollateralToken() string {
+	if x != nil {
+		return x.CollateralToken
+	}
+	return ""
+}
+
+func (x *BorrowPosition) GetTotalWithInterest() string {
+	if x != nil {
+		return x.TotalWithInterest
+	}
+	return ""
+}
+
+func (x *BorrowPosition) GetHealthFactor() string {
+	if x != nil {
+		return x.HealthFactor
+	}
+	return ""
+}
+
+func (x *BorrowPosition) GetTotalCollateralValue() string {
+	if x != nil {
+		return x.TotalCollateralValue
+	}
+	return ""
+}
+
+func (x *BorrowPosition) GetTotalDebtValue() string {
+	if x != nil {
+		return x.TotalDebtValue
+	}
+	return ""
+}
+
+func (x *BorrowPosition) GetIsLiquidatable() bool {
+	if x != nil {
+		return x.IsLiquidatable
+	}
+	return false
+}
+
+type SubscribeStateChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeStateChangesRequest) Reset() {
+	*x = SubscribeStateChangesRequest{}
+	mi := &file_aave_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeStateChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeStateChangesRequest) ProtoMessage() {}
+
+func (x *SubscribeStateChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeStateChangesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeStateChangesRequest) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{7}
+}
+
+type StateChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Lend          *LendDetail            `protobuf:"bytes,2,opt,name=lend,proto3" json:"lend,omitempty"`
+	Time          int64                  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateChange) Reset() {
+	*x = StateChange{}
+	mi := &file_aave_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateChange) ProtoMessage() {}
+
+func (x *StateChange) ProtoReflect() protoreflect.Message {
+	mi := &file_aave_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateChange.ProtoReflect.Descriptor instead.
+func (*StateChange) Descriptor() ([]byte, []int) {
+	return file_aave_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StateChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *StateChange) GetLend() *LendDetail {
+	if x != nil {
+		return x.Lend
+	}
+	return nil
+}
+
+func (x *StateChange) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+var File_aave_proto protoreflect.FileDescriptor
+
+const file_aave_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"aave.proto\x12\aaave.v1\"\x16\n" +
+	"\x14GetLendDetailRequest\"\xb4\x02\n" +
+	"\n" +
+	"LendDetail\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\x05R\x04type\x12!\n" +
+	"\ftotal_borrow\x18\x02 \x01(\tR\vtotalBorrow\x12%\n" +
+	"\x0etotal_deposits\x18\x03 \x01(\tR\rtotalDeposits\x12)\n" +
+	"\x10utilization_rate\x18\x04 \x01(\x05R\x0futilizationRate\x12#\n" +
+	"\rinterest_rate\x18\x05 \x01(\x05R\finterestRate\x12!\n" +
+	"\fblock_number\x18\x06 \x01(\x03R\vblockNumber\x12\x17\n" +
+	"\atx_hash\x18\a \x01(\tR\x06txHash\x12\x1b\n" +
+	"\tlog_index\x18\b \x01(\x05R\blogIndex\x12\x1f\n" +
+	"\vupdate_time\x18\t \x01(\x03R\n" +
+	"updateTime\"C\n" +
+	"\x16GetBorrowDetailRequest\x12)\n" +
+	"\x10collateral_asset\x18\x01 \x01(\x05R\x0fcollateralAsset\"\xde\x03\n" +
+	"\fBorrowDetail\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\x05R\x04type\x12\x1a\n" +
+	"\bborrowed\x18\x03 \x01(\tR\bborrowed\x12\x1e\n" +
+	"\n" +
+	"borrowable\x18\x04 \x01(\tR\n" +
+	"borrowable\x12)\n" +
+	"\x10utilization_rate\x18\x05 \x01(\x05R\x0futilizationRate\x12#\n" +
+	"\rinterest_rate\x18\x06 \x01(\x05R\finterestRate\x12#\n" +
+	"\rhealth_factor\x18\a \x01(\x05R\fhealthFactor\x123\n" +
+	"\x15liquidation_threshold\x18\b \x01(\x05R\x14liquidationThreshold\x125\n" +
+	"\x16collateralization_rate\x18\t \x01(\x05R\x15collateralizationRate\x12!\n" +
+	"\fblock_number\x18\n" +
+	" \x01(\x03R\vblockNumber\x12\x17\n" +
+	"\atx_hash\x18\v \x01(\tR\x06txHash\x12\x1b\n" +
+	"\tlog_index\x18\f \x01(\x05R\blogIndex\x12\x1f\n" +
+	"\vupdate_time\x18\r \x01(\x03R\n" +
+	"updateTime\"o\n" +
+	"\x16GetUserPositionRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12+\n" +
+	"\x11collateral_tokens\x18\x02 \x03(\tR\x10collateralTokens\x12\x14\n" +
+	"\x05block\x18\x03 \x01(\x03R\x05block\"\xa4\x01\n" +
+	"\fUserPosition\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x03R\x05block\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x127\n" +
+	"\x18lend_total_with_interest\x18\x03 \x01(\tR\x15lendTotalWithInterest\x121\n" +
+	"\aborrows\x18\x04 \x03(\v2\x17.aave.v1.BorrowPositionR\aborrows\"\x99\x02\n" +
+	"\x0eBorrowPosition\x12)\n" +
+	"\x10collateral_token\x18\x01 \x01(\tR\x0fcollateralToken\x12.\n" +
+	"\x13total_with_interest\x18\x02 \x01(\tR\x11totalWithInterest\x12#\n" +
+	"\rhealth_factor\x18\x03 \x01(\tR\fhealthFactor\x124\n" +
+	"\x16total_collateral_value\x18\x04 \x01(\tR\x14totalCollateralValue\x12(\n" +
+	"\x10total_debt_value\x18\x05 \x01(\tR\x0etotalDebtValue\x12'\n" +
+	"\x0fis_liquidatable\x18\x06 \x01(\bR\x0eisLiquidatable\"\x1e\n" +
+	"\x1cSubscribeStateChangesRequest\"^\n" +
+	"\vStateChange\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12'\n" +
+	"\x04lend\x18\x02 \x01(\v2\x13.aave.v1.LendDetailR\x04lend\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x03R\x04time2\xc0\x02\n" +
+	"\vAaveService\x12C\n" +
+	"\rGetLendDetail\x12\x1d.aave.v1.GetLendDetailRequest\x1a\x13.aave.v1.LendDetail\x12I\n" +
+	"\x0fGetBorrowDetail\x12\x1f.aave.v1.GetBorrowDetailRequest\x1a\x15.aave.v1.BorrowDetail\x12I\n" +
+	"\x0fGetUserPosition\x12\x1f.aave.v1.GetUserPositionRequest\x1a\x15.aave.v1.UserPosition\x12V\n" +
+	"\x15SubscribeStateChanges\x12%.aave.v1.SubscribeStateChangesRequest\x1a\x14.aave.v1.StateChange0\x01B\x18Z\x16aave_web/api/rpc/pb;pbb\x06proto3"
+
+var (
+	file_aave_proto_rawDescOnce sync.Once
+	file_aave_proto_rawDescData []byte
+)
+
+func file_aave_proto_rawDescGZIP() []byte {
+	file_aave_proto_rawDescOnce.Do(func() {
+		file_aave_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_aave_proto_rawDesc), len(file_aave_proto_rawDesc)))
+	})
+	return file_aave_proto_rawDescData
+}
+
+var file_aave_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_aave_proto_goTypes = []any{
+	(*GetLendDetailRequest)(nil),         // 0: aave.v1.GetLendDetailRequest
+	(*LendDetail)(nil),                   // 1: aave.v1.LendDetail
+	(*GetBorrowDetailRequest)(nil),       // 2: aave.v1.GetBorrowDetailRequest
+	(*BorrowDetail)(nil),                 // 3: aave.v1.BorrowDetail
+	(*GetUserPositionRequest)(nil),       // 4: aave.v1.GetUserPositionRequest
+	(*UserPosition)(nil),                 // 5: aave.v1.UserPosition
+	(*BorrowPosition)(nil),               // 6: aave.v1.BorrowPosition
+	(*SubscribeStateChangesRequest)(nil), // 7: aave.v1.SubscribeStateChangesRequest
+	(*StateChange)(nil),                  // 8: aave.v1.StateChange
+}
+var file_aave_proto_depIdxs = []int32{
+	6, // 0: aave.v1.UserPosition.borrows:type_name -> aave.v1.BorrowPosition
+	1, // 1: aave.v1.StateChange.lend:type_name -> aave.v1.LendDetail
+	0, // 2: aave.v1.AaveService.GetLendDetail:input_type -> aave.v1.GetLendDetailRequest
+	2, // 3: aave.v1.AaveService.GetBorrowDetail:input_type -> aave.v1.GetBorrowDetailRequest
+	4, // 4: aave.v1.AaveService.GetUserPosition:input_type -> aave.v1.GetUserPositionRequest
+	7, // 5: aave.v1.AaveService.SubscribeStateChanges:input_type -> aave.v1.SubscribeStateChangesRequest
+	1, // 6: aave.v1.AaveService.GetLendDetail:output_type -> aave.v1.LendDetail
+	3, // 7: aave.v1.AaveService.GetBorrowDetail:output_type -> aave.v1.BorrowDetail
+	5, // 8: aave.v1.AaveService.GetUserPosition:output_type -> aave.v1.UserPosition
+	8, // 9: aave.v1.AaveService.SubscribeStateChanges:output_type -> aave.v1.StateChange
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_aave_proto_init() }
+func file_aave_proto_init() {
+	if File_aave_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_aave_proto_rawDesc), len(file_aave_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_aave_proto_goTypes,
+		DependencyIndexes: file_aave_proto_depIdxs,
+		MessageInfos:      file_aave_proto_msgTypes,
+	}.Build()
+	File_aave_proto = out.File
+	file_aave_proto_goTypes = nil
+	file_aave_proto_depIdxs = nil
+}
@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"aave_web/api/rpc/pb"
+	"aave_web/chain"
+	"aave_web/errcode"
+	"aave_web/logger/xzap"
+	"aave_web/logger/xzap/xgrpc"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	types "aave_web/types/v1"
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// feedBuffer 每个状态变化订阅的缓冲区大小
+const feedBuffer = 16
+
+// Server AaveService的gRPC实现，与REST接口共用service层
+type Server struct {
+	pb.UnimplementedAaveServiceServer
+	svcCtx *service.ServerCtx
+}
+
+// NewGrpcServer 创建gRPC服务，使用xgrpc记录请求日志，errcode将业务错误转换为gRPC状态码
+func NewGrpcServer(svcCtx *service.ServerCtx) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			xgrpc.PayloadUnaryServerInterceptor(svcCtx.Logger),
+			errcode.ErrInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			xgrpc.PayloadStreamServerInterceptor(svcCtx.Logger),
+			errcode.ErrStreamInterceptor,
+		),
+	)
+	pb.RegisterAaveServiceServer(s, &Server{svcCtx: svcCtx})
+	return s
+}
+
+// GetLendDetail 获取存款池详情
+func (s *Server) GetLendDetail(ctx context.Context, req *pb.GetLendDetailRequest) (*pb.LendDetail, error) {
+	lend, err := v1.GetLendData(ctx, s.svcCtx)
+	if err != nil {
+		xzap.WithContext(ctx).Error("failed on get lend data", zap.Error(err))
+		return nil, errcode.NewCustomErr("Get Lend Data failed.")
+	}
+	return toLendDetail(lend), nil
+}
+
+// GetBorrowDetail 获取抵押资产的借款详情
+func (s *Server) GetBorrowDetail(ctx context.Context, req *pb.GetBorrowDetailRequest) (*pb.BorrowDetail, error) {
+	if req.CollateralAsset < 0 {
+		return nil, errcode.ErrInvalidParams
+	}
+	borrow, err := v1.GetBorrowData(ctx, s.svcCtx, int(req.CollateralAsset))
+	if err != nil {
+		xzap.WithContext(ctx).Error("failed on get borrow data", zap.Error(err))
+		return nil, errcode.NewCustomErr("Get Borrow Data failed.")
+	}
+	return toBorrowDetail(borrow), nil
+}
+
+// GetUserPosition 链上读取用户的存款本息和各抵押代币下的借款本息、健康因子，所有读取固定在同一个区块
+func (s *Server) GetUserPosition(ctx context.Context, req *pb.GetUserPositionRequest) (*pb.UserPosition, error) {
+	if s.svcCtx.Pool == nil {
+		return nil, errcode.NewCustomErr("Read user position from chain failed.")
+	}
+	if !chain.IsHexAddress(req.User) || req.Block < 0 {
+		return nil, errcode.ErrInvalidParams
+	}
+	for _, token := range req.CollateralTokens {
+		if !chain.IsHexAddress(token) {
+			return nil, errcode.ErrInvalidParams
+		}
+	}
+	user := strings.ToLower(req.User)
+	lend, err := v1.GetChainUserLend(s.svcCtx, req.Block, user)
+	if err != nil {
+		xzap.WithContext(ctx).Error("failed on get chain user lend", zap.String("user", user), zap.Error(err))
+		return nil, errcode.NewCustomErr("Read user position from chain failed.")
+	}
+	position := &pb.UserPosition{
+		Block:                 lend.Block,
+		User:                  user,
+		LendTotalWithInterest: lend.TotalWithInterest,
+	}
+	for _, token := range req.CollateralTokens {
+		token = strings.ToLower(token)
+		borrow, err := v1.GetChainUserBorrow(s.svcCtx, lend.Block, user, token)
+		if err != nil {
+			xzap.WithContext(ctx).Error("failed on get chain user borrow", zap.String("user", user), zap.String("token", token), zap.Error(err))
+			return nil, errcode.NewCustomErr("Read user position from chain failed.")
+		}
+		health, err := v1.GetChainUserHealth(s.svcCtx, lend.Block, user, token)
+		if err != nil {
+			xzap.WithContext(ctx).Error("failed on get chain user health", zap.String("user", user), zap.String("token", token), zap.Error(err))
+			return nil, errcode.NewCustomErr("Read user position from chain failed.")
+		}
+		position.Borrows = append(position.Borrows, &pb.BorrowPosition{
+			CollateralToken:      token,
+			TotalWithInterest:    borrow.TotalWithInterest,
+			HealthFactor:         health.HealthFactor,
+			TotalCollateralValue: health.TotalCollateralValue,
+			TotalDebtValue:       health.TotalDebtValue,
+			IsLiquidatable:       health.IsLiquidatable,
+		})
+	}
+	return position, nil
+}
+
+// SubscribeStateChanges 推送池子状态变化，直到客户端断开或服务退出
+func (s *Server) SubscribeStateChanges(req *pb.SubscribeStateChangesRequest, stream grpc.ServerStreamingServer[pb.StateChange]) error {
+	ch := s.svcCtx.Feed.Subscribe(feedBuffer)
+	defer s.svcCtx.Feed.Unsubscribe(ch)
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&pb.StateChange{
+				Type: change.Type,
+				Lend: toLendDetail(change.Lend),
+				Time: change.Time,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toLendDetail(lend *types.Lend) *pb.LendDetail {
+	if lend == nil {
+		return nil
+	}
+	return &pb.LendDetail{
+		Type:            int32(lend.Type),
+		TotalBorrow:     lend.TotalBorrow,
+		TotalDeposits:   lend.TotalDeposits,
+		UtilizationRate: int32(lend.UtilizationRate),
+		InterestRate:    int32(lend.InterestRate),
+		BlockNumber:     lend.BlockNumber,
+		TxHash:          lend.TxHash,
+		LogIndex:        int32(lend.LogIndex),
+		UpdateTime:      int64(lend.UpdateTime),
+	}
+}
+
+func toBorrowDetail(borrow *types.Collateral) *pb.BorrowDetail {
+	if borrow == nil {
+		return nil
+	}
+	return &pb.BorrowDetail{
+		TokenAddress:          borrow.TokenAddress,
+		Type:                  int32(borrow.Type),
+		Borrowed:              borrow.Borrowed,
+		Borrowable:            borrow.Borrowable,
+		UtilizationRate:       int32(borrow.UtilizationRate),
+		InterestRate:          int32(borrow.InterestRate),
+		HealthFactor:          int32(borrow.HealthFactor),
+		LiquidationThreshold:  int32(borrow.LiquidationThreshold),
+		CollateralizationRate: int32(borrow.CollateralizationRate),
+		BlockNumber:           borrow.BlockNumber,
+		TxHash:                borrow.TxHash,
+		LogIndex:              int32(borrow.LogIndex),
+		UpdateTime:            int64(borrow.UpdateTime),
+	}
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"aave_web/api/rpc/pb"
+	"aave_web/errcode"
+	logging "aave_web/logger"
+	"aave_web/logger/xzap"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient 在内存连接上启动gRPC服务，返回客户端
+func newTestClient(t *testing.T, svcCtx *service.ServerCtx) pb.AaveServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewGrpcServer(svcCtx)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewAaveServiceClient(conn)
+}
+
+func newTestServerCtx(t *testing.T) *service.ServerCtx {
+	logger, err := xzap.SetUp(logging.LogConf{Mode: "console", Path: os.TempDir(), Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &service.ServerCtx{Logger: logger, Feed: service.NewStateFeed()}
+}
+
+// 业务错误经过errcode拦截器转换为对应的gRPC状态码
+func TestServerMapsErrCode(t *testing.T) {
+	client := newTestClient(t, newTestServerCtx(t))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.GetBorrowDetail(ctx, &pb.GetBorrowDetailRequest{CollateralAsset: -1})
+	s, _ := status.FromError(err)
+	assert.Equal(t, uint32(10002), uint32(s.Code()))
+	assert.Equal(t, errcode.ErrInvalidParams, errcode.ParseErr(err))
+
+	// 没有配置链上读取时返回自定义错误，客户端解析出原始消息
+	_, err = client.GetUserPosition(ctx, &pb.GetUserPositionRequest{User: "0x0000000000000000000000000000000000000001"})
+	s, _ = status.FromError(err)
+	assert.Equal(t, uint32(errcode.CodeCustom), uint32(s.Code()))
+	assert.Equal(t, "Read user position from chain failed.", errcode.ParseErr(err).Error())
+}
+
+// 订阅后收到发布的状态变化
+func TestServerSubscribeStateChanges(t *testing.T) {
+	svcCtx := newTestServerCtx(t)
+	client := newTestClient(t, svcCtx)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeStateChanges(ctx, &pb.SubscribeStateChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 服务端订阅在处理流请求后才建立，重复发布直到收到
+	received := make(chan *pb.StateChange, 1)
+	go func() {
+		change, err := stream.Recv()
+		if err == nil {
+			received <- change
+		}
+	}()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case change := <-received:
+			assert.Equal(t, "lend", change.Type)
+			assert.Equal(t, "100", change.Lend.TotalDeposits)
+			return
+		case <-ticker.C:
+			svcCtx.Feed.Publish(&service.StateChange{Type: "lend", Lend: &v1.Lend{TotalDeposits: "100"}, Time: 1})
+		case <-ctx.Done():
+			t.Fatal("no state change received")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyAdmin 管理接口操作人
+const apiKeyAdmin = "admin"
+
+// CreateApiKeyHandler 新建API key，明文只在响应中返回一次
+func CreateApiKeyHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateApiKeyRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		if (req.Rate > 0) != (req.Burst > 0) {
+			invalidParams(c, "rate and burst must be set together")
+			return
+		}
+		apiKey, err := v1.CreateApiKey(c, serverCtx, strings.TrimSpace(req.Name), req.Owner, req.Rate, req.Burst, apiKeyAdmin)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Create api key failed."))
+			return
+		}
+		xhttp.OkJson(c, apiKey)
+	}
+}
+
+// ListApiKeysHandler 获取所有API key，不包含明文
+func ListApiKeysHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := v1.ListApiKeys(c, serverCtx)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("List api keys failed."))
+			return
+		}
+		xhttp.OkJson(c, items)
+	}
+}
+
+// RevokeApiKeyHandler 吊销API key
+func RevokeApiKeyHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ApiKeyRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		found, err := v1.RevokeApiKey(c, serverCtx, req.ID, apiKeyAdmin)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Revoke api key failed."))
+			return
+		}
+		if !found {
+			invalidParams(c, "api key not found")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// GetApiKeyUsageHandler 获取API key最近几天每天各路由的调用次数，可通过 ?days= 指定天数
+func GetApiKeyUsageHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ApiKeyUsageRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		usage, err := v1.GetApiKeyUsage(serverCtx, req.ID, req.Days)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get api key usage failed."))
+			return
+		}
+		xhttp.OkJson(c, usage)
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetChainLendInfoHandler 直接从链上读取全局存借款信息，可通过 ?block= 指定区块
+func GetChainLendInfoHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChainBlockRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		info, err := v1.GetChainLendInfo(serverCtx, req.Block)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Read lend info from chain failed."))
+			return
+		}
+		xhttp.OkJson(c, info)
+	}
+}
+
+// GetChainTokenBorrowInfoHandler 直接从链上读取抵押代币的借款信息
+func GetChainTokenBorrowInfoHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChainTokenRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		info, err := v1.GetChainTokenBorrowInfo(serverCtx, req.Block, strings.ToLower(req.Token))
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Read borrow info from chain failed."))
+			return
+		}
+		xhttp.OkJson(c, info)
+	}
+}
+
+// GetChainUserLendHandler 直接从链上读取用户存款本息
+func GetChainUserLendHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChainUserRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		info, err := v1.GetChainUserLend(serverCtx, req.Block, strings.ToLower(req.Address))
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Read user lend from chain failed."))
+			return
+		}
+		xhttp.OkJson(c, info)
+	}
+}
+
+// GetChainUserBorrowHandler 直接从链上读取用户在某个抵押代币下的借款本息
+func GetChainUserBorrowHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChainUserTokenRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		info, err := v1.GetChainUserBorrow(serverCtx, req.Block, strings.ToLower(req.Address), strings.ToLower(req.Token))
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Read user borrow from chain failed."))
+			return
+		}
+		xhttp.OkJson(c, info)
+	}
+}
+
+// GetChainUserHealthHandler 直接从链上读取用户在某个抵押代币下的健康因子
+func GetChainUserHealthHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChainUserTokenRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		info, err := v1.GetChainUserHealth(serverCtx, req.Block, strings.ToLower(req.Address), strings.ToLower(req.Token))
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Read user health from chain failed."))
+			return
+		}
+		xhttp.OkJson(c, info)
+	}
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLiquidationsHandler 分页获取全协议清算记录，支持按借款人、清算人、抵押代币和时间过滤
+func GetLiquidationsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LiquidationPageRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		filter, ok := liquidationFilter(c, &req.LiquidationFilterRequest)
+		if !ok {
+			return
+		}
+		if req.Cursor != "" {
+			var err error
+			filter.CursorBlock, filter.CursorLogIndex, err = v1.DecodeLogCursor(req.Cursor)
+			if err != nil {
+				invalidParams(c, "invalid cursor")
+				return
+			}
+		}
+		page, err := v1.GetLiquidations(c, serverCtx, filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get liquidations failed."))
+			return
+		}
+		xhttp.OkJson(c, page)
+	}
+}
+
+// GetLiquidatorLeaderboardHandler 清算人排行，按获得的抵押物美元价值倒序
+func GetLiquidatorLeaderboardHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return liquidationStatsHandler(serverCtx, v1.LiquidationGroupLiquidator)
+}
+
+// GetCollateralLiquidationStatsHandler 按抵押代币统计清算情况
+func GetCollateralLiquidationStatsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return liquidationStatsHandler(serverCtx, v1.LiquidationGroupCollateral)
+}
+
+func liquidationStatsHandler(serverCtx *service.ServerCtx, groupBy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LiquidationFilterRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		filter, ok := liquidationFilter(c, &req)
+		if !ok {
+			return
+		}
+		stats, err := v1.GetLiquidationStats(c, serverCtx, groupBy, filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get liquidation stats failed."))
+			return
+		}
+		xhttp.OkJson(c, stats)
+	}
+}
+
+// liquidationFilter 清算记录的公共查询条件，地址统一转为小写
+func liquidationFilter(c *gin.Context, req *LiquidationFilterRequest) (*dao.LiquidationFilter, bool) {
+	if !req.valid() {
+		invalidParams(c, "from_time greater than to_time")
+		return nil, false
+	}
+	return &dao.LiquidationFilter{
+		Borrower:        strings.ToLower(req.Borrower),
+		Liquidator:      strings.ToLower(req.Liquidator),
+		CollateralToken: strings.ToLower(req.CollateralToken),
+		FromTime:        req.FromTime,
+		ToTime:          req.ToTime,
+		Limit:           req.Limit,
+	}, true
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPoolParamsHandler 获取各风险参数的最新版本
+func GetPoolParamsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params, err := v1.GetPoolParams(c, serverCtx)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get pool params failed."))
+			return
+		}
+		xhttp.OkJson(c, params)
+	}
+}
+
+// GetPoolParamHistoryHandler 分页获取风险参数的历史版本，支持按参数名、抵押代币、时间和是否告警过滤
+func GetPoolParamHistoryHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PoolParamHistoryRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		if !req.valid() {
+			invalidParams(c, "from_time greater than to_time")
+			return
+		}
+		filter := &dao.PoolParamFilter{
+			ParamName:   req.Param,
+			Scope:       strings.ToLower(req.Scope),
+			AlertedOnly: req.Alerted != nil && *req.Alerted,
+			FromTime:    req.FromTime,
+			ToTime:      req.ToTime,
+			CursorID:    req.Cursor,
+			Limit:       req.Limit,
+		}
+		page, err := v1.GetPoolParamHistory(c, serverCtx, filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get pool param history failed."))
+			return
+		}
+		xhttp.OkJson(c, page)
+	}
+}
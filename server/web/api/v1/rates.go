@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRealizedRatesWindow 未指定from时默认统计最近7天
+const defaultRealizedRatesWindow = 7 * 24 * 3600
+
+// GetRealizedRatesHandler 获取两个时间点之间的实际存款/借款年化（from、to为秒级时间戳）
+func GetRealizedRatesHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RealizedRatesRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		to := req.To
+		if to == 0 {
+			to = time.Now().Unix()
+		}
+		from := req.From
+		if from == 0 {
+			from = to - defaultRealizedRatesWindow
+		}
+		if from >= to {
+			invalidParams(c, "from must be less than to")
+			return
+		}
+		rates, err := v1.GetRealizedRates(c, serverCtx, from, to)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get realized rates failed."))
+			return
+		}
+		xhttp.OkJson(c, rates)
+	}
+}
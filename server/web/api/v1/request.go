@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/kit/validator"
+	"aave_web/xhttp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 请求参数结构体，uri、form、json 标签用于绑定，validate 标签用于校验，desc 标签用于生成接口文档
+// label 与参数名一致，校验失败的提示中使用参数名
+
+// BorrowDetailRequest 借款详情请求参数
+type BorrowDetailRequest struct {
+	CollateralAsset *int `form:"collateralAsset" validate:"required,gte=0" label:"collateralAsset" desc:"抵押资产类型，0:TOSHI，1:DEGEN"`
+}
+
+// RealizedRatesRequest 区间实际年化请求参数，to 默认为当前时间，from 默认为 to 之前7天
+type RealizedRatesRequest struct {
+	From int64 `form:"from" validate:"gte=0" label:"from" desc:"开始时间，秒级时间戳"`
+	To   int64 `form:"to" validate:"gte=0" label:"to" desc:"结束时间，秒级时间戳"`
+}
+
+// TimeRangeRequest 按区块时间过滤的公共参数
+type TimeRangeRequest struct {
+	FromTime int64 `form:"from_time" validate:"gte=0" label:"from_time" desc:"开始时间，秒级时间戳"`
+	ToTime   int64 `form:"to_time" validate:"gte=0" label:"to_time" desc:"结束时间，秒级时间戳"`
+}
+
+// valid 结束时间不为0时不能早于开始时间
+func (r *TimeRangeRequest) valid() bool {
+	return r.ToTime == 0 || r.FromTime <= r.ToTime
+}
+
+// LiquidationFilterRequest 清算记录和统计的过滤参数
+type LiquidationFilterRequest struct {
+	Borrower        string `form:"borrower" validate:"omitempty,eth_addr" label:"borrower" desc:"借款人地址"`
+	Liquidator      string `form:"liquidator" validate:"omitempty,eth_addr" label:"liquidator" desc:"清算人地址"`
+	CollateralToken string `form:"collateral_token" validate:"omitempty,eth_addr" label:"collateral_token" desc:"抵押代币地址"`
+	TimeRangeRequest
+	Limit int `form:"limit,default=20" validate:"gte=1,lte=100" label:"limit" desc:"返回条数"`
+}
+
+// LiquidationPageRequest 清算记录分页参数
+type LiquidationPageRequest struct {
+	LiquidationFilterRequest
+	Cursor string `form:"cursor" label:"cursor" desc:"上一页返回的next_cursor"`
+}
+
+// PoolParamHistoryRequest 风险参数历史分页参数
+type PoolParamHistoryRequest struct {
+	Param   string `form:"param" validate:"max=64" label:"param" desc:"参数名"`
+	Scope   string `form:"scope" validate:"omitempty,eth_addr" label:"scope" desc:"抵押代币地址，全局参数为空"`
+	Alerted *bool  `form:"alerted" label:"alerted" desc:"只返回变化超过告警阈值的版本"`
+	TimeRangeRequest
+	Cursor int64 `form:"cursor" validate:"gte=0" label:"cursor" desc:"上一页返回的next_cursor"`
+	Limit  int   `form:"limit,default=20" validate:"gte=1,lte=100" label:"limit" desc:"返回条数"`
+}
+
+// PoolTransactionFilterRequest pool合约交易的过滤参数
+type PoolTransactionFilterRequest struct {
+	Method string `form:"method" validate:"max=64" label:"method" desc:"pool合约方法名"`
+	From   string `form:"from" validate:"omitempty,eth_addr" label:"from" desc:"交易发送方地址"`
+	TimeRangeRequest
+	Limit int `form:"limit,default=20" validate:"gte=1,lte=100" label:"limit" desc:"返回条数"`
+}
+
+// PoolTransactionPageRequest 失败交易分页参数
+type PoolTransactionPageRequest struct {
+	PoolTransactionFilterRequest
+	Cursor string `form:"cursor" label:"cursor" desc:"上一页返回的next_cursor"`
+}
+
+// ChainBlockRequest 链上读取的区块参数，0表示最新区块
+type ChainBlockRequest struct {
+	Block int64 `form:"block" validate:"gte=0" label:"block" desc:"读取的区块，默认最新区块"`
+}
+
+// ChainTokenRequest 链上读取抵押代币信息的参数
+type ChainTokenRequest struct {
+	ChainBlockRequest
+	Token string `uri:"token" validate:"required,eth_addr" label:"token" desc:"抵押代币地址"`
+}
+
+// ChainUserRequest 链上读取用户信息的参数
+type ChainUserRequest struct {
+	ChainBlockRequest
+	Address string `uri:"address" validate:"required,eth_addr" label:"address" desc:"用户地址"`
+}
+
+// ChainUserTokenRequest 链上读取用户在某个抵押代币下信息的参数
+type ChainUserTokenRequest struct {
+	ChainUserRequest
+	Token string `uri:"token" validate:"required,eth_addr" label:"token" desc:"抵押代币地址"`
+}
+
+// UserEarningsRequest 用户收益报表参数
+type UserEarningsRequest struct {
+	Address string `uri:"address" validate:"required,eth_addr" label:"address" desc:"用户地址，必须是当前登录的地址"`
+	Format  string `form:"format" validate:"omitempty,oneof=json csv" label:"format" desc:"csv时导出CSV文件"`
+}
+
+// UserActivityRequest 用户操作记录分页参数
+type UserActivityRequest struct {
+	Address   string `uri:"address" validate:"required,eth_addr" label:"address" desc:"用户地址"`
+	Action    string `form:"action" label:"action" desc:"逗号分隔的操作类型"`
+	Token     string `form:"token" validate:"omitempty,eth_addr" label:"token" desc:"代币地址"`
+	FromBlock int64  `form:"from_block" validate:"gte=0" label:"from_block" desc:"开始区块"`
+	ToBlock   int64  `form:"to_block" validate:"gte=0" label:"to_block" desc:"结束区块"`
+	TimeRangeRequest
+	Cursor string `form:"cursor" label:"cursor" desc:"上一页返回的next_cursor"`
+	Limit  int    `form:"limit,default=20" validate:"gte=1,lte=100" label:"limit" desc:"返回条数"`
+}
+
+// CreateApiKeyRequest 新建API key的请求参数，rate、burst为0时使用路由配额
+type CreateApiKeyRequest struct {
+	Name  string  `json:"name" validate:"required,max=64" label:"name" desc:"key名称，说明用途"`
+	Owner string  `json:"owner" validate:"max=128" label:"owner" desc:"使用方"`
+	Rate  float64 `json:"rate" validate:"gte=0" label:"rate" desc:"每秒补充的令牌数"`
+	Burst int     `json:"burst" validate:"gte=0" label:"burst" desc:"令牌桶容量"`
+}
+
+// ApiKeyRequest 指定API key的参数
+type ApiKeyRequest struct {
+	ID int64 `uri:"id" validate:"required,gt=0" label:"id" desc:"API key id"`
+}
+
+// ApiKeyUsageRequest API key用量参数
+type ApiKeyUsageRequest struct {
+	ApiKeyRequest
+	Days int `form:"days,default=7" validate:"gte=1,lte=31" label:"days" desc:"最近天数"`
+}
+
+// bindRequest 绑定路径参数、查询参数和请求体并校验，失败时返回参数错误，校验失败的错误信息包含不合法的参数
+func bindRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindUri(req); err != nil {
+		invalidParams(c, err.Error())
+		return false
+	}
+	if err := c.ShouldBindQuery(req); err != nil {
+		invalidParams(c, err.Error())
+		return false
+	}
+	if c.Request.Method != http.MethodGet && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(req); err != nil {
+			invalidParams(c, err.Error())
+			return false
+		}
+	}
+	if ves := validator.Verify(req); len(ves) > 0 {
+		invalidParams(c, validator.ParseErr(ves))
+		return false
+	}
+	return true
+}
+
+// invalidParams 返回参数错误，消息中带上具体原因
+func invalidParams(c *gin.Context, reason string) {
+	xhttp.Error(c, errcode.NewErr(errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()+": "+reason, errcode.ErrInvalidParams.HTTPCode()))
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aave_web/errcode"
+	"aave_web/xhttp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "0x4533840185dF00119F5a3cD8F2379C0160CA875b"
+
+// serveBind 注册route并发送请求，返回绑定结果和响应
+func serveBind(t *testing.T, method, route, target, body string, req interface{}) (bool, *xhttp.Response) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var bound bool
+	engine.Handle(method, route, func(c *gin.Context) {
+		bound = bindRequest(c, req)
+	})
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if bound {
+		return true, nil
+	}
+	var resp xhttp.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, errcode.ErrInvalidParams.HTTPCode(), w.Code)
+	return false, &resp
+}
+
+func TestBindUserActivityRequest(t *testing.T) {
+	var req UserActivityRequest
+	ok, _ := serveBind(t, http.MethodGet, "/user/:address/activity", "/user/"+testAddress+"/activity?action=borrow&from_time=10&to_time=20", "", &req)
+	assert.True(t, ok)
+	assert.Equal(t, testAddress, req.Address)
+	assert.Equal(t, "borrow", req.Action)
+	assert.Equal(t, int64(10), req.FromTime)
+	assert.Equal(t, int64(20), req.ToTime)
+	assert.Equal(t, 20, req.Limit, "default limit")
+
+	tests := []struct {
+		name   string
+		target string
+		field  string
+	}{
+		{"invalid address", "/user/0x123/activity", "address"},
+		{"invalid token", "/user/" + testAddress + "/activity?token=abc", "token"},
+		{"limit too large", "/user/" + testAddress + "/activity?limit=101", "limit"},
+		{"negative block", "/user/" + testAddress + "/activity?from_block=-1", "from_block"},
+		// 类型转换失败时gin的错误信息不包含参数名
+		{"non numeric", "/user/" + testAddress + "/activity?limit=abc", "invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req UserActivityRequest
+			ok, resp := serveBind(t, http.MethodGet, "/user/:address/activity", tt.target, "", &req)
+			assert.False(t, ok)
+			assert.Equal(t, errcode.ErrInvalidParams.Code(), resp.Code)
+			assert.Contains(t, resp.Msg, tt.field)
+		})
+	}
+}
+
+func TestBindEmbeddedUriRequest(t *testing.T) {
+	var req ChainUserTokenRequest
+	ok, _ := serveBind(t, http.MethodGet, "/user/:address/borrow/:token", "/user/"+testAddress+"/borrow/"+testAddress+"?block=100", "", &req)
+	assert.True(t, ok)
+	assert.Equal(t, testAddress, req.Address)
+	assert.Equal(t, testAddress, req.Token)
+	assert.Equal(t, int64(100), req.Block)
+
+	var usage ApiKeyUsageRequest
+	ok, _ = serveBind(t, http.MethodGet, "/api-keys/:id/usage", "/api-keys/3/usage", "", &usage)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), usage.ID)
+	assert.Equal(t, 7, usage.Days, "default days")
+
+	ok, resp := serveBind(t, http.MethodGet, "/api-keys/:id/usage", "/api-keys/0/usage", "", &ApiKeyUsageRequest{})
+	assert.False(t, ok)
+	assert.Contains(t, resp.Msg, "id")
+}
+
+func TestBindRequiredPointer(t *testing.T) {
+	var req BorrowDetailRequest
+	ok, _ := serveBind(t, http.MethodGet, "/borrow", "/borrow?collateralAsset=0", "", &req)
+	assert.True(t, ok)
+	assert.Equal(t, 0, *req.CollateralAsset)
+
+	ok, resp := serveBind(t, http.MethodGet, "/borrow", "/borrow", "", &BorrowDetailRequest{})
+	assert.False(t, ok)
+	assert.Contains(t, resp.Msg, "collateralAsset")
+}
+
+func TestBindJsonBody(t *testing.T) {
+	var req CreateApiKeyRequest
+	ok, _ := serveBind(t, http.MethodPost, "/api-keys", "/api-keys", `{"name":"bot","rate":2.5,"burst":10}`, &req)
+	assert.True(t, ok)
+	assert.Equal(t, "bot", req.Name)
+	assert.Equal(t, 2.5, req.Rate)
+	assert.Equal(t, 10, req.Burst)
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"missing name", `{"rate":1}`, "name"},
+		{"negative burst", `{"name":"bot","burst":-1}`, "burst"},
+		{"malformed json", `{"name":`, ""},
+		{"no body", ``, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, resp := serveBind(t, http.MethodPost, "/api-keys", "/api-keys", tt.body, &CreateApiKeyRequest{})
+			assert.False(t, ok)
+			assert.Equal(t, errcode.ErrInvalidParams.Code(), resp.Code)
+			assert.Contains(t, resp.Msg, tt.field)
+		})
+	}
+}
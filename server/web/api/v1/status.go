@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthzHandler 存活检查，检查MySQL和Redis，失败时返回503
+func HealthzHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return healthHandler(serverCtx, false)
+}
+
+// ReadyzHandler 就绪检查，在存活检查的基础上检查最新lend快照是否过期，失败时返回503
+func ReadyzHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return healthHandler(serverCtx, true)
+}
+
+// healthHandler 探针直接根据HTTP状态码判断，不使用统一的响应结构
+func healthHandler(serverCtx *service.ServerCtx, ready bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		health, ok := v1.CheckHealth(c, serverCtx, ready)
+		code := http.StatusOK
+		if !ok {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, health)
+	}
+}
+
+// GetStatusHandler 获取事件同步状态：链id、已同步区块、链上最新区块、落后区块数和最近事件时间
+func GetStatusHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := v1.GetStatus(c, serverCtx)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get status failed."))
+			return
+		}
+		xhttp.OkJson(c, status)
+	}
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	"aave_web/xhttp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPoolTransactionStatsHandler 按方法统计pool合约交易的数量、失败率和gas消耗，支持按发送方和时间过滤
+func GetPoolTransactionStatsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PoolTransactionFilterRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		filter, ok := poolTransactionFilter(c, &req)
+		if !ok {
+			return
+		}
+		stats, err := v1.GetPoolTransactionStats(c, serverCtx, filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get transaction stats failed."))
+			return
+		}
+		xhttp.OkJson(c, stats)
+	}
+}
+
+// GetFailedPoolTransactionsHandler 分页获取失败的pool合约交易，支持按方法、发送方和时间过滤
+func GetFailedPoolTransactionsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PoolTransactionPageRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		filter, ok := poolTransactionFilter(c, &req.PoolTransactionFilterRequest)
+		if !ok {
+			return
+		}
+		if req.Cursor != "" {
+			var err error
+			filter.CursorBlock, filter.CursorTxIndex, err = v1.DecodeLogCursor(req.Cursor)
+			if err != nil {
+				invalidParams(c, "invalid cursor")
+				return
+			}
+		}
+		page, err := v1.GetFailedPoolTransactions(c, serverCtx, filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get failed transactions failed."))
+			return
+		}
+		xhttp.OkJson(c, page)
+	}
+}
+
+// poolTransactionFilter pool合约交易的公共查询条件，地址统一转为小写
+func poolTransactionFilter(c *gin.Context, req *PoolTransactionFilterRequest) (*dao.PoolTransactionFilter, bool) {
+	if !req.valid() {
+		invalidParams(c, "from_time greater than to_time")
+		return nil, false
+	}
+	return &dao.PoolTransactionFilter{
+		Method:      req.Method,
+		FromAddress: strings.ToLower(req.From),
+		FromTime:    req.FromTime,
+		ToTime:      req.ToTime,
+		Limit:       req.Limit,
+	}, true
+}
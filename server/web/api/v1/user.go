@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"aave_web/api/middleware"
+	"aave_web/dao"
+	"aave_web/errcode"
+	"aave_web/service"
+	v1 "aave_web/service/v1"
+	types "aave_web/types/v1"
+	"aave_web/xhttp"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// activityActions 允许过滤的操作类型
+var activityActions = map[string]bool{
+	types.ActionLendDeposit:        true,
+	types.ActionLendWithdraw:       true,
+	types.ActionCollateralDeposit:  true,
+	types.ActionCollateralWithdraw: true,
+	types.ActionBorrow:             true,
+	types.ActionRepay:              true,
+	types.ActionLiquidated:         true,
+	types.ActionLiquidate:          true,
+}
+
+// earningsCsvHeader 收益报表CSV表头
+var earningsCsvHeader = []string{"block_number", "block_time", "tx_hash", "action", "amount", "interest", "principal", "realized_interest", "accrued_interest"}
+
+// GetUserEarningsHandler 获取用户存款收益报表（本金、累积利息、已实现利息），format=csv时导出CSV
+func GetUserEarningsHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UserEarningsRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		// 路由上的 UserOwnerRequired 已校验地址属于当前登录用户
+		earnings, err := v1.GetUserEarnings(c, serverCtx, strings.ToLower(req.Address))
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get user earnings failed."))
+			return
+		}
+		if req.Format == "csv" {
+			writeEarningsCsv(c, earnings)
+			return
+		}
+		xhttp.OkJson(c, earnings)
+	}
+}
+
+// UserOwnerRequired 校验路径参数address是当前登录的地址，只有本人可以查看
+// 需要放在接口缓存之前，否则缓存命中的响应会跳过校验
+func UserOwnerRequired(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := authorizeUserAddress(c, serverCtx, c.Param("address")); !ok {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeUserAddress 校验路径中的用户地址必须是当前登录的地址，只有本人可以查看
+func authorizeUserAddress(c *gin.Context, serverCtx *service.ServerCtx, address string) (string, bool) {
+	address = strings.ToLower(address)
+	addrs, err := middleware.GetAuthUserAddress(c, serverCtx.KvStore)
+	if err != nil {
+		xhttp.Error(c, errcode.ErrTokenVerify)
+		return "", false
+	}
+	for _, addr := range addrs {
+		if strings.ToLower(addr) == address {
+			return address, true
+		}
+	}
+	xhttp.Error(c, errcode.ErrPermissionDenied)
+	return "", false
+}
+
+// writeEarningsCsv 以附件形式输出收益明细CSV，用于报税
+func writeEarningsCsv(c *gin.Context, earnings *types.UserEarnings) {
+	c.Header(xhttp.HeaderContentDisposition, fmt.Sprintf("attachment; filename=earnings_%s.csv", earnings.Address))
+	c.Header(xhttp.HeaderContentType, "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(earningsCsvHeader)
+	for _, entry := range earnings.Entries {
+		_ = w.Write([]string{
+			strconv.FormatInt(entry.BlockNumber, 10),
+			strconv.FormatInt(entry.BlockTime, 10),
+			entry.TxHash,
+			entry.Action,
+			entry.Amount,
+			entry.Interest,
+			entry.Principal,
+			entry.RealizedInterest,
+			entry.AccruedInterest,
+		})
+	}
+	w.Flush()
+}
+
+// GetUserActivityHandler 分页获取用户操作记录，支持按操作类型、代币、区块和时间范围过滤
+// 翻页时把上一页返回的next_cursor作为cursor参数传入
+func GetUserActivityHandler(serverCtx *service.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UserActivityRequest
+		if !bindRequest(c, &req) {
+			return
+		}
+		filter, err := activityFilter(&req)
+		if err != nil {
+			invalidParams(c, err.Error())
+			return
+		}
+		page, err := v1.GetUserActivities(c, serverCtx, strings.ToLower(req.Address), filter)
+		if err != nil {
+			xhttp.Error(c, errcode.NewCustomErr("Get user activity failed."))
+			return
+		}
+		xhttp.OkJson(c, page)
+	}
+}
+
+// activityFilter 操作记录的查询条件，action为逗号分隔的多个操作类型
+func activityFilter(req *UserActivityRequest) (*dao.ActivityFilter, error) {
+	filter := &dao.ActivityFilter{
+		TokenAddress: strings.ToLower(req.Token),
+		FromBlock:    req.FromBlock,
+		ToBlock:      req.ToBlock,
+		FromTime:     req.FromTime,
+		ToTime:       req.ToTime,
+		Limit:        req.Limit,
+	}
+	if req.Action != "" {
+		for _, action := range strings.Split(req.Action, ",") {
+			action = strings.TrimSpace(action)
+			if !activityActions[action] {
+				return nil, errors.Errorf("unknown action %s", action)
+			}
+			filter.Actions = append(filter.Actions, action)
+		}
+	}
+	if filter.ToBlock > 0 && filter.FromBlock > filter.ToBlock {
+		return nil, errors.New("from_block greater than to_block")
+	}
+	if !req.valid() {
+		return nil, errors.New("from_time greater than to_time")
+	}
+	if req.Cursor != "" {
+		blockNumber, logIndex, id, err := v1.DecodeActivityCursor(req.Cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		filter.CursorBlock = blockNumber
+		filter.CursorLogIndex = logIndex
+		filter.CursorID = id
+	}
+	return filter, nil
+}
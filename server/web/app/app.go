@@ -1,11 +1,18 @@
 package app
 
 import (
+	"aave_web/api/router"
+	"aave_web/api/rpc"
 	"aave_web/config"
 	"aave_web/logger/xzap"
 	"aave_web/service"
 	"context"
+	"net"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 
 	"go.uber.org/zap"
 )
@@ -16,17 +23,88 @@ type Platform struct {
 	serverCtx *service.ServerCtx
 }
 
-func NewPlatform(config *config.Config, router *gin.Engine, serverCtx *service.ServerCtx) (*Platform, error) {
+func NewPlatform(config *config.Config, serverCtx *service.ServerCtx) (*Platform, error) {
 	return &Platform{
 		config:    config,
-		router:    router,
 		serverCtx: serverCtx,
 	}, nil
 }
 
-func (p *Platform) Start() {
-	xzap.WithContext(context.Background()).Info("Aave-End run", zap.String("port", p.config.Api.Port))
-	if err := p.router.Run(p.config.Api.Port); err != nil {
-		panic(err)
+// Start 创建路由并启动HTTP和gRPC服务，阻塞到收到退出信号并停止所有组件，路由创建失败时直接返回错误
+// 停止顺序：关闭状态订阅让长连接结束 -> HTTP停止接受新请求并等待处理中的请求 -> gRPC -> 路由中注册的通知消费和websocket -> 数据库
+func (p *Platform) Start() error {
+	lifecycle := p.serverCtx.Lifecycle
+	r, err := router.NewRouter(p.serverCtx)
+	if err != nil {
+		lifecycle.Stop()
+		lifecycle.Wait()
+		return errors.Wrap(err, "failed on create router")
+	}
+	p.router = r
+	if p.config.Api.GrpcPort != "" {
+		if err := p.startGrpc(); err != nil {
+			lifecycle.Stop()
+			lifecycle.Wait()
+			return err
+		}
+	}
+
+	server := &http.Server{Addr: p.config.Api.Port, Handler: p.router}
+	serveErr := make(chan error, 1)
+	go func() {
+		xzap.WithContext(context.Background()).Info("Aave-End run", zap.String("port", p.config.Api.Port))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			xzap.WithContext(context.Background()).Error("http server stopped", zap.Error(err))
+			serveErr <- errors.Wrap(err, "failed on http server")
+			lifecycle.Stop()
+		}
+	}()
+	lifecycle.OnStop("http", server.Shutdown)
+	lifecycle.OnStop("state feed", func(ctx context.Context) error {
+		p.serverCtx.Feed.Close()
+		return nil
+	})
+
+	lifecycle.Wait()
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// startGrpc 在独立端口启动gRPC服务，与gin并行运行
+func (p *Platform) startGrpc() error {
+	lis, err := net.Listen("tcp", p.config.Api.GrpcPort)
+	if err != nil {
+		return errors.Wrap(err, "failed on listen grpc port")
+	}
+	server := rpc.NewGrpcServer(p.serverCtx)
+	go func() {
+		xzap.WithContext(context.Background()).Info("Aave-End grpc run", zap.String("port", p.config.Api.GrpcPort))
+		if err := server.Serve(lis); err != nil {
+			xzap.WithContext(context.Background()).Error("grpc server stopped", zap.Error(err))
+		}
+	}()
+	p.serverCtx.Lifecycle.OnStop("grpc", func(ctx context.Context) error {
+		return stopGrpc(ctx, server)
+	})
+	return nil
+}
+
+// stopGrpc 等待处理中的请求完成，ctx到期时强制关闭
+func stopGrpc(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
 	}
 }
@@ -0,0 +1,198 @@
+package chain
+
+import (
+	"aave_web/xhttp"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 池子合约view函数的选择器，keccak256(函数签名)的前4字节
+const (
+	selectorGetTotalLendInfo         = "b2dc0bd1" // getTotalLendInfo()
+	selectorGetTokenBorrowInfo       = "658c53fc" // getTokenBorrowInfo(address)
+	selectorCalculateUserLendTotal   = "11514c50" // calculateUserLendTotal(address)
+	selectorCalculateUserBorrowTotal = "4e3461a6" // calculateUserBorrowTotal(address,address)
+	selectorGetUserHealthInfo        = "8c572f48" // getUserHealthInfo(address,address)
+)
+
+// maxBatchCalls 单次JSON-RPC批量请求最多包含的eth_call数量，大部分节点服务商限制在100以内
+const maxBatchCalls = 50
+
+var addressRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// IsHexAddress 判断是否为0x开头的20字节十六进制地址
+func IsHexAddress(address string) bool {
+	return addressRegexp.MatchString(address)
+}
+
+// PoolReader 通过 eth_call 读取池子合约的view函数，所有调用都固定在指定区块上执行
+type PoolReader struct {
+	client      xhttp.RPCClient
+	poolAddress string
+}
+
+// NewPoolReader 新建池子合约读取器
+func NewPoolReader(client xhttp.RPCClient, poolAddress string) *PoolReader {
+	return &PoolReader{client: client, poolAddress: strings.ToLower(poolAddress)}
+}
+
+// BlockNumber 获取节点最新区块号
+func (r *PoolReader) BlockNumber() (int64, error) {
+	var result string
+	if err := r.client.CallFor(&result, "eth_blockNumber"); err != nil {
+		return 0, errors.Wrap(err, "failed on eth_blockNumber")
+	}
+	return parseHexInt64(result)
+}
+
+// GetTotalLendInfo 调用 getTotalLendInfo()，返回 totalLend, totalBorrow, utilizationRate, interestApy
+func (r *PoolReader) GetTotalLendInfo(block int64) ([]*big.Int, error) {
+	return r.callWords(block, selectorGetTotalLendInfo, 4)
+}
+
+// GetTokenBorrowInfo 调用 getTokenBorrowInfo(collateralToken)，返回 borrowed, borrowable, utilizationRate
+func (r *PoolReader) GetTokenBorrowInfo(block int64, collateralToken string) ([]*big.Int, error) {
+	return r.callWords(block, selectorGetTokenBorrowInfo, 3, collateralToken)
+}
+
+// CalculateUserLendTotal 调用 calculateUserLendTotal(user)，返回用户存款本息
+func (r *PoolReader) CalculateUserLendTotal(block int64, user string) (*big.Int, error) {
+	words, err := r.callWords(block, selectorCalculateUserLendTotal, 1, user)
+	if err != nil {
+		return nil, err
+	}
+	return words[0], nil
+}
+
+// CalculateUserBorrowTotal 调用 calculateUserBorrowTotal(user, collateralToken)，返回用户借款本息
+func (r *PoolReader) CalculateUserBorrowTotal(block int64, user, collateralToken string) (*big.Int, error) {
+	words, err := r.callWords(block, selectorCalculateUserBorrowTotal, 1, user, collateralToken)
+	if err != nil {
+		return nil, err
+	}
+	return words[0], nil
+}
+
+// GetUserHealthInfo 调用 getUserHealthInfo(collateralToken, user)，
+// 返回 healthFactor, totalCollateralValue, totalDebtValue, isLiquidatable(0/1)
+func (r *PoolReader) GetUserHealthInfo(block int64, collateralToken, user string) ([]*big.Int, error) {
+	return r.callWords(block, selectorGetUserHealthInfo, 4, collateralToken, user)
+}
+
+// HealthQuery 批量读取健康因子的一组参数
+type HealthQuery struct {
+	CollateralToken string
+	User            string
+}
+
+// HealthResult 与HealthQuery一一对应，Words同GetUserHealthInfo，单个调用失败时Err不为空
+type HealthResult struct {
+	Words []*big.Int
+	Err   error
+}
+
+// GetUserHealthInfoBatch 把多个 getUserHealthInfo 合并为JSON-RPC批量请求，全部固定在block执行
+// 单个调用失败只记录在对应结果的Err中，只有请求本身失败时才返回error
+func (r *PoolReader) GetUserHealthInfoBatch(block int64, queries []HealthQuery) ([]HealthResult, error) {
+	results := make([]HealthResult, len(queries))
+	for start := 0; start < len(queries); start += maxBatchCalls {
+		end := start + maxBatchCalls
+		if end > len(queries) {
+			end = len(queries)
+		}
+		var requests xhttp.RPCRequests
+		var indexes []int
+		for i := start; i < end; i++ {
+			call, err := r.newCall(selectorGetUserHealthInfo, queries[i].CollateralToken, queries[i].User)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			req := xhttp.NewRPCRequest("eth_call", call, fmt.Sprintf("0x%x", block))
+			req.ID = len(requests)
+			requests = append(requests, req)
+			indexes = append(indexes, i)
+		}
+		if len(requests) == 0 {
+			continue
+		}
+		resps, err := r.client.CallBatch(requests)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed on batch eth_call 0x%s", selectorGetUserHealthInfo)
+		}
+		for j, i := range indexes {
+			if rpcErr := resps[j].GetError(); rpcErr != nil {
+				results[i].Err = errors.Wrapf(rpcErr, "failed on eth_call 0x%s", selectorGetUserHealthInfo)
+				continue
+			}
+			result, err := resps[j].GetString()
+			if err != nil {
+				results[i].Err = errors.Wrap(err, "failed on read eth_call result")
+				continue
+			}
+			results[i].Words, results[i].Err = decodeWords(selectorGetUserHealthInfo, result, 4)
+		}
+	}
+	return results, nil
+}
+
+// callWords 以地址为参数调用view函数，返回值都是静态类型，按32字节一个字解析
+func (r *PoolReader) callWords(block int64, selector string, outputs int, addresses ...string) ([]*big.Int, error) {
+	call, err := r.newCall(selector, addresses...)
+	if err != nil {
+		return nil, err
+	}
+	var result string
+	if err := r.client.CallFor(&result, "eth_call", call, fmt.Sprintf("0x%x", block)); err != nil {
+		return nil, errors.Wrapf(err, "failed on eth_call 0x%s", selector)
+	}
+	return decodeWords(selector, result, outputs)
+}
+
+// newCall 编码以地址为参数的调用，返回 eth_call 的调用对象
+func (r *PoolReader) newCall(selector string, addresses ...string) (map[string]string, error) {
+	var data strings.Builder
+	data.WriteString("0x")
+	data.WriteString(selector)
+	for _, address := range addresses {
+		if !IsHexAddress(address) {
+			return nil, errors.Errorf("invalid address %s", address)
+		}
+		data.WriteString(strings.Repeat("0", 24))
+		data.WriteString(strings.ToLower(address[2:]))
+	}
+	return map[string]string{
+		"to":   r.poolAddress,
+		"data": data.String(),
+	}, nil
+}
+
+// decodeWords 按32字节一个字解析 eth_call 的返回值
+func decodeWords(selector, result string, outputs int) ([]*big.Int, error) {
+	out, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on decode eth_call result")
+	}
+	if len(out) < outputs*32 {
+		return nil, errors.Errorf("eth_call 0x%s returned %d bytes, want %d", selector, len(out), outputs*32)
+	}
+	words := make([]*big.Int, outputs)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(out[i*32 : (i+1)*32])
+	}
+	return words, nil
+}
+
+func parseHexInt64(value string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimPrefix(value, "0x"), 16, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid hex number %s", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,121 @@
+package chain
+
+import (
+	"aave_web/xhttp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReader(t *testing.T, handler func(req *xhttp.RPCRequest) interface{}) *PoolReader {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req xhttp.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  handler(&req),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return NewPoolReader(xhttp.NewRPCClient(server.URL), "0xC0AF09A3986b237Faf6a66AC94C49376953F93DA")
+}
+
+func TestPoolReaderBlockNumber(t *testing.T) {
+	r := newTestReader(t, func(req *xhttp.RPCRequest) interface{} {
+		assert.Equal(t, "eth_blockNumber", req.Method)
+		return "0x10"
+	})
+	block, err := r.BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), block)
+}
+
+func TestPoolReaderGetUserHealthInfo(t *testing.T) {
+	token := "0x4533840185dF00119F5a3cD8F2379C0160CA875b"
+	user := "0x0A38A1Ef0fae4DC3AAd1A5FD419CBc4687A2C05C"
+	r := newTestReader(t, func(req *xhttp.RPCRequest) interface{} {
+		params := req.Params.([]interface{})
+		call := params[0].(map[string]interface{})
+		assert.Equal(t, "0xc0af09a3986b237faf6a66ac94c49376953f93da", call["to"])
+		assert.Equal(t, "0x"+selectorGetUserHealthInfo+
+			strings.Repeat("0", 24)+strings.ToLower(token[2:])+
+			strings.Repeat("0", 24)+strings.ToLower(user[2:]), call["data"])
+		assert.Equal(t, "0x64", params[1])
+		return "0x" + word(1) + word(2) + word(3) + word(1)
+	})
+	words, err := r.GetUserHealthInfo(100, token, user)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "1"}, []string{words[0].String(), words[1].String(), words[2].String(), words[3].String()})
+}
+
+func TestPoolReaderGetUserHealthInfoBatch(t *testing.T) {
+	token := "0x4533840185dF00119F5a3cD8F2379C0160CA875b"
+	users := []string{
+		"0x0A38A1Ef0fae4DC3AAd1A5FD419CBc4687A2C05C",
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+	}
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*xhttp.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		batches = append(batches, len(reqs))
+		var resps []map[string]interface{}
+		for _, req := range reqs {
+			params := req.Params.([]interface{})
+			assert.Equal(t, "0x64", params[1])
+			data := params[0].(map[string]interface{})["data"].(string)
+			resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			switch {
+			case strings.HasSuffix(data, strings.ToLower(users[0][2:])):
+				resp["result"] = "0x" + word(1) + word(2) + word(3) + word(0)
+			case strings.HasSuffix(data, users[2][2:]):
+				resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+			}
+			resps = append(resps, resp)
+		}
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(server.Close)
+	r := NewPoolReader(xhttp.NewRPCClient(server.URL), "0xC0AF09A3986b237Faf6a66AC94C49376953F93DA")
+
+	results, err := r.GetUserHealthInfoBatch(100, []HealthQuery{
+		{CollateralToken: token, User: strings.ToLower(users[0])},
+		{CollateralToken: token, User: "0x1234"},
+		{CollateralToken: token, User: users[2]},
+	})
+	assert.NoError(t, err)
+	// 地址不合法的调用不发送，其余调用在一次批量请求中发送
+	assert.Equal(t, []int{2}, batches)
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, []string{"1", "2", "3", "0"}, []string{results[0].Words[0].String(), results[0].Words[1].String(), results[0].Words[2].String(), results[0].Words[3].String()})
+		assert.Error(t, results[1].Err)
+		assert.Error(t, results[2].Err)
+		assert.Nil(t, results[2].Words)
+	}
+}
+
+func TestPoolReaderShortResult(t *testing.T) {
+	r := newTestReader(t, func(req *xhttp.RPCRequest) interface{} {
+		return "0x" + word(1)
+	})
+	_, err := r.GetTotalLendInfo(1)
+	assert.Error(t, err)
+}
+
+func TestPoolReaderInvalidAddress(t *testing.T) {
+	r := NewPoolReader(xhttp.NewRPCClient("http://127.0.0.1:0"), "0x0")
+	_, err := r.CalculateUserLendTotal(1, "0x1234")
+	assert.Error(t, err)
+}
+
+func word(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
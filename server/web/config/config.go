@@ -12,11 +12,56 @@ type Config struct {
 	Log logging.LogConf `toml:"log" json:"log"`
 	DB  gdb.Config              `toml:"db" json:"db"`
 	Kv  *KvConf         `toml:"kv" json:"kv"`
+	Chain ChainConf     `toml:"chain" mapstructure:"chain" json:"chain"`
+	RateLimit RateLimitConf `toml:"rate_limit" mapstructure:"rate_limit" json:"rate_limit"`
+	Health HealthConf `toml:"health" mapstructure:"health" json:"health"`
+	Ws WsConf `toml:"ws" mapstructure:"ws" json:"ws"`
 }
 
 type Api struct {
-	Port   string `toml:"port" json:"port"`
-	MaxNum int64  `toml:"max_num" json:"max_num"`
+	Port            string `toml:"port" json:"port"`
+	MaxNum          int64  `toml:"max_num" json:"max_num"`
+	GrpcPort        string `toml:"grpc_port" mapstructure:"grpc_port" json:"grpc_port"`                      // gRPC监听地址，为空时不启动gRPC服务
+	CacheSeconds    int    `toml:"cache_seconds" mapstructure:"cache_seconds" json:"cache_seconds"`          // 详情和历史接口的缓存时间，状态变化时按标签清除，为0时不缓存
+	AdminToken      string `toml:"admin_token" mapstructure:"admin_token" json:"-"`                          // 管理接口令牌，通过 X-Admin-Token 请求头传入，为空时不开放管理接口
+	ShutdownTimeout int    `toml:"shutdown_timeout" mapstructure:"shutdown_timeout" json:"shutdown_timeout"` // 优雅退出的总超时时间（秒），为0时默认30秒
+}
+
+// RateLimitConf 接口限流配置，按API key、登录地址或客户端IP分别计算令牌桶
+type RateLimitConf struct {
+	Enable bool                 `toml:"enable" mapstructure:"enable" json:"enable"`
+	Rate   float64              `toml:"rate" mapstructure:"rate" json:"rate"`       // 默认每秒补充的令牌数
+	Burst  int                  `toml:"burst" mapstructure:"burst" json:"burst"`    // 默认令牌桶容量
+	Routes map[string]RateQuota `toml:"routes" mapstructure:"routes" json:"routes"` // 按路由设置的配额，key为路由路径，如 /api/v1/borrow/detail
+}
+
+// RateQuota 令牌桶配额
+type RateQuota struct {
+	Rate  float64 `toml:"rate" mapstructure:"rate" json:"rate"`
+	Burst int     `toml:"burst" mapstructure:"burst" json:"burst"`
+}
+
+// ChainConf 链上读取配置，用于直接调用池子合约的view函数
+type ChainConf struct {
+	RpcUrl          string `toml:"rpc_url" mapstructure:"rpc_url" json:"rpc_url"`
+	AavePoolAddress string `toml:"aave_pool_address" mapstructure:"aave_pool_address" json:"aave_pool_address"`
+	ChainID         int64  `toml:"chain_id" mapstructure:"chain_id" json:"chain_id"`                // 与调度服务的 chain_cfg.id 一致，用于读取同步进度，为0时取第一条同步记录
+	CacheSeconds    int    `toml:"cache_seconds" mapstructure:"cache_seconds" json:"cache_seconds"` // 链上读取结果缓存时间
+}
+
+// HealthConf 就绪检查和同步状态的阈值，为0时不检查
+type HealthConf struct {
+	IndexedMaxAge int64 `toml:"indexed_max_age" mapstructure:"indexed_max_age" json:"indexed_max_age"` // 调度服务最后一次同步进展距今的最大秒数，超过时未就绪并标记数据延迟
+	MaxBlockLag   int64 `toml:"max_block_lag" mapstructure:"max_block_lag" json:"max_block_lag"`       // 同步落后的最大区块数，超过时未就绪并标记数据延迟
+}
+
+// WsConf WebSocket和SSE推送配置
+type WsConf struct {
+	ReplaySize    int    `toml:"replay_size" mapstructure:"replay_size" json:"replay_size"`          // 每个主题保留的最近消息条数，供断线重连后补发，为0时默认256
+	PersistReplay bool   `toml:"persist_replay" mapstructure:"persist_replay" json:"persist_replay"` // 最近消息和主题序号保存到Redis，服务重启后客户端仍可按序号补发
+	ReplayTTL     int    `toml:"replay_ttl" mapstructure:"replay_ttl" json:"replay_ttl"`             // 持久化的最近消息和序号的过期时间（秒），为0时默认86400
+	Instance      string `toml:"instance" mapstructure:"instance" json:"instance"`                   // 持久化key和通知处理中队列中的实例名，为空时使用主机名，重启后需要保持不变才能恢复
+	HealthAlert   int64  `toml:"health_alert" mapstructure:"health_alert" json:"health_alert"`       // 健康因子下降到该值以下时向用户推送health_drop，6位精度，为0时默认1500000
 }
 
 type KvConf struct {
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+	"time"
+)
+
+// CreateApiKey 保存新建的API key
+func (d *Dao) CreateApiKey(ctx context.Context, apiKey *v1.ApiKey) error {
+	return d.DB.WithContext(ctx).Table(v1.GetApiKeyTableName()).Create(apiKey).Error
+}
+
+// GetApiKeyByHash 根据key哈希查询API key，不存在时返回nil
+func (d *Dao) GetApiKeyByHash(ctx context.Context, keyHash string) (*v1.ApiKey, error) {
+	var apiKey v1.ApiKey
+	keyDb := d.DB.WithContext(ctx).
+		Table(v1.GetApiKeyTableName()).
+		Where("key_hash = ?", keyHash).
+		Limit(1)
+	if err := keyDb.Scan(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	if apiKey.ID == 0 {
+		return nil, nil
+	}
+	return &apiKey, nil
+}
+
+// GetApiKey 根据id查询API key，不存在时返回nil
+func (d *Dao) GetApiKey(ctx context.Context, id int64) (*v1.ApiKey, error) {
+	var apiKey v1.ApiKey
+	keyDb := d.DB.WithContext(ctx).
+		Table(v1.GetApiKeyTableName()).
+		Where("id = ?", id).
+		Limit(1)
+	if err := keyDb.Scan(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	if apiKey.ID == 0 {
+		return nil, nil
+	}
+	return &apiKey, nil
+}
+
+// ListApiKeys 按创建顺序倒序获取所有API key
+func (d *Dao) ListApiKeys(ctx context.Context) ([]*v1.ApiKey, error) {
+	var items []*v1.ApiKey
+	if err := d.DB.WithContext(ctx).
+		Table(v1.GetApiKeyTableName()).
+		Order("id DESC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdateApiKeyStatus 修改API key的状态
+func (d *Dao) UpdateApiKeyStatus(ctx context.Context, id int64, status int, updater string) error {
+	return d.DB.WithContext(ctx).
+		Table(v1.GetApiKeyTableName()).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"update_time": time.Now().Unix(),
+			"updater":     updater,
+		}).Error
+}
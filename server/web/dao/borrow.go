@@ -19,3 +19,24 @@ func (d *Dao) GetBorrowData(ctx context.Context, collateralAsset int) (*v1.Colla
 	}
 	return &newItem, nil
 }
+
+// GetLatestCollaterals 获取各抵押代币最新一条快照，tokenAddresses为空时返回全部抵押代币，按类型排序
+func (d *Dao) GetLatestCollaterals(ctx context.Context, tokenAddresses []string) ([]*v1.Collateral, error) {
+	var items []*v1.Collateral
+	latest := d.DB.WithContext(ctx).
+		Table(v1.GetCollateralTableName()).
+		Select("MAX(id)")
+	if len(tokenAddresses) > 0 {
+		latest = latest.Where("token_address IN ?", tokenAddresses)
+	}
+	latest = latest.Group("type")
+	borrowDb := d.DB.WithContext(ctx).
+		Table(v1.GetCollateralTableName()).
+		Select("id, token_address, type, borrowed, borrowable, utilization_rate, interest_rate, liquidation_threshold, collateralization_rate, block_number, tx_hash, log_index, create_time, update_time, creator, updater").
+		Where("id IN (?)", latest).
+		Order("type ASC")
+	if err := borrowDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
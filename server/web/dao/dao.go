@@ -4,6 +4,8 @@ import (
 	"aave_web/stores/xkv"
 	"context"
 	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
 )
 
 // Dao is show dao.
@@ -20,3 +22,21 @@ func New(ctx context.Context, db *gorm.DB, kvStore *xkv.Store) *Dao {
 		KvStore: kvStore,
 	}
 }
+
+// Ping 检查数据库连接是否可用
+func (d *Dao) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return errors.Wrap(err, "failed on get sql db")
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close 关闭数据库连接池
+func (d *Dao) Close() error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return errors.Wrap(err, "failed on get sql db")
+	}
+	return sqlDB.Close()
+}
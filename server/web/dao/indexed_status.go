@@ -0,0 +1,24 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+)
+
+// GetIndexedStatus 获取链的事件同步进度，chainID为0时取第一条，没有记录时返回nil
+func (d *Dao) GetIndexedStatus(ctx context.Context, chainID int64) (*v1.IndexedStatus, error) {
+	var status v1.IndexedStatus
+	db := d.DB.WithContext(ctx).
+		Table(v1.GetIndexedStatusTableName()).
+		Select("id, chain_id, last_indexed_block, last_indexed_time")
+	if chainID > 0 {
+		db = db.Where("chain_id = ?", chainID)
+	}
+	if err := db.Order("id ASC").Limit(1).Scan(&status).Error; err != nil {
+		return nil, err
+	}
+	if status.ID == 0 {
+		return nil, nil
+	}
+	return &status, nil
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+)
+
+// GetInterestIndexBefore 获取指数最后更新时间不晚于ts的最新一条指数快照
+func (d *Dao) GetInterestIndexBefore(ctx context.Context, ts int64) (*v1.InterestIndex, error) {
+	var newItem v1.InterestIndex
+	indexDb := d.DB.WithContext(ctx).
+		Table(v1.GetInterestIndexTableName()).
+		Where("last_update_timestamp <= ?", ts).
+		Order("block_number DESC").
+		Limit(1)
+	if err := indexDb.Scan(&newItem).Error; err != nil {
+		return nil, err
+	}
+	if newItem.ID == 0 {
+		return nil, nil
+	}
+	return &newItem, nil
+}
+
+// GetInterestIndexAfter 获取指数最后更新时间不早于ts的最早一条指数快照
+func (d *Dao) GetInterestIndexAfter(ctx context.Context, ts int64) (*v1.InterestIndex, error) {
+	var newItem v1.InterestIndex
+	indexDb := d.DB.WithContext(ctx).
+		Table(v1.GetInterestIndexTableName()).
+		Where("last_update_timestamp >= ?", ts).
+		Order("block_number ASC").
+		Limit(1)
+	if err := indexDb.Scan(&newItem).Error; err != nil {
+		return nil, err
+	}
+	if newItem.ID == 0 {
+		return nil, nil
+	}
+	return &newItem, nil
+}
+
+// GetInterestIndexAtBlock 获取不晚于blockNumber的最新一条指数快照
+func (d *Dao) GetInterestIndexAtBlock(ctx context.Context, blockNumber int64) (*v1.InterestIndex, error) {
+	var newItem v1.InterestIndex
+	indexDb := d.DB.WithContext(ctx).
+		Table(v1.GetInterestIndexTableName()).
+		Where("block_number <= ?", blockNumber).
+		Order("block_number DESC").
+		Limit(1)
+	if err := indexDb.Scan(&newItem).Error; err != nil {
+		return nil, err
+	}
+	if newItem.ID == 0 {
+		return nil, nil
+	}
+	return &newItem, nil
+}
@@ -19,3 +19,18 @@ func (d *Dao) GetLendData(ctx context.Context) (*v1.Lend, error) {
 	}
 	return &newItem, nil
 }
+
+// GetLatestLendTime 获取最新一条lend快照的时间，即最近一次池子状态变化事件所在区块的时间，没有快照时返回0
+func (d *Dao) GetLatestLendTime(ctx context.Context) (int64, error) {
+	var newItem v1.Lend
+	lendDb := d.DB.WithContext(ctx).
+		Table(v1.GetLendTableName()).
+		Select("id, create_time").
+		Where("type =?", 0).
+		Order("id DESC").
+		Limit(1)
+	if err := lendDb.Scan(&newItem).Error; err != nil {
+		return 0, err
+	}
+	return int64(newItem.CreateTime), nil
+}
@@ -0,0 +1,109 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// LiquidationFilter 清算记录查询条件，零值表示不过滤
+type LiquidationFilter struct {
+	Borrower        string
+	Liquidator      string
+	CollateralToken string
+	FromTime        int64
+	ToTime          int64
+	CursorBlock     int64 // 游标，只返回 (block_number, log_index) 小于游标的记录
+	CursorLogIndex  int
+	Limit           int
+}
+
+// GetLiquidations 按链上顺序倒序分页获取清算记录
+func (d *Dao) GetLiquidations(ctx context.Context, filter *LiquidationFilter) ([]*v1.Liquidation, error) {
+	var items []*v1.Liquidation
+	liquidationDb := d.liquidationQuery(ctx, filter)
+	if filter.CursorBlock > 0 {
+		liquidationDb = liquidationDb.Where("(block_number < ? OR (block_number = ? AND log_index < ?))",
+			filter.CursorBlock, filter.CursorBlock, filter.CursorLogIndex)
+	}
+	liquidationDb = liquidationDb.Order("block_number DESC, log_index DESC").Limit(filter.Limit)
+	if err := liquidationDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetLiquidationStats 按清算人或抵押代币分组统计清算次数和金额，按获得的抵押物美元价值倒序
+// groupBy 只能是 liquidator 或 collateral_token
+func (d *Dao) GetLiquidationStats(ctx context.Context, groupBy string, filter *LiquidationFilter) ([]*v1.LiquidationStats, error) {
+	var items []*v1.LiquidationStats
+	liquidationDb := d.liquidationQuery(ctx, filter).
+		Select(groupBy + " AS `key`, COUNT(*) AS count, " +
+			"CAST(SUM(CAST(liquidated_amount AS DECIMAL(65,0))) AS CHAR) AS liquidated_amount, " +
+			"CAST(SUM(CAST(collateral_seized AS DECIMAL(65,0))) AS CHAR) AS collateral_seized, " +
+			"CAST(SUM(CAST(bonus_amount AS DECIMAL(65,0))) AS CHAR) AS bonus_amount, " +
+			"CAST(SUM(CAST(debt_value_usd AS DECIMAL(65,0))) AS CHAR) AS debt_value_usd, " +
+			"CAST(SUM(CAST(seized_value_usd AS DECIMAL(65,0))) AS CHAR) AS seized_value_usd, " +
+			"CAST(SUM(CAST(bonus_value_usd AS DECIMAL(65,0))) AS CHAR) AS bonus_value_usd, " +
+			"MAX(block_time) AS last_block_time").
+		Group(groupBy).
+		Order("SUM(CAST(seized_value_usd AS DECIMAL(65,0))) DESC").
+		Limit(filter.Limit)
+	if err := liquidationDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetRecentLiquidationsBatch 批量获取多个借款人各自最近limit条被清算记录
+func (d *Dao) GetRecentLiquidationsBatch(ctx context.Context, borrowers []string, limit int) ([]*v1.Liquidation, error) {
+	var items []*v1.Liquidation
+	ranked := d.DB.WithContext(ctx).
+		Table(v1.GetLiquidationTableName()).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY borrower ORDER BY block_number DESC, log_index DESC) AS rn").
+		Where("borrower IN ?", borrowers)
+	liquidationDb := d.DB.WithContext(ctx).
+		Table("(?) AS ranked", ranked).
+		Where("rn <= ?", limit).
+		Order("borrower, rn")
+	if err := liquidationDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetLiquidationsAfter 按链上顺序获取 (afterBlock, afterLogIndex) 之后、toBlock及之前的清算记录，最多limit条
+func (d *Dao) GetLiquidationsAfter(ctx context.Context, afterBlock int64, afterLogIndex int, toBlock int64, limit int) ([]*v1.Liquidation, error) {
+	var items []*v1.Liquidation
+	liquidationDb := d.DB.WithContext(ctx).
+		Table(v1.GetLiquidationTableName()).
+		Where("block_number > ? OR (block_number = ? AND log_index > ?)", afterBlock, afterBlock, afterLogIndex).
+		Where("block_number <= ?", toBlock).
+		Order("block_number, log_index").
+		Limit(limit)
+	if err := liquidationDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (d *Dao) liquidationQuery(ctx context.Context, filter *LiquidationFilter) *gorm.DB {
+	liquidationDb := d.DB.WithContext(ctx).Table(v1.GetLiquidationTableName())
+	if filter.Borrower != "" {
+		liquidationDb = liquidationDb.Where("borrower = ?", filter.Borrower)
+	}
+	if filter.Liquidator != "" {
+		liquidationDb = liquidationDb.Where("liquidator = ?", filter.Liquidator)
+	}
+	if filter.CollateralToken != "" {
+		liquidationDb = liquidationDb.Where("collateral_token = ?", filter.CollateralToken)
+	}
+	if filter.FromTime > 0 {
+		liquidationDb = liquidationDb.Where("block_time >= ?", filter.FromTime)
+	}
+	if filter.ToTime > 0 {
+		liquidationDb = liquidationDb.Where("block_time <= ?", filter.ToTime)
+	}
+	return liquidationDb
+}
@@ -0,0 +1,63 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+)
+
+// PoolParamFilter 风险参数历史查询条件，零值表示不过滤
+type PoolParamFilter struct {
+	ParamName   string
+	Scope       string
+	AlertedOnly bool
+	FromTime    int64
+	ToTime      int64
+	CursorID    int64 // 游标，只返回id小于游标的记录
+	Limit       int
+}
+
+// GetLatestPoolParams 获取各风险参数的最新版本
+func (d *Dao) GetLatestPoolParams(ctx context.Context) ([]*v1.PoolParam, error) {
+	var items []*v1.PoolParam
+	latestVersions := d.DB.WithContext(ctx).
+		Table(v1.GetPoolParamTableName()).
+		Select("param_name, scope, MAX(version)").
+		Group("param_name, scope")
+	paramDb := d.DB.WithContext(ctx).
+		Table(v1.GetPoolParamTableName()).
+		Where("(param_name, scope, version) IN (?)", latestVersions).
+		Order("scope ASC, param_name ASC")
+	if err := paramDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetPoolParamHistory 按记录顺序倒序分页获取风险参数的历史版本
+func (d *Dao) GetPoolParamHistory(ctx context.Context, filter *PoolParamFilter) ([]*v1.PoolParam, error) {
+	var items []*v1.PoolParam
+	paramDb := d.DB.WithContext(ctx).Table(v1.GetPoolParamTableName())
+	if filter.ParamName != "" {
+		paramDb = paramDb.Where("param_name = ?", filter.ParamName)
+	}
+	if filter.Scope != "" {
+		paramDb = paramDb.Where("scope = ?", filter.Scope)
+	}
+	if filter.AlertedOnly {
+		paramDb = paramDb.Where("alerted = ?", true)
+	}
+	if filter.FromTime > 0 {
+		paramDb = paramDb.Where("block_time >= ?", filter.FromTime)
+	}
+	if filter.ToTime > 0 {
+		paramDb = paramDb.Where("block_time <= ?", filter.ToTime)
+	}
+	if filter.CursorID > 0 {
+		paramDb = paramDb.Where("id < ?", filter.CursorID)
+	}
+	paramDb = paramDb.Order("id DESC").Limit(filter.Limit)
+	if err := paramDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// PoolTransactionFilter pool合约交易查询条件，零值表示不过滤
+type PoolTransactionFilter struct {
+	Method        string
+	FromAddress   string
+	FromTime      int64
+	ToTime        int64
+	CursorBlock   int64 // 游标，只返回 (block_number, tx_index) 小于游标的记录
+	CursorTxIndex int
+	Limit         int
+}
+
+// GetPoolTransactionStats 按方法分组统计交易数量、失败数量和gas消耗，按交易数量倒序
+func (d *Dao) GetPoolTransactionStats(ctx context.Context, filter *PoolTransactionFilter) ([]*v1.PoolTransactionStats, error) {
+	var items []*v1.PoolTransactionStats
+	txDb := d.poolTransactionQuery(ctx, filter).
+		Select("method, COUNT(*) AS count, " +
+			"CAST(SUM(CASE WHEN status = 0 THEN 1 ELSE 0 END) AS SIGNED) AS failed_count, " +
+			"CAST(ROUND(AVG(gas_used)) AS SIGNED) AS avg_gas_used, " +
+			"CAST(ROUND(AVG(CAST(effective_gas_price AS DECIMAL(65,0)))) AS CHAR) AS avg_gas_price, " +
+			"CAST(ROUND(AVG(CAST(fee AS DECIMAL(65,0)))) AS CHAR) AS avg_fee, " +
+			"CAST(SUM(CAST(fee AS DECIMAL(65,0))) AS CHAR) AS total_fee, " +
+			"MAX(block_time) AS last_block_time").
+		Group("method").
+		Order("count DESC")
+	if err := txDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetFailedPoolTransactions 按链上顺序倒序分页获取失败的pool合约交易
+func (d *Dao) GetFailedPoolTransactions(ctx context.Context, filter *PoolTransactionFilter) ([]*v1.PoolTransaction, error) {
+	var items []*v1.PoolTransaction
+	txDb := d.poolTransactionQuery(ctx, filter).Where("status = ?", 0)
+	if filter.CursorBlock > 0 {
+		txDb = txDb.Where("(block_number < ? OR (block_number = ? AND tx_index < ?))",
+			filter.CursorBlock, filter.CursorBlock, filter.CursorTxIndex)
+	}
+	txDb = txDb.Order("block_number DESC, tx_index DESC").Limit(filter.Limit)
+	if err := txDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (d *Dao) poolTransactionQuery(ctx context.Context, filter *PoolTransactionFilter) *gorm.DB {
+	txDb := d.DB.WithContext(ctx).Table(v1.GetPoolTransactionTableName())
+	if filter.Method != "" {
+		txDb = txDb.Where("method = ?", filter.Method)
+	}
+	if filter.FromAddress != "" {
+		txDb = txDb.Where("from_address = ?", filter.FromAddress)
+	}
+	if filter.FromTime > 0 {
+		txDb = txDb.Where("block_time >= ?", filter.FromTime)
+	}
+	if filter.ToTime > 0 {
+		txDb = txDb.Where("block_time <= ?", filter.ToTime)
+	}
+	return txDb
+}
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+)
+
+// TokenPriceFilter 价格记录查询条件，零值表示不过滤
+type TokenPriceFilter struct {
+	TokenAddress string
+	FromTime     int64
+	ToTime       int64
+	CursorBlock  int64 // 游标，只返回 block_number 小于游标的记录
+	Limit        int
+}
+
+// GetTokenPrices 按区块倒序分页获取代币的价格记录
+func (d *Dao) GetTokenPrices(ctx context.Context, filter *TokenPriceFilter) ([]*v1.TokenPrice, error) {
+	var items []*v1.TokenPrice
+	priceDb := d.DB.WithContext(ctx).
+		Table(v1.GetTokenPriceTableName()).
+		Where("token_address = ?", filter.TokenAddress)
+	if filter.FromTime > 0 {
+		priceDb = priceDb.Where("block_time >= ?", filter.FromTime)
+	}
+	if filter.ToTime > 0 {
+		priceDb = priceDb.Where("block_time <= ?", filter.ToTime)
+	}
+	if filter.CursorBlock > 0 {
+		priceDb = priceDb.Where("block_number < ?", filter.CursorBlock)
+	}
+	priceDb = priceDb.Order("block_number DESC").Limit(filter.Limit)
+	if err := priceDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetLatestTokenPrices 批量获取多个代币的最新价格，没有价格记录的代币不返回
+func (d *Dao) GetLatestTokenPrices(ctx context.Context, tokenAddresses []string) ([]*v1.TokenPrice, error) {
+	var items []*v1.TokenPrice
+	latest := d.DB.WithContext(ctx).
+		Table(v1.GetTokenPriceTableName()).
+		Select("MAX(id)").
+		Where("token_address IN ?", tokenAddresses).
+		Group("token_address")
+	priceDb := d.DB.WithContext(ctx).
+		Table(v1.GetTokenPriceTableName()).
+		Where("id IN (?)", latest)
+	if err := priceDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetLatestTokenPriceID 最新一条价格记录的id，有新的价格记录时变大，没有记录时返回0
+func (d *Dao) GetLatestTokenPriceID(ctx context.Context) (int64, error) {
+	var id int64
+	priceDb := d.DB.WithContext(ctx).
+		Table(v1.GetTokenPriceTableName()).
+		Select("COALESCE(MAX(id), 0)")
+	if err := priceDb.Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,124 @@
+package dao
+
+import (
+	v1 "aave_web/types/v1"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// GetUserLendActivities 按链上顺序获取用户的存款、取款记录
+func (d *Dao) GetUserLendActivities(ctx context.Context, userAddress string) ([]*v1.UserActivity, error) {
+	var items []*v1.UserActivity
+	activityDb := d.DB.WithContext(ctx).
+		Table(v1.GetUserActivityTableName()).
+		Where("user_address = ?", userAddress).
+		Where("action IN ?", []string{v1.ActionLendDeposit, v1.ActionLendWithdraw}).
+		Order("block_number ASC, log_index ASC, id ASC")
+	if err := activityDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetBorrowerAddresses 按地址顺序分页获取借过款的用户地址，after为上一页最后一个地址
+func (d *Dao) GetBorrowerAddresses(ctx context.Context, after string, limit int) ([]string, error) {
+	var addresses []string
+	activityDb := d.DB.WithContext(ctx).
+		Table(v1.GetUserActivityTableName()).
+		Distinct("user_address").
+		Where("action = ?", v1.ActionBorrow).
+		Where("user_address > ?", after).
+		Order("user_address").
+		Limit(limit)
+	if err := activityDb.Pluck("user_address", &addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// ActivityFilter 用户操作记录查询条件，零值表示不过滤
+type ActivityFilter struct {
+	Actions        []string
+	TokenAddress   string
+	FromBlock      int64
+	ToBlock        int64
+	FromTime       int64
+	ToTime         int64
+	CursorBlock    int64 // 游标，只返回 (block_number, log_index, id) 小于游标的记录
+	CursorLogIndex int
+	CursorID       int64 // 同一条日志对应多条记录时（借款人即清算人）按id区分，为0时跳过游标位置的全部记录
+	Limit          int
+}
+
+// GetUserActivities 按链上顺序倒序分页获取用户的操作记录
+func (d *Dao) GetUserActivities(ctx context.Context, userAddress string, filter *ActivityFilter) ([]*v1.UserActivity, error) {
+	var items []*v1.UserActivity
+	activityDb := d.activityQuery(ctx, filter).
+		Where("user_address = ?", userAddress).
+		Order("block_number DESC, log_index DESC, id DESC").
+		Limit(filter.Limit)
+	if err := activityDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetActivities 按链上顺序倒序分页获取全部用户的操作记录
+func (d *Dao) GetActivities(ctx context.Context, filter *ActivityFilter) ([]*v1.UserActivity, error) {
+	var items []*v1.UserActivity
+	activityDb := d.activityQuery(ctx, filter).
+		Order("block_number DESC, log_index DESC, id DESC").
+		Limit(filter.Limit)
+	if err := activityDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetRecentUserActivitiesBatch 批量获取多个用户各自最近limit条操作记录，actions为空时不过滤操作类型
+func (d *Dao) GetRecentUserActivitiesBatch(ctx context.Context, userAddresses []string, actions []string, limit int) ([]*v1.UserActivity, error) {
+	var items []*v1.UserActivity
+	ranked := d.DB.WithContext(ctx).
+		Table(v1.GetUserActivityTableName()).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY user_address ORDER BY block_number DESC, log_index DESC, id DESC) AS rn").
+		Where("user_address IN ?", userAddresses)
+	if len(actions) > 0 {
+		ranked = ranked.Where("action IN ?", actions)
+	}
+	activityDb := d.DB.WithContext(ctx).
+		Table("(?) AS ranked", ranked).
+		Where("rn <= ?", limit).
+		Order("user_address, rn")
+	if err := activityDb.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (d *Dao) activityQuery(ctx context.Context, filter *ActivityFilter) *gorm.DB {
+	activityDb := d.DB.WithContext(ctx).Table(v1.GetUserActivityTableName())
+	if len(filter.Actions) > 0 {
+		activityDb = activityDb.Where("action IN ?", filter.Actions)
+	}
+	if filter.TokenAddress != "" {
+		activityDb = activityDb.Where("token_address = ?", filter.TokenAddress)
+	}
+	if filter.FromBlock > 0 {
+		activityDb = activityDb.Where("block_number >= ?", filter.FromBlock)
+	}
+	if filter.ToBlock > 0 {
+		activityDb = activityDb.Where("block_number <= ?", filter.ToBlock)
+	}
+	if filter.FromTime > 0 {
+		activityDb = activityDb.Where("block_time >= ?", filter.FromTime)
+	}
+	if filter.ToTime > 0 {
+		activityDb = activityDb.Where("block_time <= ?", filter.ToTime)
+	}
+	if filter.CursorBlock > 0 {
+		activityDb = activityDb.Where("(block_number < ? OR (block_number = ? AND log_index < ?) OR (block_number = ? AND log_index = ? AND id < ?))",
+			filter.CursorBlock, filter.CursorBlock, filter.CursorLogIndex, filter.CursorBlock, filter.CursorLogIndex, filter.CursorID)
+	}
+	return activityDb
+}
@@ -1,6 +1,8 @@
 package xgrpc
 
 import (
+	logging "aave_web/logger"
+	"aave_web/logger/xzap"
 	"context"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -1,6 +1,9 @@
 package xgrpc
 
 import (
+	"aave_web/errcode"
+	logging "aave_web/logger"
+	"aave_web/logger/xzap"
 	"bytes"
 	"context"
 	"fmt"
@@ -1,11 +1,15 @@
 package main
 
 import (
-	"aave_web/api/router"
 	"aave_web/app"
 	"aave_web/config"
+	"aave_web/logger/xzap"
 	"aave_web/service"
+	"context"
 	"flag"
+	"os"
+
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -21,11 +25,13 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	// Initialize router
-	r := router.NewRouter(serverCtx)
-	platform, err := app.NewPlatform(c, r, serverCtx)
+	platform, err := app.NewPlatform(c, serverCtx)
 	if err != nil {
 		panic(err)
 	}
-	platform.Start()
+	// 收到SIGTERM后优雅退出，路由创建或启动失败时以非0状态码退出
+	if err := platform.Start(); err != nil {
+		xzap.WithContext(context.Background()).Error("aave_web exited", zap.Error(err))
+		os.Exit(1)
+	}
 }
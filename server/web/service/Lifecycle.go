@@ -0,0 +1,99 @@
+package service
+
+import (
+	"aave_web/logger/xzap"
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultShutdownTimeout 未配置时优雅退出的总超时时间
+const defaultShutdownTimeout = 30 * time.Second
+
+// StopFunc 停止一个组件，ctx 到期时应尽快返回
+type StopFunc func(ctx context.Context) error
+
+type stopHook struct {
+	name string
+	stop StopFunc
+}
+
+// Lifecycle 服务的生命周期管理，收到SIGTERM、SIGINT或主动调用Stop后，按注册的逆序依次停止各组件
+// 先注册的组件（数据库、Redis）最后停止，保证其它组件退出前依赖仍然可用
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []stopHook
+	timeout time.Duration
+
+	stopOnce sync.Once
+	stopping chan struct{}
+}
+
+func NewLifecycle(timeout time.Duration) *Lifecycle {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	return &Lifecycle{
+		timeout:  timeout,
+		stopping: make(chan struct{}),
+	}
+}
+
+// OnStop 注册组件的停止函数
+func (l *Lifecycle) OnStop(name string, stop StopFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, stopHook{name: name, stop: stop})
+}
+
+// Stop 触发退出，可重复调用，如HTTP端口监听失败时
+func (l *Lifecycle) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopping)
+	})
+}
+
+// Stopping 开始退出后关闭的通道
+func (l *Lifecycle) Stopping() <-chan struct{} {
+	return l.stopping
+}
+
+// Wait 阻塞直到收到退出信号或调用Stop，然后在超时时间内依次停止所有组件
+func (l *Lifecycle) Wait() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		xzap.WithContext(context.Background()).Info("receive signal, shutting down", zap.String("signal", sig.String()))
+		l.Stop()
+	case <-l.stopping:
+	}
+	l.shutdown()
+}
+
+// shutdown 按注册的逆序停止组件，所有组件共用一个超时时间，单个组件失败不影响后续组件
+func (l *Lifecycle) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	l.mu.Lock()
+	hooks := make([]stopHook, len(l.hooks))
+	copy(hooks, l.hooks)
+	l.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		start := time.Now()
+		if err := hooks[i].stop(ctx); err != nil {
+			xzap.WithContext(ctx).Error("failed on stop component", zap.String("component", hooks[i].name), zap.Error(err))
+			continue
+		}
+		xzap.WithContext(ctx).Info("component stopped", zap.String("component", hooks[i].name), zap.Duration("cost", time.Since(start)))
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	logging "aave_web/logger"
+	"aave_web/logger/xzap"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	// 停止组件时使用全局日志，测试中输出到控制台
+	if _, err := xzap.SetUp(logging.LogConf{Mode: "console", Path: os.TempDir(), Level: "error"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestLifecycleStopOrder(t *testing.T) {
+	lifecycle := NewLifecycle(time.Second)
+	var stopped []string
+	for _, name := range []string{"db", "grpc", "http"} {
+		name := name
+		lifecycle.OnStop(name, func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+	// 单个组件失败不影响后续组件
+	lifecycle.OnStop("websocket", func(ctx context.Context) error {
+		stopped = append(stopped, "websocket")
+		return errors.New("close failed")
+	})
+
+	lifecycle.Stop()
+	lifecycle.Stop()
+	lifecycle.Wait()
+
+	// 按注册的逆序停止，数据库最后停止
+	assert.Equal(t, []string{"websocket", "http", "grpc", "db"}, stopped)
+	select {
+	case <-lifecycle.Stopping():
+	default:
+		t.Fatal("stopping channel should be closed")
+	}
+}
+
+func TestLifecycleSharedTimeout(t *testing.T) {
+	lifecycle := NewLifecycle(50 * time.Millisecond)
+	var dbErr error
+	lifecycle.OnStop("db", func(ctx context.Context) error {
+		dbErr = ctx.Err()
+		return nil
+	})
+	// 前一个组件耗尽超时时间后，后续组件拿到的ctx已经到期
+	lifecycle.OnStop("http", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	lifecycle.Stop()
+	lifecycle.Wait()
+
+	assert.ErrorIs(t, dbErr, context.DeadlineExceeded)
+}
@@ -1,16 +1,19 @@
 package service
 
 import (
+	"aave_web/chain"
 	"aave_web/config"
 	"aave_web/dao"
 	"aave_web/logger/xzap"
 	"aave_web/stores/gdb"
 	"aave_web/stores/xkv"
+	"aave_web/xhttp"
 	"context"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/kv"
 	"github.com/zeromicro/go-zero/core/stores/redis"
 	"gorm.io/gorm"
+	"time"
 )
 
 type ServerCtx struct {
@@ -19,12 +22,17 @@ type ServerCtx struct {
 	//ImageMgr image.ImageManager
 	Dao     *dao.Dao
 	KvStore *xkv.Store
+	Pool    *chain.PoolReader
+	Feed    *StateFeed
+	Logger  *xzap.ZapLogger
+	// Lifecycle 各组件在此注册停止函数，收到退出信号后按注册的逆序停止
+	Lifecycle *Lifecycle
 }
 
 func NewServiceContext(c *config.Config) (*ServerCtx, error) {
 	var err error
 	// Log
-	_, err = xzap.SetUp(c.Log)
+	zapLogger, err := xzap.SetUp(c.Log)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +60,18 @@ func NewServiceContext(c *config.Config) (*ServerCtx, error) {
 		WithKv(store),
 		WithDao(d),
 	)
+	// chain，未配置rpc时不提供链上读取
+	if c.Chain.RpcUrl != "" {
+		serverCtx.Pool = chain.NewPoolReader(xhttp.NewRPCClient(c.Chain.RpcUrl), c.Chain.AavePoolAddress)
+	}
+	serverCtx.Feed = NewStateFeed()
+	serverCtx.Logger = zapLogger
 	serverCtx.C = c
+	// 数据库最先注册、最后关闭，go-zero的redis客户端由进程级连接池管理，没有关闭接口，随进程退出释放
+	serverCtx.Lifecycle = NewLifecycle(time.Duration(c.Api.ShutdownTimeout) * time.Second)
+	serverCtx.Lifecycle.OnStop("mysql", func(ctx context.Context) error {
+		return d.Close()
+	})
 	return serverCtx, nil
 }
 
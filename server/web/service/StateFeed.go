@@ -0,0 +1,74 @@
+package service
+
+import (
+	v1 "aave_web/types/v1"
+	"sync"
+)
+
+// StateChange 池子状态变化，调度服务通知状态变化后发布
+type StateChange struct {
+	Type string
+	Lend *v1.Lend
+	Time int64
+}
+
+// StateFeed 池子状态变化的订阅分发，供gRPC流等长连接使用
+type StateFeed struct {
+	mu     sync.RWMutex
+	subs   map[chan *StateChange]struct{}
+	closed bool
+}
+
+func NewStateFeed() *StateFeed {
+	return &StateFeed{subs: make(map[chan *StateChange]struct{})}
+}
+
+// Subscribe 订阅状态变化，buffer 为缓冲区大小，用完后需要调用 Unsubscribe
+// 通道被关闭表示服务正在退出，订阅方应结束长连接
+func (f *StateFeed) Subscribe(buffer int) chan *StateChange {
+	ch := make(chan *StateChange, buffer)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(ch)
+		return ch
+	}
+	f.subs[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe 取消订阅并关闭通道
+func (f *StateFeed) Unsubscribe(ch chan *StateChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.subs[ch]; ok {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
+
+// Publish 发布状态变化，订阅者缓冲区已满时丢弃，不阻塞发布方
+func (f *StateFeed) Publish(change *StateChange) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for ch := range f.subs {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// Close 关闭所有订阅通道，之后的发布不再分发，退出时让长连接尽快结束
+func (f *StateFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for ch := range f.subs {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetUserActivities 分页获取用户的存取款、抵押物、借还款和清算记录，按链上顺序倒序
+func GetUserActivities(ctx context.Context, svcCtx *service.ServerCtx, userAddress string, filter *dao.ActivityFilter) (*v1.ActivityPage, error) {
+	items, err := svcCtx.Dao.GetUserActivities(ctx, userAddress, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query user activities")
+	}
+	page := &v1.ActivityPage{Items: items}
+	if len(items) == filter.Limit {
+		page.NextCursor = EncodeActivityCursor(items[len(items)-1])
+	}
+	return page, nil
+}
+
+// GetActivities 分页获取全部用户的操作记录，按链上顺序倒序
+func GetActivities(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.ActivityFilter) (*v1.ActivityPage, error) {
+	items, err := svcCtx.Dao.GetActivities(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query activities")
+	}
+	page := &v1.ActivityPage{Items: items}
+	if len(items) == filter.Limit {
+		page.NextCursor = EncodeActivityCursor(items[len(items)-1])
+	}
+	return page, nil
+}
+
+// GetRecentUserActivitiesBatch 批量获取多个用户各自最近的操作记录，按用户地址分组
+func GetRecentUserActivitiesBatch(ctx context.Context, svcCtx *service.ServerCtx, userAddresses []string, actions []string, limit int) (map[string][]*v1.UserActivity, error) {
+	items, err := svcCtx.Dao.GetRecentUserActivitiesBatch(ctx, userAddresses, actions, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query recent user activities")
+	}
+	grouped := make(map[string][]*v1.UserActivity, len(userAddresses))
+	for _, item := range items {
+		grouped[item.UserAddress] = append(grouped[item.UserAddress], item)
+	}
+	return grouped, nil
+}
+
+// EncodeActivityCursor 操作记录分页的游标，格式为 <block_number>-<log_index>-<id>
+// 借款人和清算人是同一地址时，同一条清算日志对应两条记录，用id区分
+func EncodeActivityCursor(item *v1.UserActivity) string {
+	return fmt.Sprintf("%d-%d-%d", item.BlockNumber, item.LogIndex, item.ID)
+}
+
+// DecodeActivityCursor 解析 EncodeActivityCursor 生成的游标，兼容不带id的旧游标，此时id为0
+func DecodeActivityCursor(cursor string) (int64, int, int64, error) {
+	parts := strings.Split(cursor, "-")
+	var id int64
+	if len(parts) == 3 {
+		var err error
+		id, err = strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || id <= 0 {
+			return 0, 0, 0, errors.New("invalid cursor id")
+		}
+		parts = parts[:2]
+	}
+	blockNumber, logIndex, err := DecodeLogCursor(strings.Join(parts, "-"))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return blockNumber, logIndex, id, nil
+}
+
+// EncodeLogCursor 按链上日志位置分页的游标，格式为 <block_number>-<log_index>
+func EncodeLogCursor(blockNumber int64, logIndex int) string {
+	return fmt.Sprintf("%d-%d", blockNumber, logIndex)
+}
+
+// DecodeLogCursor 解析 EncodeLogCursor 生成的游标
+func DecodeLogCursor(cursor string) (int64, int, error) {
+	parts := strings.Split(cursor, "-")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	blockNumber, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || blockNumber <= 0 {
+		return 0, 0, errors.New("invalid cursor block number")
+	}
+	logIndex, err := strconv.Atoi(parts[1])
+	if err != nil || logIndex < 0 {
+		return 0, 0, errors.New("invalid cursor log index")
+	}
+	return blockNumber, logIndex, nil
+}
@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiKeyPrefix 明文API key前缀
+	apiKeyPrefix = "ak_"
+	// apiKeySecretBytes 明文API key前缀之后的随机字节数，十六进制编码后为48个字符
+	apiKeySecretBytes = 24
+	// apiKeyCacheSeconds API key查询结果的缓存时间，吊销时主动清除
+	apiKeyCacheSeconds = 60
+	// apiKeyUsageDays 用量计数保留天数
+	apiKeyUsageDays = 31
+	// apiKeyUsageDateLayout 用量计数的日期格式
+	apiKeyUsageDateLayout = "20060102"
+)
+
+// HashApiKey 明文API key的sha256哈希，数据库只保存哈希
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyCacheKey(keyHash string) string {
+	return "aave:apikey:hash:" + keyHash
+}
+
+func apiKeyUsageKey(id int64, date string) string {
+	return fmt.Sprintf("aave:apikey:usage:%d:%s", id, date)
+}
+
+// CreateApiKey 生成新的API key，rate、burst为0时使用路由配额，明文只在返回值中出现一次
+func CreateApiKey(ctx context.Context, svcCtx *service.ServerCtx, name, owner string, rate float64, burst int, creator string) (*v1.ApiKeyCreated, error) {
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "failed on generate api key")
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+	now := time.Now().Unix()
+	apiKey := &v1.ApiKey{
+		Name:       name,
+		Owner:      owner,
+		KeyPrefix:  key[:len(apiKeyPrefix)+8],
+		KeyHash:    HashApiKey(key),
+		Rate:       rate,
+		Burst:      burst,
+		Status:     v1.ApiKeyStatusActive,
+		CreateTime: now,
+		UpdateTime: now,
+		Creator:    creator,
+		Updater:    creator,
+	}
+	if err := svcCtx.Dao.CreateApiKey(ctx, apiKey); err != nil {
+		return nil, errors.Wrap(err, "failed on create api key")
+	}
+	return &v1.ApiKeyCreated{ApiKey: apiKey, Key: key}, nil
+}
+
+// ValidApiKeyFormat 明文API key是否为 CreateApiKey 生成的格式：ak_ 加48位小写十六进制
+func ValidApiKeyFormat(key string) bool {
+	secret, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok || len(secret) != apiKeySecretBytes*2 {
+		return false
+	}
+	for _, ch := range secret {
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetActiveApiKey 根据明文查询可用的API key，不存在或已吊销时返回nil，查询结果短暂缓存
+// 格式不正确的key直接返回nil，不查询也不缓存，避免随意构造的key写满缓存
+func GetActiveApiKey(ctx context.Context, svcCtx *service.ServerCtx, key string) (*v1.ApiKey, error) {
+	if !ValidApiKeyFormat(key) {
+		return nil, nil
+	}
+	keyHash := HashApiKey(key)
+	var apiKey v1.ApiKey
+	err := svcCtx.KvStore.ReadOrGet(apiKeyCacheKey(keyHash), &apiKey, func() (interface{}, error) {
+		found, err := svcCtx.Dao.GetApiKeyByHash(ctx, keyHash)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			// 不存在的key也缓存，避免随机key穿透到数据库
+			return &v1.ApiKey{}, nil
+		}
+		return found, nil
+	}, apiKeyCacheSeconds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query api key")
+	}
+	if apiKey.ID == 0 || apiKey.Status != v1.ApiKeyStatusActive {
+		return nil, nil
+	}
+	return &apiKey, nil
+}
+
+// ListApiKeys 获取所有API key
+func ListApiKeys(ctx context.Context, svcCtx *service.ServerCtx) ([]*v1.ApiKey, error) {
+	items, err := svcCtx.Dao.ListApiKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query api keys")
+	}
+	return items, nil
+}
+
+// RevokeApiKey 吊销API key并清除缓存，key不存在时返回false
+func RevokeApiKey(ctx context.Context, svcCtx *service.ServerCtx, id int64, updater string) (bool, error) {
+	apiKey, err := svcCtx.Dao.GetApiKey(ctx, id)
+	if err != nil {
+		return false, errors.Wrap(err, "failed on query api key")
+	}
+	if apiKey == nil {
+		return false, nil
+	}
+	if err := svcCtx.Dao.UpdateApiKeyStatus(ctx, id, v1.ApiKeyStatusRevoked, updater); err != nil {
+		return false, errors.Wrap(err, "failed on revoke api key")
+	}
+	if _, err := svcCtx.KvStore.Del(apiKeyCacheKey(apiKey.KeyHash)); err != nil {
+		return false, errors.Wrap(err, "failed on purge api key cache")
+	}
+	return true, nil
+}
+
+// RecordApiKeyUsage 按天累计API key各路由的调用次数
+func RecordApiKeyUsage(svcCtx *service.ServerCtx, id int64, route string) error {
+	key := apiKeyUsageKey(id, time.Now().Format(apiKeyUsageDateLayout))
+	if _, err := svcCtx.KvStore.Hincrby(key, route, 1); err != nil {
+		return errors.Wrap(err, "failed on incr api key usage")
+	}
+	return svcCtx.KvStore.Expire(key, apiKeyUsageDays*24*3600)
+}
+
+// GetApiKeyUsage 获取API key最近days天每天的调用次数，按日期倒序
+func GetApiKeyUsage(svcCtx *service.ServerCtx, id int64, days int) ([]*v1.ApiKeyUsage, error) {
+	now := time.Now()
+	usages := make([]*v1.ApiKeyUsage, 0, days)
+	for i := 0; i < days; i++ {
+		date := now.AddDate(0, 0, -i).Format(apiKeyUsageDateLayout)
+		counts, err := svcCtx.KvStore.Hgetall(apiKeyUsageKey(id, date))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on query api key usage")
+		}
+		usage := &v1.ApiKeyUsage{Date: date, Routes: make(map[string]int64, len(counts))}
+		for route, count := range counts {
+			n, _ := strconv.ParseInt(count, 10, 64)
+			usage.Routes[route] = n
+			usage.Total += n
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// ApiKeyStore 限流中间件使用的API key查询和用量统计
+type ApiKeyStore struct {
+	svcCtx *service.ServerCtx
+}
+
+func NewApiKeyStore(svcCtx *service.ServerCtx) *ApiKeyStore {
+	return &ApiKeyStore{svcCtx: svcCtx}
+}
+
+func (s *ApiKeyStore) GetActiveApiKey(ctx context.Context, key string) (*v1.ApiKey, error) {
+	return GetActiveApiKey(ctx, s.svcCtx, key)
+}
+
+func (s *ApiKeyStore) RecordUsage(id int64, route string) error {
+	return RecordApiKeyUsage(s.svcCtx, id, route)
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidApiKeyFormat(t *testing.T) {
+	secret := strings.Repeat("0123456789abcdef", 3)
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"generated", apiKeyPrefix + secret, true},
+		{"empty", "", false},
+		{"prefix only", apiKeyPrefix, false},
+		{"missing prefix", secret, false},
+		{"wrong prefix", "sk_" + secret, false},
+		{"short", apiKeyPrefix + secret[:47], false},
+		{"long", apiKeyPrefix + secret + "0", false},
+		{"upper case hex", apiKeyPrefix + strings.ToUpper(secret), false},
+		{"non hex", apiKeyPrefix + secret[:47] + "g", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidApiKeyFormat(tt.key))
+		})
+	}
+}
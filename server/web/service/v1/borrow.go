@@ -15,3 +15,12 @@ func GetBorrowData(ctx context.Context, svcCtx *service.ServerCtx, collateralAss
 	}
 	return borrow, nil
 }
+
+// GetLatestCollaterals 获取抵押代币的最新快照，tokenAddresses为空时返回全部抵押代币
+func GetLatestCollaterals(ctx context.Context, svcCtx *service.ServerCtx, tokenAddresses []string) ([]*v1.Collateral, error) {
+	items, err := svcCtx.Dao.GetLatestCollaterals(ctx, tokenAddresses)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query latest collaterals")
+	}
+	return items, nil
+}
@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultChainCacheSeconds 未配置时链上读取结果的缓存时间
+const defaultChainCacheSeconds = 5
+
+// resolveBlock 未指定区块时固定到节点最新区块，保证同一次请求的结果来自同一区块，也便于前端标注数据所在区块
+func resolveBlock(svcCtx *service.ServerCtx, block int64) (int64, error) {
+	if svcCtx.Pool == nil {
+		return 0, errors.New("chain reader is not configured")
+	}
+	if block > 0 {
+		return block, nil
+	}
+	latest, err := svcCtx.Pool.BlockNumber()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed on get latest block")
+	}
+	return latest, nil
+}
+
+func chainCacheSeconds(svcCtx *service.ServerCtx) int {
+	if svcCtx.C != nil && svcCtx.C.Chain.CacheSeconds > 0 {
+		return svcCtx.C.Chain.CacheSeconds
+	}
+	return defaultChainCacheSeconds
+}
+
+// GetChainLendInfo 读取指定区块的全局存借款信息，block为0表示最新区块
+func GetChainLendInfo(svcCtx *service.ServerCtx, block int64) (*v1.ChainLendInfo, error) {
+	block, err := resolveBlock(svcCtx, block)
+	if err != nil {
+		return nil, err
+	}
+	var info v1.ChainLendInfo
+	err = svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:lend:%d", block), &info, func() (interface{}, error) {
+		words, err := svcCtx.Pool.GetTotalLendInfo(block)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.ChainLendInfo{
+			Block:           block,
+			TotalLend:       words[0].String(),
+			TotalBorrow:     words[1].String(),
+			UtilizationRate: words[2].String(),
+			InterestApy:     words[3].String(),
+		}, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read total lend info")
+	}
+	return &info, nil
+}
+
+// GetChainTokenBorrowInfo 读取指定区块某个抵押代币的借款信息
+func GetChainTokenBorrowInfo(svcCtx *service.ServerCtx, block int64, collateralToken string) (*v1.ChainTokenBorrowInfo, error) {
+	block, err := resolveBlock(svcCtx, block)
+	if err != nil {
+		return nil, err
+	}
+	var info v1.ChainTokenBorrowInfo
+	err = svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:borrow:%s:%d", collateralToken, block), &info, func() (interface{}, error) {
+		words, err := svcCtx.Pool.GetTokenBorrowInfo(block, collateralToken)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.ChainTokenBorrowInfo{
+			Block:           block,
+			CollateralToken: collateralToken,
+			Borrowed:        words[0].String(),
+			Borrowable:      words[1].String(),
+			UtilizationRate: words[2].String(),
+		}, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read token borrow info")
+	}
+	return &info, nil
+}
+
+// GetChainUserLend 读取指定区块用户的存款本息
+func GetChainUserLend(svcCtx *service.ServerCtx, block int64, user string) (*v1.ChainUserLend, error) {
+	block, err := resolveBlock(svcCtx, block)
+	if err != nil {
+		return nil, err
+	}
+	var info v1.ChainUserLend
+	err = svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:user:lend:%s:%d", user, block), &info, func() (interface{}, error) {
+		total, err := svcCtx.Pool.CalculateUserLendTotal(block, user)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.ChainUserLend{
+			Block:             block,
+			User:              user,
+			TotalWithInterest: total.String(),
+		}, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read user lend total")
+	}
+	return &info, nil
+}
+
+// GetChainUserBorrow 读取指定区块用户在某个抵押代币下的借款本息
+func GetChainUserBorrow(svcCtx *service.ServerCtx, block int64, user, collateralToken string) (*v1.ChainUserBorrow, error) {
+	block, err := resolveBlock(svcCtx, block)
+	if err != nil {
+		return nil, err
+	}
+	var info v1.ChainUserBorrow
+	err = svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:user:borrow:%s:%s:%d", user, collateralToken, block), &info, func() (interface{}, error) {
+		total, err := svcCtx.Pool.CalculateUserBorrowTotal(block, user, collateralToken)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.ChainUserBorrow{
+			Block:             block,
+			User:              user,
+			CollateralToken:   collateralToken,
+			TotalWithInterest: total.String(),
+		}, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read user borrow total")
+	}
+	return &info, nil
+}
+
+// GetChainUserHealth 读取指定区块用户在某个抵押代币下的健康因子
+func GetChainUserHealth(svcCtx *service.ServerCtx, block int64, user, collateralToken string) (*v1.ChainUserHealth, error) {
+	block, err := resolveBlock(svcCtx, block)
+	if err != nil {
+		return nil, err
+	}
+	var info v1.ChainUserHealth
+	err = svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:user:health:%s:%s:%d", user, collateralToken, block), &info, func() (interface{}, error) {
+		words, err := svcCtx.Pool.GetUserHealthInfo(block, collateralToken, user)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.ChainUserHealth{
+			Block:                block,
+			User:                 user,
+			CollateralToken:      collateralToken,
+			HealthFactor:         words[0].String(),
+			TotalCollateralValue: words[1].String(),
+			TotalDebtValue:       words[2].String(),
+			IsLiquidatable:       words[3].Sign() != 0,
+		}, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read user health info")
+	}
+	return &info, nil
+}
+
+// GetChainUserPosition 读取用户的存款本息和各抵押代币下的借款、健康因子，block为0时固定到最新区块，所有读取都在同一个区块
+func GetChainUserPosition(ctx context.Context, svcCtx *service.ServerCtx, block int64, user string) (*v1.ChainUserPosition, error) {
+	lend, err := GetChainUserLend(svcCtx, block, user)
+	if err != nil {
+		return nil, err
+	}
+	collaterals, err := GetLatestCollaterals(ctx, svcCtx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &v1.ChainUserPosition{Block: lend.Block, User: user, LendTotalWithInterest: lend.TotalWithInterest}
+	for _, collateral := range collaterals {
+		token := strings.ToLower(collateral.TokenAddress)
+		borrow, err := GetChainUserBorrow(svcCtx, lend.Block, user, token)
+		if err != nil {
+			return nil, err
+		}
+		health, err := GetChainUserHealth(svcCtx, lend.Block, user, token)
+		if err != nil {
+			return nil, err
+		}
+		result.Borrows = append(result.Borrows, &v1.ChainBorrowPosition{
+			CollateralToken:      token,
+			TotalWithInterest:    borrow.TotalWithInterest,
+			HealthFactor:         health.HealthFactor,
+			TotalCollateralValue: health.TotalCollateralValue,
+			TotalDebtValue:       health.TotalDebtValue,
+			IsLiquidatable:       health.IsLiquidatable,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// GetUserEarnings 根据用户的存取款记录和指数快照，计算用户本金、累积利息和已实现利息
+// 合约中用户的存款价值 = Σ(本金 × 当前指数 / 存款时指数)，等价于 缩放余额 × 当前指数，
+// 所以这里按链上顺序维护缩放余额：存款加上 amount/指数，取款减去 amount/指数
+func GetUserEarnings(ctx context.Context, svcCtx *service.ServerCtx, userAddress string) (*v1.UserEarnings, error) {
+	activities, err := svcCtx.Dao.GetUserLendActivities(ctx, userAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query user lend activities")
+	}
+
+	var ledger earningsLedger
+	entries := make([]*v1.EarningsEntry, 0, len(activities))
+	for _, activity := range activities {
+		amount, err := decimal.NewFromString(activity.Amount)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid amount of tx %s", activity.TxHash)
+		}
+		interest, err := decimal.NewFromString(activity.Interest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid interest of tx %s", activity.TxHash)
+		}
+		index, err := activityIndex(ctx, svcCtx, activity)
+		if err != nil {
+			return nil, err
+		}
+
+		ledger.apply(activity.Action, amount, interest, index)
+		entries = append(entries, &v1.EarningsEntry{
+			BlockNumber:      activity.BlockNumber,
+			BlockTime:        activity.BlockTime,
+			TxHash:           activity.TxHash,
+			Action:           activity.Action,
+			Amount:           activity.Amount,
+			Interest:         activity.Interest,
+			Principal:        ledger.principal.Truncate(0).String(),
+			RealizedInterest: ledger.realized.Truncate(0).String(),
+			AccruedInterest:  ledger.accrued(index).Truncate(0).String(),
+		})
+	}
+
+	earnings := &v1.UserEarnings{
+		Address:          userAddress,
+		Principal:        ledger.principal.Truncate(0).String(),
+		AccruedInterest:  "0",
+		RealizedInterest: ledger.realized.Truncate(0).String(),
+		CurrentValue:     ledger.principal.Truncate(0).String(),
+		Entries:          entries,
+	}
+	latest, err := svcCtx.Dao.GetInterestIndexBefore(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query latest interest index")
+	}
+	if latest != nil {
+		latestIndex, err := decimal.NewFromString(latest.LiquidityIndex)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid latest liquidity index")
+		}
+		earnings.CurrentValue = fromScaled(ledger.scaled, latestIndex).Truncate(0).String()
+		earnings.AccruedInterest = ledger.accrued(latestIndex).Truncate(0).String()
+		earnings.AsOfBlock = latest.BlockNumber
+		earnings.AsOfTime = latest.LastUpdateTimestamp
+	}
+	return earnings, nil
+}
+
+// earningsLedger 按链上顺序累计用户的缩放余额、本金和已实现利息
+type earningsLedger struct {
+	scaled    decimal.Decimal // 缩放余额，乘以当前指数为存款本息
+	principal decimal.Decimal // 未取回的本金
+	realized  decimal.Decimal // 取款时已取回的利息
+}
+
+// apply 记录一次存取款，index为发生时的存款指数，为0表示没有指数快照
+// 存款加上 amount/指数；取款中 amount-interest 为取回的本金，缩放余额减去 amount/指数，
+// 没有指数快照时按取回本金的比例减少缩放余额
+func (l *earningsLedger) apply(action string, amount, interest, index decimal.Decimal) {
+	switch action {
+	case v1.ActionLendDeposit:
+		l.principal = l.principal.Add(amount)
+		if index.Sign() > 0 {
+			l.scaled = l.scaled.Add(toScaled(amount, index))
+		}
+	case v1.ActionLendWithdraw:
+		principalReduced := amount.Sub(interest)
+		if index.Sign() > 0 {
+			l.scaled = l.scaled.Sub(toScaled(amount, index))
+		} else if l.principal.Sign() > 0 {
+			l.scaled = l.scaled.Sub(l.scaled.Mul(principalReduced).Div(l.principal))
+		}
+		l.principal = l.principal.Sub(principalReduced)
+		l.realized = l.realized.Add(interest)
+	}
+	l.scaled = positive(l.scaled)
+	l.principal = positive(l.principal)
+}
+
+// accrued 按指数计算的未取回利息，没有指数时为0
+func (l *earningsLedger) accrued(index decimal.Decimal) decimal.Decimal {
+	if index.Sign() <= 0 {
+		return decimal.Zero
+	}
+	return positive(fromScaled(l.scaled, index).Sub(l.principal))
+}
+
+// activityIndex 获取一次存取款发生时的存款指数，存款事件自带指数，取款使用当时区块的指数快照
+func activityIndex(ctx context.Context, svcCtx *service.ServerCtx, activity *v1.UserActivity) (decimal.Decimal, error) {
+	if activity.LiquidityIndex != "" {
+		index, err := decimal.NewFromString(activity.LiquidityIndex)
+		if err != nil {
+			return decimal.Zero, errors.Wrapf(err, "invalid liquidity index of tx %s", activity.TxHash)
+		}
+		return index, nil
+	}
+	snapshot, err := svcCtx.Dao.GetInterestIndexAtBlock(ctx, activity.BlockNumber)
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed on query interest index")
+	}
+	if snapshot == nil {
+		return decimal.Zero, nil
+	}
+	index, err := decimal.NewFromString(snapshot.LiquidityIndex)
+	if err != nil {
+		return decimal.Zero, errors.Wrapf(err, "invalid liquidity index of block %d", snapshot.BlockNumber)
+	}
+	return index, nil
+}
+
+// ray 合约指数精度 1e27
+var ray = decimal.New(1, 27)
+
+// toScaled 把金额按指数折算为缩放余额
+func toScaled(amount, index decimal.Decimal) decimal.Decimal {
+	return amount.Mul(ray).DivRound(index, 18)
+}
+
+// fromScaled 把缩放余额按指数还原为金额
+func fromScaled(scaled, index decimal.Decimal) decimal.Decimal {
+	return scaled.Mul(index).DivRound(ray, 18)
+}
+
+func positive(d decimal.Decimal) decimal.Decimal {
+	if d.Sign() < 0 {
+		return decimal.Zero
+	}
+	return d
+}
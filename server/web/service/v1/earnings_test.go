@@ -0,0 +1,91 @@
+package v1
+
+import (
+	v1 "aave_web/types/v1"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarningsLedger(t *testing.T) {
+	index := func(s string) decimal.Decimal {
+		return decimal.RequireFromString(s).Mul(ray)
+	}
+	type step struct {
+		action   string
+		amount   string
+		interest string
+		index    decimal.Decimal // 发生时的存款指数，为0表示没有快照
+
+		wantPrincipal string
+		wantRealized  string
+		wantAccrued   string // 按发生时的指数计算
+	}
+	tests := []struct {
+		name              string
+		steps             []step
+		latest            decimal.Decimal
+		wantLatestValue   string
+		wantLatestAccrued string
+	}{
+		{
+			name: "deposit then partial and full withdraw",
+			steps: []step{
+				{v1.ActionLendDeposit, "1000", "0", index("1"), "1000", "0", "0"},
+				// 指数上涨10%后取出550，其中利息50，取回本金500
+				{v1.ActionLendWithdraw, "550", "50", index("1.1"), "500", "50", "50"},
+				// 剩余缩放余额500，指数1.2时本息600
+				{v1.ActionLendWithdraw, "600", "100", index("1.2"), "0", "150", "0"},
+			},
+			latest: index("1.3"), wantLatestValue: "0", wantLatestAccrued: "0",
+		},
+		{
+			name: "deposits at different indexes",
+			steps: []step{
+				{v1.ActionLendDeposit, "1000", "0", index("1"), "1000", "0", "0"},
+				{v1.ActionLendDeposit, "1100", "0", index("1.1"), "2100", "0", "100"},
+			},
+			// 缩放余额2000，指数1.2时本息2400
+			latest: index("1.2"), wantLatestValue: "2400", wantLatestAccrued: "300",
+		},
+		{
+			name: "partial withdraw keeps accruing",
+			steps: []step{
+				{v1.ActionLendDeposit, "1000", "0", index("1"), "1000", "0", "0"},
+				{v1.ActionLendWithdraw, "550", "50", index("1.1"), "500", "50", "50"},
+			},
+			latest: index("1.2"), wantLatestValue: "600", wantLatestAccrued: "100",
+		},
+		{
+			name: "withdraw without index snapshot",
+			steps: []step{
+				{v1.ActionLendDeposit, "1000", "0", index("1"), "1000", "0", "0"},
+				// 按取回本金的比例减少缩放余额：500/1000
+				{v1.ActionLendWithdraw, "550", "50", decimal.Zero, "500", "50", "0"},
+			},
+			latest: index("1.1"), wantLatestValue: "550", wantLatestAccrued: "50",
+		},
+		{
+			name: "over withdraw clamps to zero",
+			steps: []step{
+				{v1.ActionLendDeposit, "1000", "0", index("1"), "1000", "0", "0"},
+				{v1.ActionLendWithdraw, "1200", "100", index("1.1"), "0", "100", "0"},
+			},
+			latest: index("1.1"), wantLatestValue: "0", wantLatestAccrued: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ledger earningsLedger
+			for i, s := range tt.steps {
+				ledger.apply(s.action, decimal.RequireFromString(s.amount), decimal.RequireFromString(s.interest), s.index)
+				assert.Equal(t, s.wantPrincipal, ledger.principal.Truncate(0).String(), "step %d principal", i)
+				assert.Equal(t, s.wantRealized, ledger.realized.Truncate(0).String(), "step %d realized", i)
+				assert.Equal(t, s.wantAccrued, ledger.accrued(s.index).Truncate(0).String(), "step %d accrued", i)
+			}
+			assert.Equal(t, tt.wantLatestValue, fromScaled(ledger.scaled, tt.latest).Truncate(0).String())
+			assert.Equal(t, tt.wantLatestAccrued, ledger.accrued(tt.latest).Truncate(0).String())
+		})
+	}
+}
@@ -9,43 +9,146 @@ import (
 	"time"
 )
 
+// eventPollInterval 队列为空或读取失败时的等待时间
+const eventPollInterval = 5 * time.Second
+
+// Event 调度服务发送的状态变化通知，Tags 为需要清除的接口缓存标签
 type Event struct {
-	Type string `json:"type"`
+	Type  string   `json:"type"`
+	Block uint64   `json:"block"`
+	Tags  []string `json:"tags"`
 }
 
 func getNotifyQueue() string {
 	return "aave:event:CollateralChanged"
 }
 
-func PushEvent(wsServer *WSServer, svcCtx *service.ServerCtx) {
-	go func() {
-		key := getNotifyQueue()
-		for {
-			ctx := context.Background()
-			result, err := svcCtx.KvStore.Lpop(key)
-			if err != nil || result == "" {
-				time.Sleep(5 * time.Second)
-				xzap.WithContext(ctx).Info("no event in redis queue, wait 5s")
-				continue
-			}
-			xzap.WithContext(ctx).Info("get event from redis queue", zap.String("result", result))
-			// 从数据库查询相关信息，推送给websocket
-			lendData, err := svcCtx.Dao.GetLendData(ctx)
-			if err != nil {
-				xzap.WithContext(ctx).Warn("failed on GetLendData", zap.Error(err))
-				continue
-			}
-			if lendData != nil {
-				// json序列化
-				lendDataJson, err := json.Marshal(lendData)
-				if err != nil {
-					xzap.WithContext(ctx).Warn("failed on json.Marshal lendData", zap.Error(err))
-				} else {
-					// 广播
-					xzap.WithContext(ctx).Info("broadcast lendData to websocket", zap.String("lendDataJson", string(lendDataJson)))
-					wsServer.broadcast <- lendDataJson
-				}
+// getProcessingQueue 实例正在处理的通知，处理完成后删除（ack），进程异常退出时留在这里，
+// 同一实例重启时放回通知队列；每个实例使用自己的队列，不会把其它实例正在处理的通知放回
+func getProcessingQueue(instance string) string {
+	return "aave:event:CollateralChanged:processing:" + instance
+}
+
+// EventConsumer 消费调度服务的状态变化通知：清除接口缓存、发布状态变化并推送给websocket，向受影响的用户推送私有消息
+// 通知先移入处理中队列，处理完再删除，退出时等待正在处理的通知和健康因子检查完成
+type EventConsumer struct {
+	wsServer   *WSServer
+	svcCtx     *service.ServerCtx
+	quit       chan struct{}
+	done       chan struct{}
+	healthDone chan struct{}
+
+	processingQueue string // 本实例的处理中队列
+}
+
+func NewEventConsumer(wsServer *WSServer, svcCtx *service.ServerCtx) *EventConsumer {
+	return &EventConsumer{
+		wsServer:   wsServer,
+		svcCtx:     svcCtx,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+		healthDone: make(chan struct{}),
+
+		processingQueue: getProcessingQueue(instanceName(svcCtx)),
+	}
+}
+
+// PushEvent 启动通知消费，并注册到生命周期中随服务退出停止
+func PushEvent(wsServer *WSServer, svcCtx *service.ServerCtx) *EventConsumer {
+	consumer := NewEventConsumer(wsServer, svcCtx)
+	consumer.Start()
+	if svcCtx.Lifecycle != nil {
+		svcCtx.Lifecycle.OnStop("event consumer", consumer.Stop)
+	}
+	return consumer
+}
+
+// Start 启动消费goroutine和价格更新后的健康因子检查goroutine
+func (ec *EventConsumer) Start() {
+	go ec.run()
+	go ec.runPriceHealth()
+}
+
+// Stop 停止读取新的通知，等待正在处理的通知完成并ack，健康因子检查在当前页完成后停止
+func (ec *EventConsumer) Stop(ctx context.Context) error {
+	close(ec.quit)
+	for _, done := range []chan struct{}{ec.done, ec.healthDone} {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (ec *EventConsumer) run() {
+	defer close(ec.done)
+	ctx := context.Background()
+	ec.requeueProcessing(ctx)
+	for {
+		select {
+		case <-ec.quit:
+			xzap.WithContext(ctx).Info("event consumer stopped")
+			return
+		default:
+		}
+		result, err := ec.svcCtx.KvStore.Redis.RPopLPush(getNotifyQueue(), ec.processingQueue)
+		if err != nil || result == "" {
+			xzap.WithContext(ctx).Info("no event in redis queue, wait 5s")
+			select {
+			case <-ec.quit:
+			case <-time.After(eventPollInterval):
 			}
+			continue
+		}
+		ec.handle(ctx, result)
+		if _, err := ec.svcCtx.KvStore.Redis.Lrem(ec.processingQueue, 1, result); err != nil {
+			xzap.WithContext(ctx).Warn("failed on ack event", zap.String("result", result), zap.Error(err))
+		}
+	}
+}
+
+// requeueProcessing 把本实例上次异常退出时未ack的通知放回通知队列，重复处理只会多清一次缓存、多推一次最新数据
+func (ec *EventConsumer) requeueProcessing(ctx context.Context) {
+	for {
+		result, err := ec.svcCtx.KvStore.Redis.RPopLPush(ec.processingQueue, getNotifyQueue())
+		if err != nil || result == "" {
+			return
+		}
+		xzap.WithContext(ctx).Info("requeue unacked event", zap.String("result", result))
+	}
+}
+
+// handle 处理一条通知
+func (ec *EventConsumer) handle(ctx context.Context, result string) {
+	svcCtx := ec.svcCtx
+	xzap.WithContext(ctx).Info("get event from redis queue", zap.String("result", result))
+	var event Event
+	if err := json.Unmarshal([]byte(result), &event); err != nil {
+		xzap.WithContext(ctx).Warn("failed on json.Unmarshal event", zap.Error(err))
+	}
+	// 先清除受影响的接口缓存，再推送最新数据
+	if len(event.Tags) > 0 {
+		purged, err := svcCtx.KvStore.PurgeTags(event.Tags...)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on purge api cache", zap.Strings("tags", event.Tags), zap.Error(err))
+		} else {
+			xzap.WithContext(ctx).Info("purge api cache", zap.Strings("tags", event.Tags), zap.Int("purged", purged))
 		}
-	}()
+	}
+	// 从数据库查询相关信息，推送给websocket
+	lendData, err := svcCtx.Dao.GetLendData(ctx)
+	if err != nil {
+		xzap.WithContext(ctx).Warn("failed on GetLendData", zap.Error(err))
+		return
+	}
+	if lendData != nil {
+		svcCtx.Feed.Publish(&service.StateChange{Type: event.Type, Lend: lendData, Time: time.Now().Unix()})
+		// 按主题广播给WebSocket和SSE客户端
+		xzap.WithContext(ctx).Info("publish lendData to pool topic", zap.Int64("block_number", lendData.BlockNumber))
+		ec.wsServer.Publish(TopicPool, MessagePoolUpdate, lendData)
+	}
+	// 推送受影响用户的私有主题
+	ec.publishUserUpdates(ctx, event)
 }
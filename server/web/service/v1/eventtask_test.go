@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"aave_web/config"
+	"aave_web/service"
+	"aave_web/stores/xkv"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestRequeueProcessingOwnQueue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := xkv.NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}})
+	newConsumer := func(instance string) *EventConsumer {
+		return NewEventConsumer(nil, &service.ServerCtx{KvStore: store, C: &config.Config{Ws: config.WsConf{Instance: instance}}})
+	}
+	a, b := newConsumer("a"), newConsumer("b")
+	_, err := mr.Lpush(getProcessingQueue("a"), `{"type":"a"}`)
+	assert.NoError(t, err)
+	_, err = mr.Lpush(getProcessingQueue("b"), `{"type":"b"}`)
+	assert.NoError(t, err)
+
+	// a重启时只放回自己未ack的通知，b正在处理的通知保持不变
+	a.requeueProcessing(context.Background())
+	queue, err := mr.List(getNotifyQueue())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"type":"a"}`}, queue)
+	assert.False(t, mr.Exists(getProcessingQueue("a")))
+	processing, err := mr.List(b.processingQueue)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"type":"b"}`}, processing)
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// LiquidationGroupLiquidator 按清算人聚合
+	LiquidationGroupLiquidator = "liquidator"
+	// LiquidationGroupCollateral 按抵押代币聚合
+	LiquidationGroupCollateral = "collateral_token"
+)
+
+// GetLiquidations 分页获取全协议的清算记录，按链上顺序倒序
+func GetLiquidations(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.LiquidationFilter) (*v1.LiquidationPage, error) {
+	items, err := svcCtx.Dao.GetLiquidations(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query liquidations")
+	}
+	page := &v1.LiquidationPage{Items: items}
+	if len(items) == filter.Limit {
+		last := items[len(items)-1]
+		page.NextCursor = EncodeLogCursor(last.BlockNumber, last.LogIndex)
+	}
+	return page, nil
+}
+
+// GetRecentLiquidationsBatch 批量获取多个借款人各自最近的被清算记录，按借款人地址分组
+func GetRecentLiquidationsBatch(ctx context.Context, svcCtx *service.ServerCtx, borrowers []string, limit int) (map[string][]*v1.Liquidation, error) {
+	items, err := svcCtx.Dao.GetRecentLiquidationsBatch(ctx, borrowers, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query recent liquidations")
+	}
+	grouped := make(map[string][]*v1.Liquidation, len(borrowers))
+	for _, item := range items {
+		grouped[item.Borrower] = append(grouped[item.Borrower], item)
+	}
+	return grouped, nil
+}
+
+// GetLiquidationStats 获取清算人排行或抵押代币的清算统计，并计算实际清算奖励率，用于观察清算激励是否有效
+func GetLiquidationStats(ctx context.Context, svcCtx *service.ServerCtx, groupBy string, filter *dao.LiquidationFilter) ([]*v1.LiquidationStats, error) {
+	items, err := svcCtx.Dao.GetLiquidationStats(ctx, groupBy, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query liquidation stats")
+	}
+	for _, item := range items {
+		item.BonusRate = bonusRate(item.BonusValueUsd, item.DebtValueUsd)
+	}
+	return items, nil
+}
+
+// bonusRate 清算奖励价值占代还债务价值的百分比，债务价值为0时返回0
+func bonusRate(bonusValue, debtValue string) string {
+	bonus, err := decimal.NewFromString(bonusValue)
+	if err != nil {
+		return "0"
+	}
+	debt, err := decimal.NewFromString(debtValue)
+	if err != nil || debt.Sign() <= 0 {
+		return "0"
+	}
+	return bonus.Mul(decimal.NewFromInt(100)).DivRound(debt, 4).StringFixed(4)
+}
@@ -0,0 +1,305 @@
+package v1
+
+import (
+	"aave_web/chain"
+	"aave_web/logger/xzap"
+	v1 "aave_web/types/v1"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	// cacheTagUserPrefix 通知中受影响用户的缓存标签前缀，与 api/middleware.CacheTagUserPrefix 一致
+	cacheTagUserPrefix = "user:"
+	// defaultHealthAlert 未配置时健康因子的告警值，6位精度
+	defaultHealthAlert = 1500000
+	// healthStateSeconds 用户上次健康因子的保存时间，过期后下一次低于告警值时重新推送
+	healthStateSeconds = 7 * 24 * 3600
+	// maxLiquidationNotices 每次查询的清算记录条数，超过时分页查询
+	maxLiquidationNotices = 200
+	// priceHealthInterval 检查预言机价格是否更新的间隔，价格更新后重新检查所有借款人的健康因子
+	priceHealthInterval = time.Minute
+	// priceHealthPageSize 每次查询的借款人数量
+	priceHealthPageSize = 500
+
+	// LiquidationRoleBorrower 清算通知中用户是被清算的借款人
+	LiquidationRoleBorrower = "borrower"
+	// LiquidationRoleLiquidator 清算通知中用户是清算人
+	LiquidationRoleLiquidator = "liquidator"
+)
+
+// getHealthStateKey 用户在某个抵押代币下上次推送时的健康因子
+func getHealthStateKey(user, collateralToken string) string {
+	return fmt.Sprintf("aave:ws:health:%s:%s", user, collateralToken)
+}
+
+// getNoticeCursorKey 已推送清算通知的最后一条记录的位置
+func getNoticeCursorKey() string {
+	return "aave:ws:notice:cursor"
+}
+
+// getPriceHealthCursorKey 价格更新后重新检查健康因子的进度
+func getPriceHealthCursorKey() string {
+	return "aave:ws:health:cursor"
+}
+
+// priceHealthCursor 重新检查健康因子的进度，每页检查完后保存
+type priceHealthCursor struct {
+	PriceID int64  `json:"price_id"` // 本轮检查对应的最新价格记录id
+	Block   int64  `json:"block"`    // 本轮检查读取链上数据的区块
+	After   string `json:"after"`    // 已检查的最后一个借款人
+	Done    bool   `json:"done"`     // 本轮检查已完成
+}
+
+// noticeCursor 清算记录在链上的位置
+type noticeCursor struct {
+	Block    int64 `json:"block"`
+	LogIndex int   `json:"log_index"`
+}
+
+// healthDrop health_drop消息的payload
+type healthDrop struct {
+	Block                int64  `json:"block"`
+	CollateralToken      string `json:"collateral_token"`
+	PreviousHealthFactor string `json:"previous_health_factor"` // 第一次观察到时为空
+	HealthFactor         string `json:"health_factor"`          // 6位精度
+	IsLiquidatable       bool   `json:"is_liquidatable"`
+}
+
+// liquidationNotice liquidation消息的payload
+type liquidationNotice struct {
+	Role        string          `json:"role"`
+	Liquidation *v1.Liquidation `json:"liquidation"`
+}
+
+// eventUsers 通知的缓存标签中受影响的用户地址
+func eventUsers(tags []string) []string {
+	var users []string
+	for _, tag := range tags {
+		if user, ok := strings.CutPrefix(tag, cacheTagUserPrefix); ok && user != "" {
+			users = append(users, strings.ToLower(user))
+		}
+	}
+	return users
+}
+
+// publishUserUpdates 向受影响用户的私有主题推送最新仓位和健康因子下降，向清算双方推送清算通知
+// 仓位固定读取通知所在区块，未配置链上读取时只推送清算通知
+func (ec *EventConsumer) publishUserUpdates(ctx context.Context, event Event) {
+	block := int64(event.Block)
+	if ec.svcCtx.Pool != nil {
+		for _, user := range eventUsers(event.Tags) {
+			position, err := GetChainUserPosition(ctx, ec.svcCtx, block, user)
+			if err != nil {
+				xzap.WithContext(ctx).Warn("failed on get chain user position", zap.String("user", user), zap.Error(err))
+				continue
+			}
+			topic := UserTopic(user)
+			ec.wsServer.Publish(topic, MessagePositionUpdate, position)
+			for _, borrow := range position.Borrows {
+				if drop := ec.checkHealth(ctx, user, position.Block, borrow); drop != nil {
+					ec.wsServer.Publish(topic, MessageHealthDrop, drop)
+				}
+			}
+		}
+	}
+	ec.publishLiquidations(ctx, block)
+}
+
+// checkHealth 记录用户最新的健康因子，低于告警值且比上次下降时返回health_drop，重复处理同一区块不会重复推送
+func (ec *EventConsumer) checkHealth(ctx context.Context, user string, block int64, borrow *v1.ChainBorrowPosition) *healthDrop {
+	// 没有借款时健康因子没有意义
+	debt, err := decimal.NewFromString(borrow.TotalDebtValue)
+	if err != nil || debt.Sign() <= 0 {
+		return nil
+	}
+	current, err := decimal.NewFromString(borrow.HealthFactor)
+	if err != nil {
+		return nil
+	}
+	key := getHealthStateKey(user, borrow.CollateralToken)
+	previous, err := ec.svcCtx.KvStore.Get(key)
+	if err != nil {
+		xzap.WithContext(ctx).Warn("failed on get health state", zap.String("key", key), zap.Error(err))
+	}
+	if err := ec.svcCtx.KvStore.SetString(key, borrow.HealthFactor, healthStateSeconds); err != nil {
+		xzap.WithContext(ctx).Warn("failed on set health state", zap.String("key", key), zap.Error(err))
+	}
+	if current.GreaterThanOrEqual(decimal.NewFromInt(ec.healthAlert())) {
+		return nil
+	}
+	if previous != "" {
+		if last, err := decimal.NewFromString(previous); err == nil && current.GreaterThanOrEqual(last) {
+			return nil
+		}
+	}
+	return &healthDrop{
+		Block:                block,
+		CollateralToken:      borrow.CollateralToken,
+		PreviousHealthFactor: previous,
+		HealthFactor:         borrow.HealthFactor,
+		IsLiquidatable:       borrow.IsLiquidatable,
+	}
+}
+
+func (ec *EventConsumer) healthAlert() int64 {
+	if ec.svcCtx.C != nil && ec.svcCtx.C.Ws.HealthAlert > 0 {
+		return ec.svcCtx.C.Ws.HealthAlert
+	}
+	return defaultHealthAlert
+}
+
+// publishLiquidations 推送上次推送的记录之后到block之间的清算记录，借款人和清算人各收到一条
+// 第一次运行时只推送block所在区块的清算；按页查询直到区间内没有更多记录，每页推送后记录最后一条的位置，
+// 查询失败时下一条通知从该位置继续，重复处理的通知不会重复推送
+func (ec *EventConsumer) publishLiquidations(ctx context.Context, block int64) {
+	store := ec.svcCtx.KvStore
+	var cursor noticeCursor
+	found, err := store.Read(getNoticeCursorKey(), &cursor)
+	if err != nil {
+		xzap.WithContext(ctx).Warn("failed on get notice cursor", zap.Error(err))
+		return
+	}
+	if !found {
+		cursor = noticeCursor{Block: block, LogIndex: -1}
+	}
+	for cursor.Block <= block {
+		items, err := ec.svcCtx.Dao.GetLiquidationsAfter(ctx, cursor.Block, cursor.LogIndex, block, maxLiquidationNotices)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on get liquidations after cursor", zap.Int64("block", cursor.Block), zap.Int("log_index", cursor.LogIndex), zap.Int64("to", block), zap.Error(err))
+			return
+		}
+		for _, item := range items {
+			ec.wsServer.Publish(UserTopic(item.Borrower), MessageLiquidation, liquidationNotice{Role: LiquidationRoleBorrower, Liquidation: item})
+			ec.wsServer.Publish(UserTopic(item.Liquidator), MessageLiquidation, liquidationNotice{Role: LiquidationRoleLiquidator, Liquidation: item})
+			cursor = noticeCursor{Block: item.BlockNumber, LogIndex: item.LogIndex}
+		}
+		if len(items) > 0 {
+			if err := store.Write(getNoticeCursorKey(), &cursor); err != nil {
+				xzap.WithContext(ctx).Warn("failed on set notice cursor", zap.Error(err))
+			}
+		}
+		if len(items) < maxLiquidationNotices {
+			return
+		}
+	}
+}
+
+// runPriceHealth 按priceHealthInterval检查预言机价格是否更新，与通知消费在不同的goroutine中运行，收到退出信号后停止
+func (ec *EventConsumer) runPriceHealth() {
+	defer close(ec.healthDone)
+	// 未配置链上读取时不检查
+	if ec.svcCtx.Pool == nil {
+		return
+	}
+	ctx := context.Background()
+	ticker := time.NewTicker(priceHealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ec.quit:
+			xzap.WithContext(ctx).Info("price health check stopped")
+			return
+		case <-ticker.C:
+			ec.checkPriceHealth(ctx)
+		}
+	}
+}
+
+// checkPriceHealth 预言机价格更新后重新检查所有借款人的健康因子，借款人没有操作时价格下跌也能收到health_drop
+// 没有新的价格记录时不读取链上数据；每页检查完后保存进度，中断或重启后从上次的借款人之后继续，
+// 一轮检查全部使用开始时的区块
+func (ec *EventConsumer) checkPriceHealth(ctx context.Context) {
+	store := ec.svcCtx.KvStore
+	var cursor priceHealthCursor
+	if _, err := store.Read(getPriceHealthCursorKey(), &cursor); err != nil {
+		xzap.WithContext(ctx).Warn("failed on get price health cursor", zap.Error(err))
+		return
+	}
+	if cursor.Done || cursor.PriceID == 0 {
+		priceID, err := ec.svcCtx.Dao.GetLatestTokenPriceID(ctx)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on get latest token price", zap.Error(err))
+			return
+		}
+		if priceID <= cursor.PriceID {
+			return
+		}
+		block, err := resolveBlock(ec.svcCtx, 0)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on get latest block", zap.Error(err))
+			return
+		}
+		cursor = priceHealthCursor{PriceID: priceID, Block: block}
+	}
+	collaterals, err := GetLatestCollaterals(ctx, ec.svcCtx, nil)
+	if err != nil {
+		xzap.WithContext(ctx).Warn("failed on get collaterals", zap.Error(err))
+		return
+	}
+	for !cursor.Done {
+		select {
+		case <-ec.quit:
+			return
+		default:
+		}
+		users, err := ec.svcCtx.Dao.GetBorrowerAddresses(ctx, cursor.After, priceHealthPageSize)
+		if err != nil {
+			xzap.WithContext(ctx).Warn("failed on get borrowers", zap.String("after", cursor.After), zap.Error(err))
+			return
+		}
+		if err := ec.checkUsersHealth(ctx, users, cursor.Block, collaterals); err != nil {
+			xzap.WithContext(ctx).Warn("failed on get chain users health", zap.String("after", cursor.After), zap.Error(err))
+			return
+		}
+		if len(users) < priceHealthPageSize {
+			cursor.Done = true
+		} else {
+			cursor.After = users[len(users)-1]
+		}
+		if err := store.Write(getPriceHealthCursorKey(), &cursor); err != nil {
+			xzap.WithContext(ctx).Warn("failed on set price health cursor", zap.Error(err))
+		}
+	}
+}
+
+// checkUsersHealth 通过一次批量请求读取一页借款人在各抵押代币下的健康因子，下降到告警值以下时推送health_drop
+// 单个读取失败时跳过，只有请求本身失败时返回error
+func (ec *EventConsumer) checkUsersHealth(ctx context.Context, users []string, block int64, collaterals []*v1.Collateral) error {
+	queries := make([]chain.HealthQuery, 0, len(users)*len(collaterals))
+	for _, user := range users {
+		for _, collateral := range collaterals {
+			queries = append(queries, chain.HealthQuery{CollateralToken: strings.ToLower(collateral.TokenAddress), User: strings.ToLower(user)})
+		}
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	results, err := ec.svcCtx.Pool.GetUserHealthInfoBatch(block, queries)
+	if err != nil {
+		return err
+	}
+	for i, query := range queries {
+		if results[i].Err != nil {
+			xzap.WithContext(ctx).Warn("failed on get chain user health", zap.String("user", query.User), zap.String("token", query.CollateralToken), zap.Error(results[i].Err))
+			continue
+		}
+		words := results[i].Words
+		borrow := &v1.ChainBorrowPosition{
+			CollateralToken:      query.CollateralToken,
+			HealthFactor:         words[0].String(),
+			TotalCollateralValue: words[1].String(),
+			TotalDebtValue:       words[2].String(),
+			IsLiquidatable:       words[3].Sign() != 0,
+		}
+		if drop := ec.checkHealth(ctx, query.User, block, borrow); drop != nil {
+			ec.wsServer.Publish(UserTopic(query.User), MessageHealthDrop, drop)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"aave_web/chain"
+	logging "aave_web/logger"
+	"aave_web/logger/xzap"
+	"aave_web/service"
+	"aave_web/stores/xkv"
+	v1 "aave_web/types/v1"
+	"aave_web/xhttp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestMain(m *testing.M) {
+	// 读取失败时使用全局日志，测试中输出到控制台
+	if _, err := xzap.SetUp(logging.LogConf{Mode: "console", Path: os.TempDir(), Level: "error"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestEventUsers(t *testing.T) {
+	users := eventUsers([]string{"lend", "collateral:1", "user:0xABC", "user:", "user:0xdef"})
+	assert.Equal(t, []string{"0xabc", "0xdef"}, users)
+}
+
+func TestCheckHealth(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := xkv.NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}})
+	ec := &EventConsumer{svcCtx: &service.ServerCtx{KvStore: store}}
+	ctx := context.Background()
+	borrow := func(health, debt string) *v1.ChainBorrowPosition {
+		return &v1.ChainBorrowPosition{CollateralToken: "0xtoken", HealthFactor: health, TotalDebtValue: debt}
+	}
+
+	steps := []struct {
+		name         string
+		borrow       *v1.ChainBorrowPosition
+		wantDrop     bool
+		wantPrevious string
+	}{
+		{"above alert", borrow("2000000", "100"), false, ""},
+		{"drops below alert", borrow("1400000", "100"), true, "2000000"},
+		// 同一健康因子重复处理不重复推送
+		{"unchanged", borrow("1400000", "100"), false, ""},
+		{"drops again", borrow("1100000", "100"), true, "1400000"},
+		{"recovers", borrow("1300000", "100"), false, ""},
+		// 没有借款时不检查
+		{"no debt", borrow("0", "0"), false, ""},
+	}
+	for _, step := range steps {
+		drop := ec.checkHealth(ctx, "0xabc", 10, step.borrow)
+		if !step.wantDrop {
+			assert.Nil(t, drop, step.name)
+			continue
+		}
+		if assert.NotNil(t, drop, step.name) {
+			assert.Equal(t, step.wantPrevious, drop.PreviousHealthFactor, step.name)
+			assert.Equal(t, step.borrow.HealthFactor, drop.HealthFactor, step.name)
+		}
+	}
+}
+
+func TestCheckUsersHealth(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := xkv.NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}})
+	good := "0x1111111111111111111111111111111111111111"
+	reverted := "0x2222222222222222222222222222222222222222"
+	token := "0x4533840185df00119f5a3cd8f2379c0160ca875b"
+	var batches int
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batches++
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var reqs []*xhttp.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		var resps []map[string]interface{}
+		for _, req := range reqs {
+			data := req.Params.([]interface{})[0].(map[string]interface{})["data"].(string)
+			resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			if strings.HasSuffix(data, good[2:]) {
+				resp["result"] = fmt.Sprintf("0x%064x%064x%064x%064x", 2000000, 300, 100, 0)
+			} else {
+				resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+			}
+			resps = append(resps, resp)
+		}
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(server.Close)
+	ec := &EventConsumer{svcCtx: &service.ServerCtx{
+		KvStore: store,
+		Pool:    chain.NewPoolReader(xhttp.NewRPCClient(server.URL), "0xC0AF09A3986b237Faf6a66AC94C49376953F93DA"),
+	}}
+	collaterals := []*v1.Collateral{{TokenAddress: token}}
+	ctx := context.Background()
+
+	// 一页借款人在一次批量请求中读取，单个读取失败时跳过
+	assert.NoError(t, ec.checkUsersHealth(ctx, []string{good, reverted}, 10, collaterals))
+	assert.Equal(t, 1, batches)
+	health, err := store.Get(getHealthStateKey(good, token))
+	assert.NoError(t, err)
+	assert.Equal(t, "2000000", health)
+	health, err = store.Get(getHealthStateKey(reverted, token))
+	assert.NoError(t, err)
+	assert.Equal(t, "", health)
+
+	// 请求本身失败时返回错误，进度不前进
+	fail = true
+	assert.Error(t, ec.checkUsersHealth(ctx, []string{good}, 10, collaterals))
+}
@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// GetPoolParams 获取各风险参数的最新版本，全局参数和抵押代币参数分开返回
+func GetPoolParams(ctx context.Context, svcCtx *service.ServerCtx) (*v1.PoolParams, error) {
+	items, err := svcCtx.Dao.GetLatestPoolParams(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query latest pool params")
+	}
+	params := &v1.PoolParams{Global: []*v1.PoolParam{}, Collaterals: make(map[string][]*v1.PoolParam)}
+	for _, item := range items {
+		if item.Scope == "" {
+			params.Global = append(params.Global, item)
+			continue
+		}
+		params.Collaterals[item.Scope] = append(params.Collaterals[item.Scope], item)
+	}
+	return params, nil
+}
+
+// GetPoolParamHistory 分页获取风险参数的历史版本，按记录顺序倒序
+func GetPoolParamHistory(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.PoolParamFilter) (*v1.PoolParamPage, error) {
+	items, err := svcCtx.Dao.GetPoolParamHistory(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query pool param history")
+	}
+	page := &v1.PoolParamPage{Items: items}
+	if len(items) == filter.Limit {
+		page.NextCursor = strconv.FormatInt(items[len(items)-1].ID, 10)
+	}
+	return page, nil
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// GetTokenPrices 分页获取代币的预言机价格记录，按区块倒序，游标为上一页最后一条记录的区块号
+func GetTokenPrices(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.TokenPriceFilter) (*v1.TokenPricePage, error) {
+	items, err := svcCtx.Dao.GetTokenPrices(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query token prices")
+	}
+	page := &v1.TokenPricePage{Items: items}
+	if len(items) == filter.Limit {
+		page.NextCursor = strconv.FormatInt(items[len(items)-1].BlockNumber, 10)
+	}
+	return page, nil
+}
+
+// GetLatestTokenPrices 批量获取多个代币的最新价格，按代币地址索引
+func GetLatestTokenPrices(ctx context.Context, svcCtx *service.ServerCtx, tokenAddresses []string) (map[string]*v1.TokenPrice, error) {
+	items, err := svcCtx.Dao.GetLatestTokenPrices(ctx, tokenAddresses)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query latest token prices")
+	}
+	prices := make(map[string]*v1.TokenPrice, len(items))
+	for _, item := range items {
+		prices[item.TokenAddress] = item
+	}
+	return prices, nil
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// secondsPerYear 与合约中 SECONDS_PER_YEAR 保持一致
+const secondsPerYear = 365 * 24 * 3600
+
+// GetRealizedRates 根据from、to两个时间点的指数快照，计算这段时间实际的存款/借款年化
+func GetRealizedRates(ctx context.Context, svcCtx *service.ServerCtx, from, to int64) (*v1.RealizedRates, error) {
+	start, err := svcCtx.Dao.GetInterestIndexBefore(ctx, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query start interest index")
+	}
+	if start == nil {
+		// from早于第一条快照时，从第一条快照开始算
+		start, err = svcCtx.Dao.GetInterestIndexAfter(ctx, from)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on query start interest index")
+		}
+	}
+	end, err := svcCtx.Dao.GetInterestIndexBefore(ctx, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query end interest index")
+	}
+	if start == nil || end == nil || end.LastUpdateTimestamp <= start.LastUpdateTimestamp {
+		return nil, errors.New("not enough interest index snapshots in range")
+	}
+
+	seconds := end.LastUpdateTimestamp - start.LastUpdateTimestamp
+	supplyApy, err := annualizeIndexGrowth(start.LiquidityIndex, end.LiquidityIndex, seconds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on calculate supply apy")
+	}
+	borrowApy, err := annualizeIndexGrowth(start.BorrowIndex, end.BorrowIndex, seconds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on calculate borrow apy")
+	}
+	return &v1.RealizedRates{
+		From:      start.LastUpdateTimestamp,
+		To:        end.LastUpdateTimestamp,
+		FromBlock: start.BlockNumber,
+		ToBlock:   end.BlockNumber,
+		SupplyApy: supplyApy,
+		BorrowApy: borrowApy,
+	}, nil
+}
+
+// annualizeIndexGrowth 把指数在seconds秒内的增长折算为复利年化，返回百分比
+func annualizeIndexGrowth(startIndex, endIndex string, seconds int64) (string, error) {
+	startValue, err := decimal.NewFromString(startIndex)
+	if err != nil {
+		return "", err
+	}
+	endValue, err := decimal.NewFromString(endIndex)
+	if err != nil {
+		return "", err
+	}
+	if startValue.Sign() <= 0 {
+		return "", errors.New("invalid start index")
+	}
+	growth, _ := endValue.Div(startValue).Float64()
+	apy := math.Pow(growth, float64(secondsPerYear)/float64(seconds)) - 1
+	return decimal.NewFromFloat(apy * 100).StringFixed(4), nil
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"aave_web/errcode"
+	"aave_web/xhttp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastEventIDHeader 浏览器的EventSource断线重连时自动带上最后收到的事件id
+const lastEventIDHeader = "Last-Event-ID"
+
+// HandleStream SSE推送，与WebSocket共用同一个广播和主题，供代理不支持WebSocket的客户端使用
+// topics为逗号分隔的主题，为空时接收全部公开主题和自己的用户主题；订阅用户主题需要带session_id认证，
+// 浏览器的EventSource不能设置请求头，可以通过session_id参数传入；带Last-Event-ID重连时先补发重放缓冲区中之后的消息，
+// 要补发的消息已被淘汰时先推送resync，客户端需要通过接口重新拉取最新状态
+func HandleStream(s *WSServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := &Client{
+			send:     make(chan *outbound, 256),
+			clientID: fmt.Sprintf("sse-%s-%d", c.Request.RemoteAddr, time.Now().UnixNano()),
+		}
+		sessionID := c.GetHeader(sessionIDHeader)
+		if sessionID == "" {
+			sessionID = c.Query(sessionIDHeader)
+		}
+		if sessionID != "" {
+			if _, err := s.authenticate(client, sessionID); err != nil {
+				log.Printf("SSE认证失败: %v", err)
+				xhttp.Error(c, errcode.ErrTokenVerify)
+				return
+			}
+		}
+		topics, ok := parseTopics(strings.Split(c.Query("topics"), ","), client.canSubscribe)
+		if !ok {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+		// 不能设置请求头的客户端可以通过last_event_id参数传入
+		lastEventID := c.GetHeader(lastEventIDHeader)
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+		var lastSent int64
+		if lastEventID != "" {
+			var err error
+			lastSent, err = strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil || lastSent < 0 {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+		}
+
+		s.mu.RLock()
+		closing := s.closing
+		s.mu.RUnlock()
+		if closing {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		var stopping <-chan struct{}
+		if s.svcCtx.Lifecycle != nil {
+			stopping = s.svcCtx.Lifecycle.Stopping()
+		}
+
+		client.topics = topics
+		select {
+		case s.register <- client:
+		case <-s.quit:
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		defer s.unregisterClient(client)
+
+		c.Header(xhttp.HeaderContentType, "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // 关闭nginx的响应缓冲
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		// 先注册再读取重放缓冲区，期间发布的消息两边都会收到，按事件id去重
+		if lastSent > 0 {
+			items, complete := s.replay.since(lastSent, client.topicList())
+			if !complete {
+				resync, _ := json.Marshal(Message{Type: MessageResync, Payload: nil, Time: time.Now().Unix()})
+				writeStreamEvent(c.Writer, &outbound{data: resync})
+				lastSent = 0
+			}
+			for _, item := range items {
+				writeStreamEvent(c.Writer, item)
+				lastSent = item.id
+			}
+			c.Writer.Flush()
+		}
+
+		for {
+			select {
+			case item, ok := <-client.send:
+				if !ok {
+					// 发送队列满或服务退出，由客户端重连后补发
+					return
+				}
+				if item.id > 0 && item.id <= lastSent {
+					continue
+				}
+				if err := writeStreamEvent(c.Writer, item); err != nil {
+					log.Printf("SSE写入失败: %s, %v", client.clientID, err)
+					return
+				}
+				c.Writer.Flush()
+				if item.id > 0 {
+					lastSent = item.id
+				}
+			case <-c.Request.Context().Done():
+				return
+			case <-stopping:
+				// 通知客户端稍后重连，随机分散避免同时重连
+				_, _ = fmt.Fprintf(c.Writer, "retry: %d\n\n", reconnectAfterMillis())
+				c.Writer.Flush()
+				return
+			}
+		}
+	}
+}
+
+// writeStreamEvent 写入一条SSE消息，按主题发布的消息带上事件id供重连时补发
+func writeStreamEvent(w gin.ResponseWriter, item *outbound) error {
+	if item.id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", item.id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "data: %s\n\n", item.data)
+	return err
+}
@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteStreamEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	// 按主题发布的消息带事件id，心跳等消息没有id
+	assert.NoError(t, writeStreamEvent(c.Writer, &outbound{id: 7, topic: TopicPool, data: []byte(`{"type":"pool_update"}`)}))
+	assert.NoError(t, writeStreamEvent(c.Writer, &outbound{data: []byte(`{"type":"heartbeat"}`)}))
+
+	assert.Equal(t, "id: 7\ndata: {\"type\":\"pool_update\"}\n\ndata: {\"type\":\"heartbeat\"}\n\n", w.Body.String())
+}
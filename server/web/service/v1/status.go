@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"aave_web/config"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// healthCheckTimeout 单项依赖检查的超时时间，避免探针请求被卡住
+const healthCheckTimeout = 2 * time.Second
+
+// CheckHealth 检查MySQL和Redis，ready为true时再检查调度服务的同步进度，全部通过时返回true
+func CheckHealth(ctx context.Context, svcCtx *service.ServerCtx, ready bool) (*v1.Health, bool) {
+	checks := map[string]func(ctx context.Context) error{
+		"mysql": svcCtx.Dao.Ping,
+		"redis": svcCtx.KvStore.Ping,
+	}
+	if ready {
+		checks["indexer"] = func(ctx context.Context) error {
+			return checkIndexer(ctx, svcCtx)
+		}
+	}
+	health := &v1.Health{Status: v1.HealthStatusOk, Checks: make(map[string]string, len(checks))}
+	for name, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := check(checkCtx)
+		cancel()
+		if err != nil {
+			health.Status = v1.HealthStatusFail
+			health.Checks[name] = err.Error()
+			continue
+		}
+		health.Checks[name] = v1.HealthStatusOk
+	}
+	return health, health.Status == v1.HealthStatusOk
+}
+
+// checkIndexer 调度服务没有同步记录、同步停滞或落后过多时返回错误
+// 池子长时间没有事件时lend快照不会更新，不能作为同步是否正常的依据
+func checkIndexer(ctx context.Context, svcCtx *service.ServerCtx) error {
+	indexed, err := svcCtx.Dao.GetIndexedStatus(ctx, svcCtx.C.Chain.ChainID)
+	if err != nil {
+		return errors.Wrap(err, "failed on query indexed status")
+	}
+	if indexed == nil {
+		return errors.New("indexed status not found")
+	}
+	var lag int64
+	if head, err := getChainHead(svcCtx); err == nil {
+		lag = indexLag(head, indexed.LastIndexedBlock)
+	}
+	return indexerDelay(svcCtx.C.Health, indexed, lag, time.Now().Unix())
+}
+
+// indexLag 同步落后的区块数，链上最新区块未知时为0
+func indexLag(head, lastIndexedBlock int64) int64 {
+	if head > lastIndexedBlock {
+		return head - lastIndexedBlock
+	}
+	return 0
+}
+
+// indexerDelay 最后一次同步进展距今或落后区块数超过配置的阈值时返回原因，阈值为0时不检查
+func indexerDelay(conf config.HealthConf, indexed *v1.IndexedStatus, lag, now int64) error {
+	if conf.IndexedMaxAge > 0 {
+		if indexed.LastIndexedTime == 0 {
+			return errors.New("indexer has not reported progress")
+		}
+		if age := now - indexed.LastIndexedTime; age > conf.IndexedMaxAge {
+			return errors.Errorf("indexer last progressed %d seconds ago", age)
+		}
+	}
+	if conf.MaxBlockLag > 0 && lag > conf.MaxBlockLag {
+		return errors.Errorf("indexer is %d blocks behind", lag)
+	}
+	return nil
+}
+
+// GetStatus 获取事件同步状态，链上最新区块读取失败时不影响其余字段
+func GetStatus(ctx context.Context, svcCtx *service.ServerCtx) (*v1.Status, error) {
+	indexed, err := svcCtx.Dao.GetIndexedStatus(ctx, svcCtx.C.Chain.ChainID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query indexed status")
+	}
+	if indexed == nil {
+		return nil, errors.New("indexed status not found")
+	}
+	lastEventTime, err := svcCtx.Dao.GetLatestLendTime(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query latest lend snapshot")
+	}
+	status := &v1.Status{
+		ChainID:          indexed.ChainID,
+		LastIndexedBlock: indexed.LastIndexedBlock,
+		LastIndexedTime:  indexed.LastIndexedTime,
+		LastEventTime:    lastEventTime,
+	}
+	if head, err := getChainHead(svcCtx); err == nil {
+		status.ChainHead = head
+		status.Lag = indexLag(head, indexed.LastIndexedBlock)
+	}
+	status.Delayed = indexerDelay(svcCtx.C.Health, indexed, status.Lag, time.Now().Unix()) != nil
+	return status, nil
+}
+
+// getChainHead 获取节点最新区块，按链上读取的缓存时间缓存，避免前端轮询时频繁请求节点
+func getChainHead(svcCtx *service.ServerCtx) (int64, error) {
+	if svcCtx.Pool == nil {
+		return 0, errors.New("chain reader is not configured")
+	}
+	var head int64
+	err := svcCtx.KvStore.ReadOrGet(fmt.Sprintf("aave:chain:head:%d", svcCtx.C.Chain.ChainID), &head, func() (interface{}, error) {
+		block, err := svcCtx.Pool.BlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		return &block, nil
+	}, chainCacheSeconds(svcCtx))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed on get chain head")
+	}
+	return head, nil
+}
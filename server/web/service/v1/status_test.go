@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"testing"
+
+	"aave_web/config"
+	v1 "aave_web/types/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexerDelay(t *testing.T) {
+	const now = int64(1700000000)
+	conf := config.HealthConf{IndexedMaxAge: 300, MaxBlockLag: 100}
+	tests := []struct {
+		name    string
+		conf    config.HealthConf
+		indexed v1.IndexedStatus
+		lag     int64
+		wantErr bool
+	}{
+		{"fresh", conf, v1.IndexedStatus{LastIndexedTime: now - 10}, 5, false},
+		{"age at limit", conf, v1.IndexedStatus{LastIndexedTime: now - 300}, 0, false},
+		{"stale", conf, v1.IndexedStatus{LastIndexedTime: now - 301}, 0, true},
+		{"never reported", conf, v1.IndexedStatus{}, 0, true},
+		{"lag too large", conf, v1.IndexedStatus{LastIndexedTime: now}, 101, true},
+		// 阈值为0时不检查
+		{"checks disabled", config.HealthConf{}, v1.IndexedStatus{}, 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexed := tt.indexed
+			err := indexerDelay(tt.conf, &indexed, tt.lag, now)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestIndexLag(t *testing.T) {
+	assert.Equal(t, int64(10), indexLag(110, 100))
+	// 链上最新区块未知或落后于已同步区块时为0
+	assert.Equal(t, int64(0), indexLag(0, 100))
+	assert.Equal(t, int64(0), indexLag(90, 100))
+}
@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"aave_web/logger/xzap"
+	"aave_web/stores/xkv"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// TopicPool 存款池状态变化
+	TopicPool = "pool"
+
+	// MessagePoolUpdate 存款池状态变化后推送最新状态
+	MessagePoolUpdate = "pool_update"
+	// MessageResync 重连时要补发的消息已被淘汰，客户端需要通过接口重新拉取最新状态
+	MessageResync = "resync"
+	// MessagePositionUpdate 用户仓位变化后推送最新的链上仓位
+	MessagePositionUpdate = "position_update"
+	// MessageHealthDrop 用户某个抵押代币下的健康因子下降到告警值以下
+	MessageHealthDrop = "health_drop"
+	// MessageLiquidation 用户作为借款人被清算，或作为清算人完成清算
+	MessageLiquidation = "liquidation"
+
+	// userTopicPrefix 用户私有主题的前缀，只有认证为该地址的客户端可以订阅
+	userTopicPrefix = "user:"
+
+	// defaultReplaySize 未配置时每个主题保留的最近消息条数
+	defaultReplaySize = 256
+	// defaultReplayTTL 未配置时持久化的最近消息和序号的过期时间（秒）
+	defaultReplayTTL = 86400
+	// userTopicIdle 用户主题超过该时间没有发布和补发时从内存中淘汰，用户主题随通知过的用户不断增加
+	userTopicIdle = 10 * time.Minute
+)
+
+// publicTopics 所有客户端都可以订阅的主题，未订阅任何主题的客户端接收全部公开主题和自己的用户主题
+var publicTopics = map[string]bool{
+	TopicPool: true,
+}
+
+// outbound 待发送给客户端的消息，id为0的消息（心跳、订阅回复）不进入重放缓冲区
+// id在所有主题间递增，供SSE的Last-Event-ID使用；seq在单个主题内连续递增，供客户端发现丢失的消息
+type outbound struct {
+	id    int64
+	seq   int64
+	topic string
+	data  []byte
+}
+
+// UserTopic 用户私有主题，地址统一小写
+func UserTopic(address string) string {
+	return userTopicPrefix + strings.ToLower(address)
+}
+
+// normalizeTopic 用户主题中的地址统一小写，其它主题原样返回
+func normalizeTopic(name string) string {
+	if address, ok := strings.CutPrefix(name, userTopicPrefix); ok {
+		return UserTopic(address)
+	}
+	return name
+}
+
+// parseTopics 校验客户端订阅的主题，allowed判断客户端是否有权订阅，忽略空字符串，没有主题时返回nil
+func parseTopics(names []string, allowed func(topic string) bool) (map[string]bool, bool) {
+	var topics map[string]bool
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		name = normalizeTopic(name)
+		if !allowed(name) {
+			return nil, false
+		}
+		if topics == nil {
+			topics = make(map[string]bool)
+		}
+		topics[name] = true
+	}
+	return topics, true
+}
+
+// getReplayKey 按实例区分，每个实例只保存自己发布的消息，序号也只在实例内连续
+func getReplayKey(instance, topic string) string {
+	return fmt.Sprintf("aave:ws:replay:%s:%s", instance, topic)
+}
+
+func getSeqKey(instance, topic string) string {
+	return fmt.Sprintf("aave:ws:seq:%s:%s", instance, topic)
+}
+
+// topicLog 单个主题最近发布的消息，floorID、floorSeq之后的消息都保留在缓冲区中
+type topicLog struct {
+	items    []*outbound // 环形缓冲区，用户主题数量多且消息少，按需增长到limit
+	limit    int
+	start    int // 最旧一条消息的位置
+	size     int
+	seq      int64 // 最后发布的序号
+	floorID  int64
+	floorSeq int64
+	used     time.Time // 最后一次发布或补发的时间，用于淘汰空闲的用户主题
+}
+
+func (l *topicLog) add(item *outbound) {
+	if l.size < l.limit {
+		// 未满时没有淘汰过消息，start始终为0
+		l.items = append(l.items, item)
+		l.size++
+	} else {
+		evicted := l.items[l.start]
+		l.floorID, l.floorSeq = evicted.id, evicted.seq
+		l.items[l.start] = item
+		l.start = (l.start + 1) % len(l.items)
+	}
+	l.seq = item.seq
+}
+
+// each 按发布顺序遍历缓冲区中的消息
+func (l *topicLog) each(f func(item *outbound)) {
+	for i := 0; i < l.size; i++ {
+		f(l.items[(l.start+i)%len(l.items)])
+	}
+}
+
+// replayBuffer 按主题保存本实例最近发布的消息，断线重连的客户端按事件id或主题序号补发
+// 配置了持久化时，消息和序号同时写入Redis并设置过期时间，同一实例重启后从Redis恢复；
+// 多个实例各自发布、各自分配序号，客户端重连到其它实例时序号跳变，收到resync后重新拉取
+type replayBuffer struct {
+	mu       sync.Mutex
+	size     int
+	lastID   int64 // 最后发布的事件id，新建的主题从这里开始分配序号
+	store    *xkv.Store
+	instance string
+	ttl      int
+	logs     map[string]*topicLog
+}
+
+// newReplayBuffer store为空时只保存在内存中，服务重启后序号从startID重新开始，
+// 客户端会看到序号跳变，从而知道中间可能丢失了消息
+func newReplayBuffer(size int, startID int64, store *xkv.Store, instance string, ttl int) *replayBuffer {
+	if size <= 0 {
+		size = defaultReplaySize
+	}
+	if ttl <= 0 {
+		ttl = defaultReplayTTL
+	}
+	return &replayBuffer{size: size, lastID: startID, store: store, instance: instance, ttl: ttl, logs: make(map[string]*topicLog)}
+}
+
+// logFor 获取主题的缓冲区，第一次使用或淘汰后再次使用时从Redis恢复，调用方需持有mu
+// 新建的主题从最后发布的事件id开始分配序号，事件id不小于任何主题已经分配过的序号，淘汰后重建的主题序号不会回退
+func (b *replayBuffer) logFor(topic string) *topicLog {
+	l, ok := b.logs[topic]
+	if !ok {
+		l = &topicLog{limit: b.size, seq: b.lastID, floorID: b.lastID, floorSeq: b.lastID}
+		if b.store != nil {
+			b.restore(topic, l)
+		}
+		b.logs[topic] = l
+	}
+	l.used = time.Now()
+	return l
+}
+
+// evictIdle 淘汰空闲的用户主题，公开主题数量固定，一直保留
+func (b *replayBuffer) evictIdle(idle time.Duration) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	evicted := 0
+	for topic, l := range b.logs {
+		if strings.HasPrefix(topic, userTopicPrefix) && time.Since(l.used) > idle {
+			delete(b.logs, topic)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// restore 从Redis恢复主题的序号和最近消息，失败时只使用内存中的缓冲区
+func (b *replayBuffer) restore(topic string, l *topicLog) {
+	logger := xzap.WithContext(context.Background())
+	seq, err := b.store.GetInt64(getSeqKey(b.instance, topic))
+	if err != nil {
+		logger.Warn("failed on get topic seq", zap.String("topic", topic), zap.Error(err))
+		return
+	}
+	if seq == 0 {
+		// 没有持久化过或已过期
+		return
+	}
+	values, err := b.store.Lrange(getReplayKey(b.instance, topic), -b.size, -1)
+	if err != nil {
+		logger.Warn("failed on get topic replay", zap.String("topic", topic), zap.Error(err))
+		return
+	}
+	l.seq, l.floorSeq = seq, seq
+	for _, value := range values {
+		var msg Message
+		if err := json.Unmarshal([]byte(value), &msg); err != nil || msg.Seq == 0 {
+			continue
+		}
+		if l.size == 0 {
+			// 序号在主题内连续，最旧一条之前的消息都已淘汰；事件id不连续，无法确认最旧一条之前是否有淘汰
+			l.floorSeq = msg.Seq - 1
+			l.floorID = msg.ID
+			if msg.Seq == 1 {
+				l.floorID = 0
+			}
+		}
+		l.add(&outbound{id: msg.ID, seq: msg.Seq, topic: topic, data: []byte(value)})
+	}
+	l.seq = max(l.seq, seq)
+}
+
+// nextSeq 分配主题的下一个序号，调用方需要保证同一时间只有一个发布方，分配序号后必须调用add
+func (b *replayBuffer) nextSeq(topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.logFor(topic).seq + 1
+}
+
+// add 追加一条消息，缓冲区满时淘汰最旧的消息
+func (b *replayBuffer) add(item *outbound) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logFor(item.topic).add(item)
+	b.lastID = max(b.lastID, item.id)
+	if b.store == nil {
+		return
+	}
+	logger := xzap.WithContext(context.Background())
+	if err := b.store.PushCapped(getReplayKey(b.instance, item.topic), string(item.data), b.size, b.ttl); err != nil {
+		logger.Warn("failed on persist topic replay", zap.String("topic", item.topic), zap.Error(err))
+	}
+	if err := b.store.SetInt64(getSeqKey(b.instance, item.topic), item.seq, b.ttl); err != nil {
+		logger.Warn("failed on persist topic seq", zap.String("topic", item.topic), zap.Error(err))
+	}
+}
+
+// since 按事件id补发：返回topics中各主题id之后的消息，按id排序
+// complete为false表示中间的消息已被淘汰，或者id之后服务重启过，客户端需要重新拉取最新状态
+func (b *replayBuffer) since(id int64, topics []string) ([]*outbound, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	complete := true
+	var items []*outbound
+	for _, topic := range topics {
+		l := b.logFor(topic)
+		if id < l.floorID {
+			complete = false
+		}
+		l.each(func(item *outbound) {
+			if item.id > id {
+				items = append(items, item)
+			}
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].id < items[j].id
+	})
+	return items, complete
+}
+
+// after 按主题序号补发：返回主题seq之后的消息
+// complete为false表示中间的消息已被淘汰，或者seq不是本主题发出的（服务重启过），客户端需要重新拉取最新状态
+func (b *replayBuffer) after(topic string, seq int64) ([]*outbound, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l := b.logFor(topic)
+	var items []*outbound
+	l.each(func(item *outbound) {
+		if item.seq > seq {
+			items = append(items, item)
+		}
+	})
+	return items, seq >= l.floorSeq && seq <= l.seq
+}
+
+// lastSeq 主题最后发布的序号
+func (b *replayBuffer) lastSeq(topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.logFor(topic).seq
+}
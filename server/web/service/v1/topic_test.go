@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"aave_web/stores/xkv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTopics(t *testing.T) {
+	allowed := func(topic string) bool {
+		return topic == TopicPool || topic == "user:0xabc"
+	}
+	tests := []struct {
+		name   string
+		names  []string
+		want   map[string]bool
+		wantOk bool
+	}{
+		{"empty", []string{""}, nil, true},
+		{"public", []string{"pool", ""}, map[string]bool{"pool": true}, true},
+		// 用户主题中的地址统一小写后再校验
+		{"user topic", []string{"pool", "user:0xABC"}, map[string]bool{"pool": true, "user:0xabc": true}, true},
+		{"other user", []string{"pool", "user:0xdef"}, nil, false},
+		{"unknown", []string{"prices"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTopics(tt.names, allowed)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// newTestLog 按顺序发布ids对应的消息，seq从1开始
+func newTestLog(limit int, ids ...int64) *topicLog {
+	l := &topicLog{limit: limit}
+	for i, id := range ids {
+		l.add(&outbound{id: id, seq: int64(i + 1), topic: TopicPool})
+	}
+	return l
+}
+
+func TestTopicLogAdd(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		ids       []int64
+		wantIDs   []int64
+		wantFloor int64 // 最后淘汰的序号
+	}{
+		{"not full", 3, []int64{10, 11}, []int64{10, 11}, 0},
+		{"full", 3, []int64{10, 11, 12}, []int64{10, 11, 12}, 0},
+		{"wrap once", 3, []int64{10, 11, 12, 13}, []int64{11, 12, 13}, 1},
+		// 环形缓冲区绕回多圈后仍按发布顺序遍历
+		{"wrap twice", 3, []int64{10, 11, 12, 13, 14, 15, 16}, []int64{14, 15, 16}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLog(tt.limit, tt.ids...)
+			var ids []int64
+			l.each(func(item *outbound) {
+				ids = append(ids, item.id)
+			})
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantFloor, l.floorSeq)
+			assert.Equal(t, int64(len(tt.ids)), l.seq)
+		})
+	}
+}
+
+func TestReplayBufferAfter(t *testing.T) {
+	tests := []struct {
+		name         string
+		seq          int64
+		wantSeqs     []int64
+		wantComplete bool
+	}{
+		{"up to date", 5, nil, true},
+		{"in buffer", 3, []int64{4, 5}, true},
+		{"at floor", 2, []int64{3, 4, 5}, true},
+		// 序号2已被淘汰
+		{"evicted", 1, []int64{3, 4, 5}, false},
+		// 序号不是本主题发出的
+		{"ahead", 6, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newReplayBuffer(3, 0, nil, "", 0)
+			b.logs[TopicPool] = newTestLog(3, 10, 11, 12, 13, 14)
+			items, complete := b.after(TopicPool, tt.seq)
+			var seqs []int64
+			for _, item := range items {
+				seqs = append(seqs, item.seq)
+			}
+			assert.Equal(t, tt.wantSeqs, seqs)
+			assert.Equal(t, tt.wantComplete, complete)
+		})
+	}
+}
+
+func TestReplayBufferSince(t *testing.T) {
+	b := newReplayBuffer(3, 0, nil, "", 0)
+	b.logs[TopicPool] = newTestLog(3, 10, 12, 14, 16)
+	b.logs["user:0xabc"] = newTestLog(3, 11, 15)
+	topics := []string{TopicPool, "user:0xabc"}
+
+	// 多个主题的消息按事件id排序
+	items, complete := b.since(11, topics)
+	var ids []int64
+	for _, item := range items {
+		ids = append(ids, item.id)
+	}
+	assert.Equal(t, []int64{12, 14, 15, 16}, ids)
+	assert.True(t, complete)
+
+	// pool主题中id为10的消息已被淘汰
+	_, complete = b.since(9, topics)
+	assert.False(t, complete)
+}
+
+func TestReplayBufferEvictIdle(t *testing.T) {
+	b := newReplayBuffer(3, 100, nil, "", 0)
+	user := UserTopic("0xabc")
+	seq := b.nextSeq(user)
+	b.add(&outbound{id: 101, seq: seq, topic: user})
+	b.add(&outbound{id: 102, seq: b.nextSeq(TopicPool), topic: TopicPool})
+	b.logs[user].used = time.Now().Add(-2 * userTopicIdle)
+	b.logs[TopicPool].used = time.Now().Add(-2 * userTopicIdle)
+
+	// 只淘汰用户主题
+	assert.Equal(t, 1, b.evictIdle(userTopicIdle))
+	assert.NotContains(t, b.logs, user)
+	assert.Contains(t, b.logs, TopicPool)
+
+	// 重建的主题从最后发布的事件id开始分配序号，不会回退，补发时提示重新拉取
+	assert.Greater(t, b.nextSeq(user), seq)
+	_, complete := b.after(user, seq)
+	assert.False(t, complete)
+}
+
+func TestReplayBufferRestore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := xkv.NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}})
+	user := UserTopic("0xabc")
+	b := newReplayBuffer(3, 100, store, "web-0", 60)
+	for id := int64(101); id <= 104; id++ {
+		seq := b.nextSeq(user)
+		msg, _ := json.Marshal(Message{Type: MessagePositionUpdate, Topic: user, ID: id, Seq: seq})
+		b.add(&outbound{id: id, seq: seq, topic: user, data: msg})
+	}
+	// 持久化的key按实例区分并设置过期时间
+	assert.Equal(t, 60*time.Second, mr.TTL(getReplayKey("web-0", user)))
+	assert.Equal(t, 60*time.Second, mr.TTL(getSeqKey("web-0", user)))
+
+	// 同一实例重启后从Redis恢复，序号继续递增
+	restarted := newReplayBuffer(3, 1000, store, "web-0", 60)
+	items, complete := restarted.after(user, 102)
+	assert.True(t, complete)
+	assert.Len(t, items, 2)
+	assert.Equal(t, int64(105), restarted.nextSeq(user))
+
+	// 其它实例不读取本实例的消息，从自己的事件id开始分配序号
+	other := newReplayBuffer(3, 2000, store, "web-1", 60)
+	_, complete = other.after(user, 104)
+	assert.False(t, complete)
+	assert.Equal(t, int64(2001), other.nextSeq(user))
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"aave_web/dao"
+	"aave_web/service"
+	v1 "aave_web/types/v1"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// GetPoolTransactionStats 按方法统计pool合约交易的数量、失败率和gas消耗，用于了解用户实际支付的手续费
+func GetPoolTransactionStats(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.PoolTransactionFilter) ([]*v1.PoolTransactionStats, error) {
+	items, err := svcCtx.Dao.GetPoolTransactionStats(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query pool transaction stats")
+	}
+	for _, item := range items {
+		item.FailureRate = failureRate(item.FailedCount, item.Count)
+	}
+	return items, nil
+}
+
+// GetFailedPoolTransactions 分页获取失败的pool合约交易，按链上顺序倒序
+func GetFailedPoolTransactions(ctx context.Context, svcCtx *service.ServerCtx, filter *dao.PoolTransactionFilter) (*v1.PoolTransactionPage, error) {
+	items, err := svcCtx.Dao.GetFailedPoolTransactions(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query failed pool transactions")
+	}
+	page := &v1.PoolTransactionPage{Items: items}
+	if len(items) == filter.Limit {
+		last := items[len(items)-1]
+		page.NextCursor = EncodeLogCursor(last.BlockNumber, last.TxIndex)
+	}
+	return page, nil
+}
+
+// failureRate 失败交易占比，百分比，保留四位小数
+func failureRate(failed, total int64) string {
+	if total <= 0 {
+		return "0"
+	}
+	return decimal.NewFromInt(failed).Mul(decimal.NewFromInt(100)).DivRound(decimal.NewFromInt(total), 4).StringFixed(4)
+}
@@ -1,16 +1,24 @@
 package v1
 
 import (
+	"aave_web/errcode"
 	"aave_web/service"
+	"aave_web/stores/xkv"
+	"aave_web/xhttp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"log"
+	"math/rand"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 )
 
 // 定义WebSocket升级器配置
@@ -20,41 +28,261 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// Client 代表一个WebSocket客户端
+const (
+	// wsReconnectMinMillis、wsReconnectMaxMillis 退出时建议客户端重连的等待时间范围，随机分散避免同时重连
+	wsReconnectMinMillis = 1000
+	wsReconnectMaxMillis = 5000
+	// wsCloseWriteTimeout 发送关闭帧的超时时间
+	wsCloseWriteTimeout = time.Second
+)
+
+// Client 代表一个WebSocket或SSE客户端，SSE客户端的conn为空
 type Client struct {
 	conn     *websocket.Conn
-	send     chan []byte
+	send     chan *outbound
 	clientID string
+
+	mu     sync.Mutex
+	closed bool                          // send已关闭，订阅的推送不再写入
+	subs   map[string]context.CancelFunc // 按订阅id取消订阅
+	topics map[string]bool               // 订阅的主题，为nil时接收全部公开主题和自己的用户主题
+	users  map[string]bool               // 通过session_id认证的用户地址，小写
+}
+
+// permitted 客户端是否有权订阅该主题，调用方需持有mu
+func (c *Client) permitted(topic string) bool {
+	if publicTopics[topic] {
+		return true
+	}
+	address, ok := strings.CutPrefix(topic, userTopicPrefix)
+	return ok && c.users[address]
+}
+
+// canSubscribe 客户端是否有权订阅该主题，用户主题只有认证为该地址的客户端可以订阅
+func (c *Client) canSubscribe(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.permitted(topic)
+}
+
+// authenticate 记录认证的用户地址，返回客户端可以订阅的用户主题
+func (c *Client) authenticate(addresses []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.users == nil {
+		c.users = make(map[string]bool)
+	}
+	for _, address := range addresses {
+		c.users[strings.ToLower(address)] = true
+	}
+	return c.userTopics()
+}
+
+// userTopics 客户端可以订阅的用户主题，调用方需持有mu
+func (c *Client) userTopics() []string {
+	topics := make([]string, 0, len(c.users))
+	for address := range c.users {
+		topics = append(topics, UserTopic(address))
+	}
+	return topics
+}
+
+// defaultTopics 未订阅过主题时接收的主题：全部公开主题和自己的用户主题，调用方需持有mu
+func (c *Client) defaultTopics() map[string]bool {
+	topics := make(map[string]bool, len(publicTopics)+len(c.users))
+	for topic := range publicTopics {
+		topics[topic] = true
+	}
+	for _, topic := range c.userTopics() {
+		topics[topic] = true
+	}
+	return topics
+}
+
+// wants 客户端是否接收该主题的消息，没有主题的消息（心跳）发给所有客户端
+func (c *Client) wants(topic string) bool {
+	if topic == "" {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.topics == nil {
+		return c.permitted(topic)
+	}
+	return c.topics[topic]
+}
+
+// topicList 客户端接收的所有主题
+func (c *Client) topicList() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topics := c.topics
+	if topics == nil {
+		topics = c.defaultTopics()
+	}
+	list := make([]string, 0, len(topics))
+	for topic := range topics {
+		list = append(list, topic)
+	}
+	return list
+}
+
+// setTopics 订阅或取消订阅主题，返回当前订阅的主题
+func (c *Client) setTopics(topics map[string]bool, subscribe bool) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.topics == nil {
+		c.topics = make(map[string]bool)
+		if !subscribe {
+			c.topics = c.defaultTopics()
+		}
+	}
+	for topic := range topics {
+		if subscribe {
+			c.topics[topic] = true
+		} else {
+			delete(c.topics, topic)
+		}
+	}
+	current := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		current = append(current, topic)
+	}
+	return current
+}
+
+// trySend 发送队列未关闭且未满时写入消息
+func (c *Client) trySend(message *outbound) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend 关闭发送队列，可重复调用
+func (c *Client) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// cancelSubs 连接断开时取消所有订阅
+func (c *Client) cancelSubs() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, cancel := range c.subs {
+		cancel()
+		delete(c.subs, id)
+	}
+}
+
+// Subscriber 执行客户端通过WebSocket发起的订阅，如GraphQL订阅，
+// payload为客户端消息中的payload，返回的通道在ctx取消或订阅结束时关闭
+type Subscriber interface {
+	Subscribe(ctx context.Context, payload json.RawMessage) (<-chan interface{}, error)
+}
+
+// Authenticator 校验session_id，返回登录的用户地址，与HTTP接口的登录校验一致
+type Authenticator func(sessionID string) ([]string, error)
+
+// sessionIDHeader 与HTTP接口相同的登录凭证请求头，多个session_id用逗号分隔
+const sessionIDHeader = "session_id"
+
+// subscription 订阅消息中的id，同一连接内唯一
+type subscription struct {
+	ID string `json:"id"`
 }
 
-// Message 定义消息结构
+// Message 定义消息结构，Topic、ID、Seq只在按主题发布的消息中出现
+// Seq在主题内连续递增，客户端发现序号不连续时可以发送resume补发
 type Message struct {
 	Type    string      `json:"type"`
+	Topic   string      `json:"topic,omitempty"`
+	ID      int64       `json:"id,omitempty"`
+	Seq     int64       `json:"seq,omitempty"`
 	Payload interface{} `json:"payload"`
 	Time    int64       `json:"timestamp"`
 }
 
+// topicRequest subscribe、unsubscribe消息的payload
+type topicRequest struct {
+	Topics []string `json:"topics"`
+}
+
+// authRequest auth消息的payload，用于升级时无法设置请求头的浏览器客户端
+type authRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// resumeRequest resume消息的payload，Seq为客户端在该主题最后收到的序号
+type resumeRequest struct {
+	Topic string `json:"topic"`
+	Seq   int64  `json:"seq"`
+}
+
 // WSServer WebSocket服务器
 type WSServer struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
+	broadcast  chan *outbound
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
 	svcCtx     *service.ServerCtx
+	closing    bool          // 正在退出，不再接受新连接
+	quit       chan struct{} // 关闭后Run退出
+	subscriber Subscriber    // 为空时不支持graphql_subscribe
+	authorize  Authenticator // 为空时不支持认证，客户端只能订阅公开主题
+
+	publishMu sync.Mutex    // 保证事件id、序号的分配与写入重放缓冲区、广播通道的顺序一致
+	lastID    int64         // 最后发布的事件id
+	replay    *replayBuffer // 各主题最近发布的消息，供SSE按Last-Event-ID、WebSocket按resume补发
+}
+
+// instanceName 本实例在Redis key中的名称，未配置时使用主机名，重启后需要保持不变
+func instanceName(svcCtx *service.ServerCtx) string {
+	if svcCtx.C != nil && svcCtx.C.Ws.Instance != "" {
+		return svcCtx.C.Ws.Instance
+	}
+	instance, _ := os.Hostname()
+	return instance
 }
 
 // NewWSServer 新建WebSocket服务器
 func NewWSServer(svcCtx *service.ServerCtx) *WSServer {
+	startID := time.Now().UnixMilli()
+	var store *xkv.Store
+	var instance string
+	var ttl int
+	if svcCtx.C != nil && svcCtx.C.Ws.PersistReplay {
+		store = svcCtx.KvStore
+		instance, ttl = instanceName(svcCtx), svcCtx.C.Ws.ReplayTTL
+	}
 	wsServer := &WSServer{
-		broadcast:  make(chan []byte, 100),
+		broadcast:  make(chan *outbound, 100),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
 		svcCtx:     svcCtx,
+		quit:       make(chan struct{}),
+		// 事件id从启动时的毫秒时间戳开始递增，重启后不会与之前发出的id重复
+		lastID: startID,
+		replay: newReplayBuffer(replaySize(svcCtx), startID, store, instance, ttl),
 	}
 	// 启动（注册、注销、广播）
 	go wsServer.Run()
+	if svcCtx.Lifecycle != nil {
+		svcCtx.Lifecycle.OnStop("websocket", wsServer.Shutdown)
+	}
 	return wsServer
 }
 
@@ -67,6 +295,10 @@ func (s *WSServer) Run() {
 
 	for {
 		select {
+		case <-s.quit:
+			log.Println("WebSocket服务器后台任务退出")
+			return
+
 		case client := <-s.register:
 			s.mu.Lock()
 			s.clients[client] = true
@@ -77,29 +309,32 @@ func (s *WSServer) Run() {
 			s.mu.Lock()
 			if _, ok := s.clients[client]; ok {
 				delete(s.clients, client)
-				close(client.send)
+				client.closeSend()
 			}
 			s.mu.Unlock()
 			log.Printf("客户端断开: %s, 剩余连接数: %d", client.clientID, len(s.clients))
 
 		case message := <-s.broadcast:
-			s.mu.RLock()
+			s.mu.Lock()
 			clientCount := len(s.clients)
 			if clientCount > 0 {
 				for client := range s.clients {
-					select {
-					case client.send <- message:
-						// 消息发送成功
-					default:
+					if !client.wants(message.topic) {
+						continue
+					}
+					if !client.trySend(message) {
 						// 发送队列满，关闭连接
-						close(client.send)
+						client.closeSend()
 						delete(s.clients, client)
 					}
 				}
 			}
-			s.mu.RUnlock()
+			s.mu.Unlock()
 
 		case <-ticker.C:
+			if evicted := s.replay.evictIdle(userTopicIdle); evicted > 0 {
+				log.Printf("淘汰空闲的用户主题: %d", evicted)
+			}
 			// 定时发送心跳
 			s.mu.RLock()
 			clientCount := len(s.clients)
@@ -110,7 +345,7 @@ func (s *WSServer) Run() {
 					Time:    time.Now().Unix(),
 				}
 				msgBytes, _ := json.Marshal(heartbeatMsg)
-				s.broadcast <- msgBytes
+				s.broadcast <- &outbound{data: msgBytes}
 				log.Printf("发送心跳到 %d 个客户端", clientCount)
 			}
 			s.mu.RUnlock()
@@ -118,6 +353,101 @@ func (s *WSServer) Run() {
 	}
 }
 
+// Shutdown 向所有客户端发送服务重启的关闭帧，原因中带上建议的重连等待时间，
+// 等待客户端回应关闭帧后断开，ctx到期时强制关闭剩余连接，最后停止后台任务
+func (s *WSServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	clients := make([]*Client, 0, len(s.clients))
+	for client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.mu.Unlock()
+
+	// 关闭帧可以与writePump并发写入，SSE客户端在开始退出时已自行结束
+	for _, client := range clients {
+		if client.conn == nil {
+			continue
+		}
+		reason := fmt.Sprintf(`{"reconnect_after_ms":%d}`, reconnectAfterMillis())
+		err := client.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseServiceRestart, reason),
+			time.Now().Add(wsCloseWriteTimeout))
+		if err != nil {
+			log.Printf("发送关闭帧失败: %s, %v", client.clientID, err)
+		}
+	}
+	log.Printf("已通知 %d 个客户端重连", len(clients))
+
+	// 客户端回应关闭帧后readPump退出并注销
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	var err error
+wait:
+	for {
+		s.mu.RLock()
+		remaining := len(s.clients)
+		s.mu.RUnlock()
+		if remaining == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break wait
+		case <-ticker.C:
+		}
+	}
+	s.mu.RLock()
+	for client := range s.clients {
+		if client.conn != nil {
+			_ = client.conn.Close()
+		} else {
+			client.closeSend()
+		}
+	}
+	s.mu.RUnlock()
+	close(s.quit)
+	return err
+}
+
+func replaySize(svcCtx *service.ServerCtx) int {
+	if svcCtx.C != nil && svcCtx.C.Ws.ReplaySize > 0 {
+		return svcCtx.C.Ws.ReplaySize
+	}
+	return defaultReplaySize
+}
+
+// reconnectAfterMillis 建议客户端重连前等待的时间，随机分散避免同时重连
+func reconnectAfterMillis() int {
+	return wsReconnectMinMillis + rand.Intn(wsReconnectMaxMillis-wsReconnectMinMillis)
+}
+
+// SetSubscriber 设置WebSocket订阅的执行方，需要在接受连接前设置
+func (s *WSServer) SetSubscriber(subscriber Subscriber) {
+	s.subscriber = subscriber
+}
+
+// SetAuthenticator 设置session_id的校验方，需要在接受连接前设置
+func (s *WSServer) SetAuthenticator(authorize Authenticator) {
+	s.authorize = authorize
+}
+
+// authenticate 校验session_id并记录到客户端，返回客户端可以订阅的用户主题
+func (s *WSServer) authenticate(client *Client, sessionID string) ([]string, error) {
+	if s.authorize == nil {
+		return nil, errors.New("authentication is not supported")
+	}
+	addresses, err := s.authorize(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, errors.New("no user in session")
+	}
+	return client.authenticate(addresses), nil
+}
+
 // BroadcastJSON 广播JSON消息
 func (s *WSServer) BroadcastJSON(v interface{}) {
 	message, err := json.Marshal(v)
@@ -127,16 +457,84 @@ func (s *WSServer) BroadcastJSON(v interface{}) {
 	}
 
 	select {
-	case s.broadcast <- message:
+	case s.broadcast <- &outbound{data: message}:
 		// 消息成功放入广播通道
 	default:
 		log.Printf("广播通道已满，丢弃消息")
 	}
 }
 
+// Publish 向订阅了topic的WebSocket和SSE客户端推送消息，消息分配递增的事件id和主题内的序号，并写入重放缓冲区
+func (s *WSServer) Publish(topic, msgType string, payload interface{}) {
+	// 先编码payload，避免分配了序号的消息编码失败，客户端误以为丢失了消息
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("JSON编码错误: %v", err)
+		return
+	}
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+	s.lastID++
+	id, seq := s.lastID, s.replay.nextSeq(topic)
+	message, _ := json.Marshal(Message{Type: msgType, Topic: topic, ID: id, Seq: seq, Payload: json.RawMessage(raw), Time: time.Now().Unix()})
+	item := &outbound{id: id, seq: seq, topic: topic, data: message}
+	s.replay.add(item)
+	select {
+	case s.broadcast <- item:
+		// 消息成功放入广播通道
+	default:
+		log.Printf("广播通道已满，丢弃消息: %s", topic)
+	}
+}
+
+// resume 补发主题中客户端最后收到的序号之后的消息，中间的消息已被淘汰时先推送resync
+// 补发期间暂停发布，补发的消息之后不会再收到更早的序号，但可能重复收到已补发的序号，客户端按seq去重
+func (s *WSServer) resume(client *Client, req resumeRequest) {
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+	items, complete := s.replay.after(req.Topic, req.Seq)
+	if !complete {
+		sendJSON(client, MessageResync, topicRequest{Topics: []string{req.Topic}})
+	}
+	for _, item := range items {
+		if !client.trySend(item) {
+			log.Printf("客户端 %s 发送队列已满，停止补发: %s", client.clientID, req.Topic)
+			return
+		}
+	}
+	sendJSON(client, "resumed", resumeRequest{Topic: req.Topic, Seq: s.replay.lastSeq(req.Topic)})
+}
+
+// unregisterClient 注销客户端，后台任务已退出时直接返回
+func (s *WSServer) unregisterClient(client *Client) {
+	select {
+	case s.unregister <- client:
+	case <-s.quit:
+	}
+}
+
 // HandleWebSocket 处理WebSocket连接
 func HandleWebSocket(s *WSServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		s.mu.RLock()
+		closing := s.closing
+		s.mu.RUnlock()
+		if closing {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		// 升级时带session_id的客户端直接认证，校验失败时拒绝升级；也可以连接后发送auth消息认证
+		client := &Client{
+			send: make(chan *outbound, 256),
+			subs: make(map[string]context.CancelFunc),
+		}
+		if sessionID := c.GetHeader(sessionIDHeader); sessionID != "" {
+			if _, err := s.authenticate(client, sessionID); err != nil {
+				log.Printf("WebSocket认证失败: %v", err)
+				xhttp.Error(c, errcode.ErrTokenVerify)
+				return
+			}
+		}
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			log.Printf("WebSocket升级失败: %v", err)
@@ -144,11 +542,8 @@ func HandleWebSocket(s *WSServer) gin.HandlerFunc {
 		}
 
 		clientID := fmt.Sprintf("%s-%d", c.Request.RemoteAddr, time.Now().UnixNano())
-		client := &Client{
-			conn:     conn,
-			send:     make(chan []byte, 256),
-			clientID: clientID,
-		}
+		client.conn = conn
+		client.clientID = clientID
 
 		s.register <- client
 
@@ -191,24 +586,14 @@ func (s *WSServer) writePump(client *Client) {
 			if err != nil {
 				return
 			}
-			write, err := w.Write(message)
+			write, err := w.Write(message.data)
 			if err != nil {
 				log.Printf("<UNK>: %v", err)
 				return
 			}
 			log.Printf("write bytes: %d", write)
 
-			// 写入队列中的其他消息
-			n := len(client.send)
-			for i := 0; i < n; i++ {
-				write, err := w.Write(<-client.send)
-				if err != nil {
-					log.Printf("<UNK>: %v", err)
-					return
-				}
-				log.Printf("write bytes: %d", write)
-			}
-
+			// 每条消息单独一帧，多条JSON写在同一帧中客户端无法解析
 			if err := w.Close(); err != nil {
 				return
 			}
@@ -230,7 +615,8 @@ func (s *WSServer) writePump(client *Client) {
 // 读goroutine
 func (s *WSServer) readPump(client *Client) {
 	defer func() {
-		s.unregister <- client
+		client.cancelSubs()
+		s.unregisterClient(client)
 		err := client.conn.Close()
 		if err != nil {
 			log.Printf("<UNK>: %v", err)
@@ -283,25 +669,155 @@ func (s *WSServer) handleMessage(client *Client, msg Message) {
 			Time:    time.Now().Unix(),
 		}
 		msgBytes, _ := json.Marshal(response)
-		client.send <- msgBytes
+		client.trySend(&outbound{data: msgBytes})
 		log.Printf("客户端 %s 发送ping", client.clientID)
 
-	case "subscribe":
-		// 处理订阅请求
-		log.Printf("客户端 %s 订阅: %v", client.clientID, msg.Payload)
-		response := Message{
-			Type:    "subscribed",
-			Payload: msg.Payload,
-			Time:    time.Now().Unix(),
+	case "subscribe", "unsubscribe":
+		// 订阅或取消订阅主题，payload为 {"topics": ["pool"]}，从未订阅过的客户端接收全部公开主题和自己的用户主题
+		// 用户主题 user:<地址> 需要先认证为该地址，否则返回unknown topic
+		log.Printf("客户端 %s %s: %v", client.clientID, msg.Type, msg.Payload)
+		var req topicRequest
+		if err := decodePayload(msg.Payload, &req); err != nil {
+			sendJSON(client, "error", "invalid topics")
+			return
+		}
+		topics, ok := parseTopics(req.Topics, client.canSubscribe)
+		if !ok {
+			sendJSON(client, "error", "unknown topic")
+			return
+		}
+		current := client.setTopics(topics, msg.Type == "subscribe")
+		sendJSON(client, msg.Type+"d", topicRequest{Topics: current})
+
+	case "resume":
+		// 补发主题中seq之后的消息，payload为 {"topic": "pool", "seq": 12}
+		var req resumeRequest
+		if err := decodePayload(msg.Payload, &req); err != nil || req.Seq < 0 {
+			sendJSON(client, "error", "invalid resume")
+			return
+		}
+		req.Topic = normalizeTopic(req.Topic)
+		if !client.canSubscribe(req.Topic) {
+			sendJSON(client, "error", "invalid resume")
+			return
+		}
+		s.resume(client, req)
+
+	case "auth":
+		// 认证用户，payload为 {"session_id": "..."}，成功后回复可以订阅的用户主题
+		var req authRequest
+		if err := decodePayload(msg.Payload, &req); err != nil || req.SessionID == "" {
+			sendJSON(client, "error", "invalid auth")
+			return
+		}
+		topics, err := s.authenticate(client, req.SessionID)
+		if err != nil {
+			log.Printf("客户端 %s 认证失败: %v", client.clientID, err)
+			sendJSON(client, "error", "auth failed")
+			return
+		}
+		sendJSON(client, "authed", topicRequest{Topics: topics})
+
+	case "graphql_subscribe":
+		s.subscribe(client, msg.Payload)
+
+	case "graphql_unsubscribe":
+		var sub subscription
+		if decodePayload(msg.Payload, &sub) == nil {
+			client.mu.Lock()
+			if cancel, ok := client.subs[sub.ID]; ok {
+				cancel()
+				delete(client.subs, sub.ID)
+			}
+			client.mu.Unlock()
 		}
-		msgBytes, _ := json.Marshal(response)
-		client.send <- msgBytes
 
 	default:
 		log.Printf("收到未知消息类型: %s, 内容: %v", msg.Type, msg.Payload)
 	}
 }
 
+// subscribe 开始一个订阅，结果以graphql_data推送，订阅结束时推送graphql_complete，
+// 请求有误时推送graphql_error
+func (s *WSServer) subscribe(client *Client, payload interface{}) {
+	raw, err := json.Marshal(payload)
+	var sub subscription
+	if err == nil {
+		err = json.Unmarshal(raw, &sub)
+	}
+	if err != nil || sub.ID == "" {
+		sendJSON(client, "graphql_error", map[string]interface{}{"id": sub.ID, "message": "invalid subscription"})
+		return
+	}
+	if s.subscriber == nil {
+		sendJSON(client, "graphql_error", map[string]interface{}{"id": sub.ID, "message": "subscription is not supported"})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.mu.Lock()
+	_, exists := client.subs[sub.ID]
+	closed := client.closed
+	if !exists && !closed {
+		client.subs[sub.ID] = cancel
+	}
+	client.mu.Unlock()
+	if exists || closed {
+		cancel()
+		if exists {
+			sendJSON(client, "graphql_error", map[string]interface{}{"id": sub.ID, "message": "subscription id already exists"})
+		}
+		return
+	}
+
+	results, err := s.subscriber.Subscribe(ctx, raw)
+	if err != nil {
+		cancel()
+		s.endSubscription(client, sub.ID)
+		sendJSON(client, "graphql_error", map[string]interface{}{"id": sub.ID, "message": err.Error()})
+		return
+	}
+	go func() {
+		defer cancel()
+		for result := range results {
+			sendJSON(client, "graphql_data", map[string]interface{}{"id": sub.ID, "result": result})
+		}
+		s.endSubscription(client, sub.ID)
+		sendJSON(client, "graphql_complete", map[string]interface{}{"id": sub.ID})
+	}()
+}
+
+// endSubscription 移除并取消订阅
+func (s *WSServer) endSubscription(client *Client, id string) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if cancel, ok := client.subs[id]; ok {
+		cancel()
+		delete(client.subs, id)
+	}
+}
+
+// sendJSON 向单个客户端发送消息，队列已满或连接已关闭时丢弃
+func sendJSON(client *Client, msgType string, payload interface{}) {
+	msgBytes, err := json.Marshal(Message{Type: msgType, Payload: payload, Time: time.Now().Unix()})
+	if err != nil {
+		log.Printf("JSON编码错误: %v", err)
+		return
+	}
+	if !client.trySend(&outbound{data: msgBytes}) {
+		log.Printf("客户端 %s 发送队列不可用，丢弃消息: %s", client.clientID, msgType)
+	}
+}
+
+// decodePayload 将客户端消息中的payload解析到结构体
+func decodePayload(payload interface{}, v interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
 // PushService 推送服务
 type PushService struct {
 	wsServer *WSServer
@@ -336,7 +852,7 @@ func (ps *PushService) StartDataPush() {
 
 			if clientCount > 0 {
 				data := map[string]interface{}{
-					"price":    fmt.Sprintf("%.2f", 10000+100*(time.Now().Second()%60)),
+					"price":    fmt.Sprintf("%.2f", float64(10000+100*(time.Now().Second()%60))),
 					"volume":   time.Now().Unix() % 10000,
 					"market":   "BTC/USDT",
 					"updateAt": time.Now().Format("15:04:05"),
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drain 读取客户端发送队列中的所有消息
+func drain(client *Client) []Message {
+	var messages []Message
+	for {
+		select {
+		case item := <-client.send:
+			var msg Message
+			_ = json.Unmarshal(item.data, &msg)
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestWSServerResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		seq        int64
+		wantResync bool
+		wantSeqs   []int64
+	}{
+		{"in buffer", 1003, false, []int64{1004, 1005}},
+		{"up to date", 1005, false, nil},
+		// 序号1002已被淘汰，先推送resync再补发缓冲区中的消息
+		{"evicted", 1001, true, []int64{1003, 1004, 1005}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &WSServer{lastID: 1000, replay: newReplayBuffer(3, 1000, nil, "", 0)}
+			for i := 0; i < 5; i++ {
+				s.Publish(TopicPool, MessagePoolUpdate, i)
+			}
+			client := &Client{send: make(chan *outbound, 16)}
+			s.resume(client, resumeRequest{Topic: TopicPool, Seq: tt.seq})
+
+			messages := drain(client)
+			if !assert.NotEmpty(t, messages) {
+				return
+			}
+			assert.Equal(t, tt.wantResync, messages[0].Type == MessageResync)
+			var seqs []int64
+			for _, msg := range messages {
+				if msg.Type == MessagePoolUpdate {
+					seqs = append(seqs, msg.Seq)
+				}
+			}
+			assert.Equal(t, tt.wantSeqs, seqs)
+			// 最后回复主题当前的序号
+			last := messages[len(messages)-1]
+			assert.Equal(t, "resumed", last.Type)
+			assert.Equal(t, float64(1005), last.Payload.(map[string]interface{})["seq"])
+		})
+	}
+}
+
+func TestClientPermitted(t *testing.T) {
+	client := &Client{}
+	// 未认证的客户端只能订阅公开主题
+	assert.True(t, client.canSubscribe(TopicPool))
+	assert.False(t, client.canSubscribe(UserTopic("0xabc")))
+
+	topics := client.authenticate([]string{"0xABC"})
+	assert.Equal(t, []string{"user:0xabc"}, topics)
+	tests := []struct {
+		topic string
+		want  bool
+	}{
+		{TopicPool, true},
+		{"user:0xabc", true},
+		{"user:0xdef", false},
+		{"user:", false},
+		{"prices", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			assert.Equal(t, tt.want, client.canSubscribe(tt.topic))
+		})
+	}
+
+	// 未订阅过主题时接收公开主题和自己的用户主题
+	assert.True(t, client.wants(UserTopic("0xABC")))
+	assert.False(t, client.wants(UserTopic("0xdef")))
+	assert.ElementsMatch(t, []string{TopicPool, "user:0xabc"}, client.topicList())
+}
@@ -2,12 +2,14 @@ package xkv
 
 import (
 	"aave_web/kit/convert"
+	"context"
 	"encoding/json"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/kv"
 	"github.com/zeromicro/go-zero/core/stores/redis"
 	"log"
 	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -19,6 +21,36 @@ if (current) then
     redis.call('DEL', KEYS[1]);
 end
 return current;`
+
+	// takeTokenScript 令牌桶取令牌lua脚本，ARGV为每秒补充令牌数、桶容量和当前毫秒时间戳
+	// 返回是否取到令牌和剩余令牌数
+	takeTokenScript = `local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens)}`
+
+	// pushCappedScript 追加到列表尾部并只保留最后ARGV[2]条，ARGV[3]大于0时重置过期时间（秒），返回列表长度
+	pushCappedScript = `redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('LTRIM', KEYS[1], -tonumber(ARGV[2]), -1)
+if tonumber(ARGV[3]) > 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[3])
+end
+return redis.call('LLEN', KEYS[1])`
 )
 
 // Store 键值存取器结构详情
@@ -152,6 +184,87 @@ func (s *Store) ReadOrGet(key string, obj interface{}, gf GetFunc, seconds ...in
 	return nil
 }
 
+// TakeToken 从给定key的令牌桶中取一个令牌，rate为每秒补充的令牌数，burst为桶容量
+// 返回是否取到令牌和剩余令牌数
+func (s *Store) TakeToken(key string, rate float64, burst int) (bool, int, error) {
+	return s.takeToken(key, rate, burst, time.Now().UnixMilli())
+}
+
+// takeToken 按给定的毫秒时间戳从令牌桶中取一个令牌
+func (s *Store) takeToken(key string, rate float64, burst int, nowMillis int64) (bool, int, error) {
+	if rate <= 0 || burst <= 0 {
+		return false, 0, errors.New("rate and burst must be positive")
+	}
+	resp, err := s.Eval(takeTokenScript, key, rate, burst, nowMillis)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "eval script err")
+	}
+	result, ok := resp.([]interface{})
+	if !ok || len(result) != 2 {
+		return false, 0, errors.New("unexpected script result")
+	}
+	allowed, _ := result[0].(int64)
+	remaining, _ := result[1].(int64)
+	return allowed == 1, int(remaining), nil
+}
+
+// PushCapped 追加到列表尾部并只保留最后size条，用于固定长度的最近记录，seconds为key的过期时间（秒）
+func (s *Store) PushCapped(key, value string, size int, seconds ...int) error {
+	if size <= 0 {
+		return errors.New("size must be positive")
+	}
+	expire := 0
+	if len(seconds) > 0 {
+		expire = seconds[0]
+	}
+	if _, err := s.Eval(pushCappedScript, key, value, size, expire); err != nil {
+		return errors.Wrap(err, "eval script err")
+	}
+	return nil
+}
+
+// tagPrefix 标签集合key前缀，集合中保存打了该标签的key
+const tagPrefix = "kvtag:"
+
+// TagKey 给key打上标签，seconds为标签集合的过期时间（秒），应不小于key的过期时间
+func (s *Store) TagKey(key string, seconds int, tags ...string) error {
+	for _, tag := range tags {
+		if _, err := s.Sadd(tagPrefix+tag, key); err != nil {
+			return errors.Wrapf(err, "sadd key to tag %s err", tag)
+		}
+		if err := s.Expire(tagPrefix+tag, seconds); err != nil {
+			return errors.Wrapf(err, "expire tag %s err", tag)
+		}
+	}
+	return nil
+}
+
+// PurgeTags 删除打了给定标签的所有key和标签集合，返回删除的key数量（包含标签集合）
+func (s *Store) PurgeTags(tags ...string) (int, error) {
+	var purged int
+	for _, tag := range tags {
+		keys, err := s.Smembers(tagPrefix + tag)
+		if err != nil {
+			return purged, errors.Wrapf(err, "smembers of tag %s err", tag)
+		}
+		keys = append(keys, tagPrefix+tag)
+		n, err := s.Del(keys...)
+		if err != nil {
+			return purged, errors.Wrapf(err, "del keys of tag %s err", tag)
+		}
+		purged += n
+	}
+	return purged, nil
+}
+
+// Ping 检查redis连接是否可用
+func (s *Store) Ping(ctx context.Context) error {
+	if !s.Redis.PingCtx(ctx) {
+		return errors.New("redis ping failed")
+	}
+	return nil
+}
+
 // isValid 判断对象是否合法
 func isValid(obj interface{}) bool {
 	if obj == nil {
@@ -0,0 +1,92 @@
+package xkv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/kv"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return NewStore(kv.KvConf{cache.NodeConf{
+		RedisConf: redis.RedisConf{Host: mr.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}}), mr
+}
+
+func TestTakeToken(t *testing.T) {
+	type take struct {
+		at        int64 // 相对首次取令牌的毫秒数
+		allowed   bool
+		remaining int
+	}
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		takes []take
+	}{
+		{
+			name: "burst then reject", rate: 1, burst: 3,
+			takes: []take{{0, true, 2}, {0, true, 1}, {0, true, 0}, {0, false, 0}},
+		},
+		{
+			name: "refill by elapsed time", rate: 2, burst: 2,
+			takes: []take{{0, true, 1}, {0, true, 0}, {0, false, 0}, {500, true, 0}, {500, false, 0}, {1500, true, 1}},
+		},
+		{
+			name: "refill capped at burst", rate: 10, burst: 2,
+			takes: []take{{0, true, 1}, {60000, true, 1}, {60000, true, 0}},
+		},
+		{
+			name: "clock going backwards does not refill", rate: 1, burst: 1,
+			takes: []take{{1000, true, 0}, {0, false, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newTestStore(t)
+			start := time.Now().UnixMilli()
+			for i, tk := range tt.takes {
+				allowed, remaining, err := store.takeToken("bucket", tt.rate, tt.burst, start+tk.at)
+				assert.NoError(t, err)
+				assert.Equal(t, tk.allowed, allowed, "take %d allowed", i)
+				assert.Equal(t, tk.remaining, remaining, "take %d remaining", i)
+			}
+		})
+	}
+}
+
+func TestTakeTokenExpire(t *testing.T) {
+	store, mr := newTestStore(t)
+	_, _, err := store.takeToken("bucket", 2, 4, time.Now().UnixMilli())
+	assert.NoError(t, err)
+	// 桶从空到满需要 burst/rate 秒，再多保留1秒
+	assert.Equal(t, 3*time.Second, mr.TTL("bucket"))
+
+	_, _, err = store.takeToken("bucket", 0, 4, time.Now().UnixMilli())
+	assert.Error(t, err)
+}
+
+func TestPushCapped(t *testing.T) {
+	store, mr := newTestStore(t)
+	for _, value := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, store.PushCapped("list", value, 3, 60))
+	}
+	// 只保留最后3条，每次追加都重置过期时间
+	values, err := store.Lrange("list", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, values)
+	assert.Equal(t, 60*time.Second, mr.TTL("list"))
+
+	// 未指定过期时间时不过期
+	assert.NoError(t, store.PushCapped("forever", "a", 3))
+	assert.Equal(t, time.Duration(0), mr.TTL("forever"))
+
+	assert.Error(t, store.PushCapped("list", "e", 0))
+}
@@ -0,0 +1,7 @@
+package v1
+
+// ActivityPage 用户操作记录分页结果，NextCursor为空表示没有更多数据
+type ActivityPage struct {
+	Items      []*UserActivity `json:"items"`
+	NextCursor string          `json:"next_cursor"`
+}
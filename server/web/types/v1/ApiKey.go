@@ -0,0 +1,58 @@
+package v1
+
+/**
+create table aave.api_key
+(
+    id          bigint auto_increment primary key not null comment '主键ID,自增',
+    name        varchar(64)                       not null comment 'key名称，说明用途',
+    owner       varchar(128) default ''           not null comment '使用方，联系人或地址',
+    key_prefix  varchar(16)                       not null comment 'key的前几位，用于展示和识别',
+    key_hash    char(64)                          not null comment 'key的sha256哈希，明文只在创建时返回一次',
+    rate        double       default 0            not null comment '每秒补充的令牌数，0表示使用路由配额',
+    burst       int          default 0            not null comment '令牌桶容量，0表示使用路由配额',
+    status      tinyint      default 1            not null comment '状态，1可用，0已吊销',
+    create_time bigint                            not null comment '创建时间',
+    update_time bigint                            not null comment '更新时间',
+    creator     varchar(64)                       not null comment '创建人',
+    updater     varchar(64)                       not null comment '更新人',
+    unique key uk_key_hash (key_hash)
+);
+*/
+
+const (
+	ApiKeyStatusRevoked = 0
+	ApiKeyStatusActive  = 1
+)
+
+// ApiKey 公开接口的API key，请求通过 X-API-Key 请求头携带，按key单独限流和统计用量
+type ApiKey struct {
+	ID         int64   `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name       string  `gorm:"column:name;not null" json:"name"`
+	Owner      string  `gorm:"column:owner;not null;default:''" json:"owner"`
+	KeyPrefix  string  `gorm:"column:key_prefix;not null" json:"key_prefix"`
+	KeyHash    string  `gorm:"column:key_hash;not null" json:"-"`
+	Rate       float64 `gorm:"column:rate;not null;default:0" json:"rate"`
+	Burst      int     `gorm:"column:burst;not null;default:0" json:"burst"`
+	Status     int     `gorm:"column:status;not null;default:1" json:"status"`
+	CreateTime int64   `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime int64   `gorm:"column:update_time;not null" json:"update_time"`
+	Creator    string  `gorm:"column:creator;not null" json:"creator"`
+	Updater    string  `gorm:"column:updater;not null" json:"updater"`
+}
+
+func GetApiKeyTableName() string {
+	return "api_key"
+}
+
+// ApiKeyCreated 新建的API key，Key为明文，只在创建时返回
+type ApiKeyCreated struct {
+	*ApiKey
+	Key string `json:"key"`
+}
+
+// ApiKeyUsage API key每天的调用次数，Routes为各路由的调用次数
+type ApiKeyUsage struct {
+	Date   string           `json:"date"`
+	Total  int64            `json:"total"`
+	Routes map[string]int64 `json:"routes"`
+}
@@ -0,0 +1,63 @@
+package v1
+
+// ChainLendInfo 池子合约 getTotalLendInfo 在指定区块的返回值
+type ChainLendInfo struct {
+	Block           int64  `json:"block"` // 读取所在的区块
+	TotalLend       string `json:"total_lend"`
+	TotalBorrow     string `json:"total_borrow"`
+	UtilizationRate string `json:"utilization_rate"` // 6位精度
+	InterestApy     string `json:"interest_apy"`     // 6位精度
+}
+
+// ChainTokenBorrowInfo 池子合约 getTokenBorrowInfo 在指定区块的返回值
+type ChainTokenBorrowInfo struct {
+	Block           int64  `json:"block"`
+	CollateralToken string `json:"collateral_token"`
+	Borrowed        string `json:"borrowed"`
+	Borrowable      string `json:"borrowable"`
+	UtilizationRate string `json:"utilization_rate"` // 6位精度
+}
+
+// ChainUserLend 池子合约 calculateUserLendTotal 在指定区块的返回值
+type ChainUserLend struct {
+	Block             int64  `json:"block"`
+	User              string `json:"user"`
+	TotalWithInterest string `json:"total_with_interest"`
+}
+
+// ChainUserBorrow 池子合约 calculateUserBorrowTotal 在指定区块的返回值
+type ChainUserBorrow struct {
+	Block             int64  `json:"block"`
+	User              string `json:"user"`
+	CollateralToken   string `json:"collateral_token"`
+	TotalWithInterest string `json:"total_with_interest"`
+}
+
+// ChainUserHealth 池子合约 getUserHealthInfo 在指定区块的返回值
+type ChainUserHealth struct {
+	Block                int64  `json:"block"`
+	User                 string `json:"user"`
+	CollateralToken      string `json:"collateral_token"`
+	HealthFactor         string `json:"health_factor"` // 6位精度
+	TotalCollateralValue string `json:"total_collateral_value"`
+	TotalDebtValue       string `json:"total_debt_value"`
+	IsLiquidatable       bool   `json:"is_liquidatable"`
+}
+
+// ChainUserPosition 用户在同一区块的存款本息和各抵押代币下的借款
+type ChainUserPosition struct {
+	Block                 int64                  `json:"block"`
+	User                  string                 `json:"user"`
+	LendTotalWithInterest string                 `json:"lend_total_with_interest"`
+	Borrows               []*ChainBorrowPosition `json:"borrows"`
+}
+
+// ChainBorrowPosition 用户在某个抵押代币下的借款本息和健康因子
+type ChainBorrowPosition struct {
+	CollateralToken      string `json:"collateral_token"`
+	TotalWithInterest    string `json:"total_with_interest"`
+	HealthFactor         string `json:"health_factor"` // 6位精度
+	TotalCollateralValue string `json:"total_collateral_value"`
+	TotalDebtValue       string `json:"total_debt_value"`
+	IsLiquidatable       bool   `json:"is_liquidatable"`
+}
@@ -0,0 +1,26 @@
+package v1
+
+// UserEarnings 用户存款收益报表，金额均为USDC链上精度的整数
+type UserEarnings struct {
+	Address          string           `json:"address"`
+	Principal        string           `json:"principal"`         // 当前剩余本金
+	AccruedInterest  string           `json:"accrued_interest"`  // 已累积未取出的利息
+	RealizedInterest string           `json:"realized_interest"` // 取款时已实现的利息
+	CurrentValue     string           `json:"current_value"`     // 本金+累积利息
+	AsOfBlock        int64            `json:"as_of_block"`       // 计算当前价值所用指数快照的区块
+	AsOfTime         int64            `json:"as_of_time"`        // 计算当前价值所用指数快照的更新时间
+	Entries          []*EarningsEntry `json:"entries"`
+}
+
+// EarningsEntry 每次存取款之后用户的收益状态
+type EarningsEntry struct {
+	BlockNumber      int64  `json:"block_number"`
+	BlockTime        int64  `json:"block_time"`
+	TxHash           string `json:"tx_hash"`
+	Action           string `json:"action"`
+	Amount           string `json:"amount"`
+	Interest         string `json:"interest"`
+	Principal        string `json:"principal"`
+	RealizedInterest string `json:"realized_interest"`
+	AccruedInterest  string `json:"accrued_interest"`
+}
@@ -0,0 +1,36 @@
+package v1
+
+/**
+create table aave.interest_index
+(
+    id                    bigint auto_increment primary key not null comment '主键ID,自增',
+    block_number          bigint                            not null comment '区块号',
+    block_time            bigint                            not null comment '区块时间戳',
+    liquidity_index       varchar(100)                      not null comment '存款指数，1e27精度',
+    borrow_index          varchar(100)                      not null comment '借款指数，1e27精度',
+    last_update_timestamp bigint                            not null comment '合约指数最后更新时间',
+    create_time           bigint                            not null comment '创建时间',
+    update_time           bigint                            not null comment '更新时间',
+    creator               char(42)                          not null comment '创建人',
+    updater               char(42)                          not null comment '更新人',
+    unique key uk_block_number (block_number)
+);
+*/
+
+// InterestIndex 根据上面的表结构，我们可以定义一个InterestIndex结构体
+type InterestIndex struct {
+	ID                  int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	BlockNumber         int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime           int64  `gorm:"column:block_time;not null" json:"block_time"`
+	LiquidityIndex      string `gorm:"column:liquidity_index;not null" json:"liquidity_index"`
+	BorrowIndex         string `gorm:"column:borrow_index;not null" json:"borrow_index"`
+	LastUpdateTimestamp int64  `gorm:"column:last_update_timestamp;not null" json:"last_update_timestamp"`
+	CreateTime          int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime          int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator             string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater             string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetInterestIndexTableName() string {
+	return "interest_index"
+}
@@ -11,10 +11,15 @@ create table lend
     total_deposits   varchar(100)  not null comment '总存入金额，usdc换算成的dollar，保留两小数',
     utilization_rate int default 0 not null comment '利用率，保留两位小数，百分比，例子：10.02%',
     interest_rate    int default 0 not null comment '动态年化利率，保留两位小数，百分比，例子：10.02%',
-    create_time      int           not null comment '创建时间',
+    block_number     bigint default 0 not null comment '事件所在区块号',
+    tx_hash          char(66) default '' not null comment '事件所在交易哈希',
+    log_index        int default 0 not null comment '日志序号',
+    create_time      int           not null comment '创建时间，事件所在区块的时间',
     update_time      int           not null comment '更新时间',
     creator          char(42)      not null comment '创建人',
-    updater          char(42)      not null comment '更新人'
+    updater          char(42)      not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_block_number (block_number)
 );
 */
 
@@ -26,6 +31,9 @@ type Lend struct {
 	TotalDeposits   string `gorm:"column:total_deposits;not null;default:0" json:"total_deposits"`
 	UtilizationRate int    `gorm:"column:utilization_rate;not null;default:0" json:"utilization_rate"`
 	InterestRate    int    `gorm:"column:interest_rate;not null" json:"interest_rate"`
+	BlockNumber     int64  `gorm:"column:block_number;not null;default:0" json:"block_number"`
+	TxHash          string `gorm:"column:tx_hash;not null;default:''" json:"tx_hash"`
+	LogIndex        int    `gorm:"column:log_index;not null;default:0" json:"log_index"`
 	CreateTime      int    `gorm:"column:create_time;not null" json:"create_time"`
 	UpdateTime      int    `gorm:"column:update_time;not null" json:"update_time"`
 	Creator         string `gorm:"column:creator;not null;default:''" json:"creator"`
@@ -0,0 +1,61 @@
+package v1
+
+/**
+create table aave.liquidation
+(
+    id                bigint auto_increment primary key not null comment '主键ID,自增',
+    borrower          char(42)                          not null comment '被清算的借款人地址，小写',
+    liquidator        char(42)                          not null comment '清算人地址，小写',
+    collateral_token  char(42)                          not null comment '抵押代币地址，小写',
+    liquidated_amount varchar(100)                      not null comment '清算人代还的USDC数量，链上精度',
+    collateral_seized varchar(100)                      not null comment '清算人获得的抵押物数量（含奖励），链上精度',
+    bonus_amount      varchar(100) default '0'          not null comment '其中清算奖励的抵押物数量，链上精度',
+    penalty_rate      varchar(100) default '0'          not null comment '清算时的liquidationPenaltyFeeRate4Cleaner，6位精度',
+    collateral_price  varchar(100) default '0'          not null comment '清算时抵押物的预言机价格，美元6位精度',
+    usdc_price        varchar(100) default '0'          not null comment '清算时USDC的预言机价格，美元6位精度',
+    debt_value_usd    varchar(100) default '0'          not null comment '代还债务的美元价值，6位精度',
+    seized_value_usd  varchar(100) default '0'          not null comment '获得抵押物的美元价值，6位精度',
+    bonus_value_usd   varchar(100) default '0'          not null comment '清算奖励的美元价值，6位精度',
+    tx_hash           char(66)                          not null comment '交易哈希',
+    log_index         int                               not null comment '日志序号',
+    block_number      bigint                            not null comment '区块号',
+    block_time        bigint                            not null comment '区块时间戳',
+    create_time       bigint                            not null comment '创建时间',
+    update_time       bigint                            not null comment '更新时间',
+    creator           char(42)                          not null comment '创建人',
+    updater           char(42)                          not null comment '更新人',
+    unique key uk_tx_log (tx_hash, log_index),
+    key idx_liquidator (liquidator),
+    key idx_collateral_token (collateral_token),
+    key idx_borrower (borrower)
+);
+*/
+
+// Liquidation 根据上面的表结构，我们可以定义一个Liquidation结构体
+type Liquidation struct {
+	ID               int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Borrower         string `gorm:"column:borrower;not null" json:"borrower"`
+	Liquidator       string `gorm:"column:liquidator;not null" json:"liquidator"`
+	CollateralToken  string `gorm:"column:collateral_token;not null" json:"collateral_token"`
+	LiquidatedAmount string `gorm:"column:liquidated_amount;not null" json:"liquidated_amount"`
+	CollateralSeized string `gorm:"column:collateral_seized;not null" json:"collateral_seized"`
+	BonusAmount      string `gorm:"column:bonus_amount;not null;default:'0'" json:"bonus_amount"`
+	PenaltyRate      string `gorm:"column:penalty_rate;not null;default:'0'" json:"penalty_rate"`
+	CollateralPrice  string `gorm:"column:collateral_price;not null;default:'0'" json:"collateral_price"`
+	UsdcPrice        string `gorm:"column:usdc_price;not null;default:'0'" json:"usdc_price"`
+	DebtValueUsd     string `gorm:"column:debt_value_usd;not null;default:'0'" json:"debt_value_usd"`
+	SeizedValueUsd   string `gorm:"column:seized_value_usd;not null;default:'0'" json:"seized_value_usd"`
+	BonusValueUsd    string `gorm:"column:bonus_value_usd;not null;default:'0'" json:"bonus_value_usd"`
+	TxHash           string `gorm:"column:tx_hash;not null" json:"tx_hash"`
+	LogIndex         int    `gorm:"column:log_index;not null" json:"log_index"`
+	BlockNumber      int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime        int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime       int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime       int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator          string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater          string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetLiquidationTableName() string {
+	return "liquidation"
+}
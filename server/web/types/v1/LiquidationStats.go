@@ -0,0 +1,21 @@
+package v1
+
+// LiquidationPage 清算记录分页结果，NextCursor为空表示没有更多数据
+type LiquidationPage struct {
+	Items      []*Liquidation `json:"items"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// LiquidationStats 按清算人或抵押代币聚合的清算统计，代币数量为链上精度，美元价值为6位精度
+type LiquidationStats struct {
+	Key              string `gorm:"column:key" json:"key"` // 清算人地址或抵押代币地址
+	Count            int64  `gorm:"column:count" json:"count"`
+	LiquidatedAmount string `gorm:"column:liquidated_amount" json:"liquidated_amount"`
+	CollateralSeized string `gorm:"column:collateral_seized" json:"collateral_seized"`
+	BonusAmount      string `gorm:"column:bonus_amount" json:"bonus_amount"`
+	DebtValueUsd     string `gorm:"column:debt_value_usd" json:"debt_value_usd"`
+	SeizedValueUsd   string `gorm:"column:seized_value_usd" json:"seized_value_usd"`
+	BonusValueUsd    string `gorm:"column:bonus_value_usd" json:"bonus_value_usd"`
+	BonusRate        string `gorm:"-" json:"bonus_rate"` // 清算奖励价值/代还债务价值，百分比
+	LastBlockTime    int64  `gorm:"column:last_block_time" json:"last_block_time"`
+}
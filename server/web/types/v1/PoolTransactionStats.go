@@ -0,0 +1,20 @@
+package v1
+
+// PoolTransactionStats 按方法聚合的pool合约交易和gas统计，gas价格和手续费单位为wei
+type PoolTransactionStats struct {
+	Method        string `gorm:"column:method" json:"method"` // pool合约方法名，经由其他合约调用或未知方法时为空
+	Count         int64  `gorm:"column:count" json:"count"`
+	FailedCount   int64  `gorm:"column:failed_count" json:"failed_count"`
+	FailureRate   string `gorm:"-" json:"failure_rate"` // 失败交易占比，百分比
+	AvgGasUsed    int64  `gorm:"column:avg_gas_used" json:"avg_gas_used"`
+	AvgGasPrice   string `gorm:"column:avg_gas_price" json:"avg_gas_price"`
+	AvgFee        string `gorm:"column:avg_fee" json:"avg_fee"`
+	TotalFee      string `gorm:"column:total_fee" json:"total_fee"`
+	LastBlockTime int64  `gorm:"column:last_block_time" json:"last_block_time"`
+}
+
+// PoolTransactionPage pool合约交易分页结果，NextCursor为空表示没有更多数据
+type PoolTransactionPage struct {
+	Items      []*PoolTransaction `json:"items"`
+	NextCursor string             `json:"next_cursor"`
+}
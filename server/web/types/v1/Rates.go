@@ -0,0 +1,11 @@
+package v1
+
+// RealizedRates 两个时间点之间根据指数增长计算出的实际年化收益率
+type RealizedRates struct {
+	From      int64  `json:"from"`       // 起始指数快照的更新时间
+	To        int64  `json:"to"`         // 结束指数快照的更新时间
+	FromBlock int64  `json:"from_block"` // 起始指数快照所在区块
+	ToBlock   int64  `json:"to_block"`   // 结束指数快照所在区块
+	SupplyApy string `json:"supply_apy"` // 存款实际年化，百分比，保留四位小数
+	BorrowApy string `json:"borrow_apy"` // 借款实际年化，百分比，保留四位小数
+}
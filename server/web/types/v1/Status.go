@@ -0,0 +1,50 @@
+package v1
+
+/**
+调度服务维护的同步进度表，web只读
+create table aave.indexed_status
+(
+    id                 bigint auto_increment primary key not null comment '主键ID,自增',
+    chain_id           bigint  default 1                 not null comment '链id (1:以太坊, 56: BSC)',
+    last_indexed_block bigint  default 0                 null comment '区块号',
+    last_indexed_time  bigint                            null comment '最后同步时间戳',
+    index_type         tinyint default 0                 not null comment '0:activity',
+    create_time        bigint                            null comment '创建时间戳',
+    update_time        bigint                            null comment '更新时间戳'
+);
+*/
+
+// IndexedStatus 事件同步进度
+type IndexedStatus struct {
+	ID               int64 `gorm:"column:id" json:"id"`
+	ChainID          int64 `gorm:"column:chain_id" json:"chain_id"`
+	LastIndexedBlock int64 `gorm:"column:last_indexed_block" json:"last_indexed_block"`
+	LastIndexedTime  int64 `gorm:"column:last_indexed_time" json:"last_indexed_time"`
+}
+
+func GetIndexedStatusTableName() string {
+	return "indexed_status"
+}
+
+// 健康检查结果
+const (
+	HealthStatusOk   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// Health 健康检查结果，Checks 为各依赖项的检查结果，失败时为错误信息
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Status 事件同步状态，前端据此提示数据延迟
+type Status struct {
+	ChainID          int64 `json:"chain_id"`           // 链id
+	LastIndexedBlock int64 `json:"last_indexed_block"` // 已同步到的区块
+	LastIndexedTime  int64 `json:"last_indexed_time"`  // 调度服务最后一次同步进展的时间
+	ChainHead        int64 `json:"chain_head"`         // 节点最新区块，未配置rpc或读取失败时为0
+	Lag              int64 `json:"lag"`                // 落后的区块数，未知链上最新区块时为0
+	LastEventTime    int64 `json:"last_event_time"`    // 最近一次池子状态变化事件的区块时间
+	Delayed          bool  `json:"delayed"`            // 落后区块数或最后一次同步进展距今超过配置的阈值
+}
@@ -0,0 +1,70 @@
+package v1
+
+/**
+create table aave.user_activity
+(
+    id                bigint auto_increment primary key not null comment '主键ID,自增',
+    user_address      char(42)                          not null comment '用户地址，小写',
+    action            varchar(32)                       not null comment '操作类型',
+    token_address     char(42)     default ''           not null comment '抵押代币地址，存取款为空',
+    amount            varchar(100) default '0'          not null comment '操作金额，链上精度',
+    interest          varchar(100) default '0'          not null comment '取款时实现的利息，链上精度',
+    liquidity_index   varchar(100) default ''           not null comment '存款时的存款指数，1e27精度',
+    collateral_amount varchar(100) default '0'          not null comment '清算时被扣押或还款时返还的抵押物数量，链上精度',
+    counterparty      char(42)     default ''           not null comment '清算的对手方地址',
+    tx_hash           char(66)                          not null comment '交易哈希',
+    log_index         int                               not null comment '日志序号',
+    block_number      bigint                            not null comment '区块号',
+    block_time        bigint                            not null comment '区块时间戳',
+    create_time       bigint                            not null comment '创建时间',
+    update_time       bigint                            not null comment '更新时间',
+    creator           char(42)                          not null comment '创建人',
+    updater           char(42)                          not null comment '更新人',
+    unique key uk_tx_log_user (tx_hash, log_index, user_address),
+    key idx_user_block (user_address, block_number)
+);
+*/
+
+const (
+	// ActionLendDeposit 存款
+	ActionLendDeposit = "lend_deposit"
+	// ActionLendWithdraw 取款
+	ActionLendWithdraw = "lend_withdraw"
+	// ActionCollateralDeposit 存入抵押物
+	ActionCollateralDeposit = "collateral_deposit"
+	// ActionCollateralWithdraw 取回抵押物
+	ActionCollateralWithdraw = "collateral_withdraw"
+	// ActionBorrow 借款
+	ActionBorrow = "borrow"
+	// ActionRepay 还款
+	ActionRepay = "repay"
+	// ActionLiquidated 被清算（借款人视角）
+	ActionLiquidated = "liquidated"
+	// ActionLiquidate 执行清算（清算人视角）
+	ActionLiquidate = "liquidate"
+)
+
+// UserActivity 根据上面的表结构，我们可以定义一个UserActivity结构体
+type UserActivity struct {
+	ID               int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserAddress      string `gorm:"column:user_address;not null" json:"user_address"`
+	Action           string `gorm:"column:action;not null" json:"action"`
+	TokenAddress     string `gorm:"column:token_address;not null;default:''" json:"token_address"`
+	Amount           string `gorm:"column:amount;not null;default:'0'" json:"amount"`
+	Interest         string `gorm:"column:interest;not null;default:'0'" json:"interest"`
+	LiquidityIndex   string `gorm:"column:liquidity_index;not null;default:''" json:"liquidity_index"`
+	CollateralAmount string `gorm:"column:collateral_amount;not null;default:'0'" json:"collateral_amount"`
+	Counterparty     string `gorm:"column:counterparty;not null;default:''" json:"counterparty"`
+	TxHash           string `gorm:"column:tx_hash;not null" json:"tx_hash"`
+	LogIndex         int    `gorm:"column:log_index;not null" json:"log_index"`
+	BlockNumber      int64  `gorm:"column:block_number;not null" json:"block_number"`
+	BlockTime        int64  `gorm:"column:block_time;not null" json:"block_time"`
+	CreateTime       int    `gorm:"column:create_time;not null" json:"create_time"`
+	UpdateTime       int    `gorm:"column:update_time;not null" json:"update_time"`
+	Creator          string `gorm:"column:creator;not null;default:''" json:"creator"`
+	Updater          string `gorm:"column:updater;not null;default:''" json:"updater"`
+}
+
+func GetUserActivityTableName() string {
+	return "user_activity"
+}
@@ -3,8 +3,11 @@ package xhttp
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -22,6 +25,10 @@ type RPCClient interface {
 	CallRaw(request *RPCRequest) (*RPCResponse, error)
 	// CallFor 进行 JSON-RPC 调用并将响应结果反序列化到所给类型对象中
 	CallFor(out interface{}, method string, params ...interface{}) error
+	// CallBatch 在一个 HTTP 请求中进行批量 JSON-RPC 调用，请求的 ID 不能重复，响应与请求按顺序一一对应
+	CallBatch(requests RPCRequests) (RPCResponses, error)
+	// CallBatchFor 进行批量 JSON-RPC 调用并将各响应结果反序列化到 outs 中对应的对象
+	CallBatchFor(outs []interface{}, requests RPCRequests) error
 }
 
 // RPCOption JSON-RPC 客户端可选配置
@@ -44,6 +51,23 @@ func WithCustomHeaders(m map[string]string) RPCOption {
 	}
 }
 
+// WithRetry 对网络错误、HTTP 429/5xx 以及限流类的 JSON-RPC 错误进行重试，
+// 最多重试 maxRetries 次，退避时间从 baseDelay 开始指数增长，不超过 maxDelay，并加入随机抖动
+func WithRetry(maxRetries int, baseDelay, maxDelay time.Duration) RPCOption {
+	return func(c *rpcClient) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+		c.retryMaxDelay = maxDelay
+	}
+}
+
+// WithRateLimit 限制每秒发出的 HTTP 请求数，burst 为允许的突发请求数，批量调用按一次请求计算
+func WithRateLimit(rps float64, burst int) RPCOption {
+	return func(c *rpcClient) {
+		c.limiter = newRateLimiter(rps, burst)
+	}
+}
+
 // NewRPCClient 新建通用 JSON-RPC 客户端
 func NewRPCClient(endpoint string, opts ...RPCOption) RPCClient {
 	c := &rpcClient{endpoint: endpoint}
@@ -61,9 +85,13 @@ func NewRPCClient(endpoint string, opts ...RPCOption) RPCClient {
 
 // rpcClient 默认 JSON-RPC 客户端
 type rpcClient struct {
-	endpoint      string
-	httpClient    *http.Client
-	customHeaders map[string]string
+	endpoint       string
+	httpClient     *http.Client
+	customHeaders  map[string]string
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	limiter        *rateLimiter
 }
 
 // newRequest 新建 HTTP 请求体
@@ -89,37 +117,84 @@ func (c *rpcClient) newRequest(req interface{}) (*http.Request, error) {
 	return request, nil
 }
 
-// doCall 执行 JSON-RPC 调用
+// doCall 执行 JSON-RPC 调用，配置了重试时，限流类的 JSON-RPC 错误与批量调用一样重试
 func (c *rpcClient) doCall(req *RPCRequest) (*RPCResponse, error) {
+	for attempt := 0; ; attempt++ {
+		var rpcResp *RPCResponse
+		err := c.post(req, req.Method, func(d *json.Decoder) error {
+			return d.Decode(&rpcResp)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if rpcResp == nil {
+			return nil, errors.Errorf("call %s method on %s err: rpc response missing", req.Method, c.endpoint)
+		}
+		if rpcErr := rpcResp.GetError(); rpcErr == nil || !isRetryableRPCError(rpcErr) || attempt >= c.maxRetries {
+			return rpcResp, nil
+		}
+		time.Sleep(c.backoff(attempt))
+	}
+}
+
+// post 发送请求并通过 decode 解析响应体，按配置进行限流和重试
+func (c *rpcClient) post(req interface{}, method string, decode func(d *json.Decoder) error) error {
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			c.limiter.Wait()
+		}
+		retryable, err := c.postOnce(req, method, decode)
+		if err == nil || !retryable || attempt >= c.maxRetries {
+			return err
+		}
+		time.Sleep(c.backoff(attempt))
+	}
+}
+
+// postOnce 发送一次请求，返回的 bool 表示错误是否可以重试
+func (c *rpcClient) postOnce(req interface{}, method string, decode func(d *json.Decoder) error) (bool, error) {
 	httpReq, err := c.newRequest(req)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "call %s method on %s err",
-			req.Method, c.endpoint)
+		return false, errors.WithMessagef(err, "call %s method on %s err",
+			method, c.endpoint)
 	}
 
 	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "call %s method on %s err",
-			req.Method, httpReq.URL.String())
+		return true, errors.WithMessagef(err, "call %s method on %s err",
+			method, httpReq.URL.String())
 	}
 	defer httpResp.Body.Close()
 
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		return true, errors.Errorf("call %s method on %s status code: %d, rate limited",
+			method, httpReq.URL.String(), httpResp.StatusCode)
+	}
+
 	d := json.NewDecoder(httpResp.Body)
 	d.DisallowUnknownFields()
 	d.UseNumber()
 
-	var rpcResp *RPCResponse
-	err = d.Decode(&rpcResp)
+	err = decode(d)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "call %s method on %s status code: %d, decode body err",
-			req.Method, httpReq.URL.String(), httpResp.StatusCode)
-	}
-	if rpcResp == nil {
-		return nil, errors.WithMessagef(err, "call %s method on %s status code: %d, rpc response missing err.",
-			req.Method, httpReq.URL.String(), httpResp.StatusCode)
+		return httpResp.StatusCode >= http.StatusInternalServerError, errors.WithMessagef(err, "call %s method on %s status code: %d, decode body err",
+			method, httpReq.URL.String(), httpResp.StatusCode)
 	}
 
-	return rpcResp, nil
+	return false, nil
+}
+
+// backoff 第 attempt 次重试前的等待时间，在指数退避时间的 [1/2, 1] 区间内随机取值
+func (c *rpcClient) backoff(attempt int) time.Duration {
+	delay := c.retryBaseDelay << uint(attempt)
+	if delay <= 0 || (c.retryMaxDelay > 0 && delay > c.retryMaxDelay) {
+		delay = c.retryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
 }
 
 // Call 进行 JSON-RPC 调用
@@ -215,6 +290,34 @@ type RPCResponse struct {
 	Error   interface{} `json:"error,omitempty"`
 }
 
+// RPCError JSON-RPC 错误对象
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// GetError 将响应中的错误解析为 RPCError，响应没有错误时返回 nil
+func (resp *RPCResponse) GetError() *RPCError {
+	if resp.Error == nil {
+		return nil
+	}
+	if rpcErr, ok := resp.Error.(*RPCError); ok {
+		return rpcErr
+	}
+	rpcErr := &RPCError{}
+	b, err := json.Marshal(resp.Error)
+	if err != nil || json.Unmarshal(b, rpcErr) != nil || (rpcErr.Code == 0 && rpcErr.Message == "") {
+		return &RPCError{Message: fmt.Sprintf("%v", resp.Error)}
+	}
+	return rpcErr
+}
+
 // GetInt64 获取响应结果的 int64 类型值
 func (resp *RPCResponse) GetInt64() (int64, error) {
 	if resp.Error != nil {
@@ -0,0 +1,186 @@
+package xhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// rpcErrLimitExceeded 节点服务商常用的限流错误码
+	rpcErrLimitExceeded = -32005
+	// rpcErrMissingResponse 批量响应中缺少某个请求的响应时使用的错误码
+	rpcErrMissingResponse = -32099
+)
+
+// RPCRequests 批量 JSON-RPC 请求
+type RPCRequests []*RPCRequest
+
+// RPCResponses 批量 JSON-RPC 响应
+type RPCResponses []*RPCResponse
+
+// HasError 是否存在失败的响应
+func (resps RPCResponses) HasError() bool {
+	for _, resp := range resps {
+		if resp.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchError 批量调用中部分请求失败，Errors 的 key 为请求在批量中的下标
+type BatchError struct {
+	Errors map[int]error
+}
+
+// Error 实现 error 接口
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("[%d] %v", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("%d of batch requests failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// CallBatch 在一个 HTTP 请求中进行批量 JSON-RPC 调用
+// 请求按调用方设置的 ID 发送，响应按 ID 还原为请求的顺序，同一批中的 ID 不能重复；
+// 单个请求的错误保存在对应响应的 Error 中，缺失的响应也会以错误形式返回，
+// 配置了重试时，限流类错误的请求会单独重试
+func (c *rpcClient) CallBatch(requests RPCRequests) (RPCResponses, error) {
+	if len(requests) == 0 {
+		return nil, errors.New("empty batch requests")
+	}
+
+	indexByID := make(map[int]int, len(requests))
+	for i, req := range requests {
+		if _, ok := indexByID[req.ID]; ok {
+			return nil, errors.Errorf("duplicate request id %d in batch", req.ID)
+		}
+		indexByID[req.ID] = i
+	}
+
+	responses := make(RPCResponses, len(requests))
+	pending := make([]int, len(requests))
+	for i := range pending {
+		pending[i] = i
+	}
+	for attempt := 0; ; attempt++ {
+		batch := make(RPCRequests, len(pending))
+		for i, index := range pending {
+			req := *requests[index]
+			if req.JSONRPC == "" {
+				req.JSONRPC = jsonrpcVersion
+			}
+			batch[i] = &req
+		}
+
+		resps, err := c.doBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+
+		var retry []int
+		for _, index := range pending {
+			id := requests[index].ID
+			resp, ok := resps[id]
+			if !ok {
+				resp = &RPCResponse{
+					JSONRPC: jsonrpcVersion,
+					ID:      id,
+					Error:   &RPCError{Code: rpcErrMissingResponse, Message: "missing response in batch"},
+				}
+			}
+			responses[indexByID[id]] = resp
+			if rpcErr := resp.GetError(); rpcErr != nil && isRetryableRPCError(rpcErr) {
+				retry = append(retry, index)
+			}
+		}
+		if len(retry) == 0 || attempt >= c.maxRetries {
+			return responses, nil
+		}
+		pending = retry
+		time.Sleep(c.backoff(attempt))
+	}
+}
+
+// doBatch 发送一次批量请求，返回以请求 ID 为 key 的响应
+func (c *rpcClient) doBatch(batch RPCRequests) (map[int]*RPCResponse, error) {
+	var raw json.RawMessage
+	err := c.post(batch, fmt.Sprintf("batch(%d)", len(batch)), func(d *json.Decoder) error {
+		return d.Decode(&raw)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.DisallowUnknownFields()
+	d.UseNumber()
+
+	// 部分服务端在整个批量请求不合法时只返回一个错误对象
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var resp RPCResponse
+		if err := d.Decode(&resp); err != nil {
+			return nil, errors.WithMessage(err, "decode batch response err")
+		}
+		if rpcErr := resp.GetError(); rpcErr != nil {
+			return nil, errors.WithMessage(rpcErr, "batch request rejected")
+		}
+		return nil, errors.New("batch request returned a single response")
+	}
+
+	var resps RPCResponses
+	if err := d.Decode(&resps); err != nil {
+		return nil, errors.WithMessage(err, "decode batch response err")
+	}
+	byID := make(map[int]*RPCResponse, len(resps))
+	for _, resp := range resps {
+		if resp != nil {
+			byID[resp.ID] = resp
+		}
+	}
+	return byID, nil
+}
+
+// CallBatchFor 进行批量 JSON-RPC 调用并将响应结果依次反序列化到 outs 中
+// 部分请求失败时其余结果仍会写入 outs，并返回 *BatchError 说明失败的请求
+func (c *rpcClient) CallBatchFor(outs []interface{}, requests RPCRequests) error {
+	if len(outs) != len(requests) {
+		return errors.Errorf("outs length %d not equal to requests length %d", len(outs), len(requests))
+	}
+	responses, err := c.CallBatch(requests)
+	if err != nil {
+		return err
+	}
+
+	batchErr := &BatchError{Errors: make(map[int]error)}
+	for i, resp := range responses {
+		if rpcErr := resp.GetError(); rpcErr != nil {
+			batchErr.Errors[i] = rpcErr
+			continue
+		}
+		if err := resp.ReadToObject(outs[i]); err != nil {
+			batchErr.Errors[i] = err
+		}
+	}
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// isRetryableRPCError 限流类错误可以重试，其余错误重试也不会成功
+func isRetryableRPCError(rpcErr *RPCError) bool {
+	return rpcErr.Code == rpcErrLimitExceeded || rpcErr.Code == 429
+}
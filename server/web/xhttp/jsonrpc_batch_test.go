@@ -0,0 +1,209 @@
+package xhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBatchServer 启动一个测试用 JSON-RPC 服务，按请求顺序倒序返回批量响应，用于验证 ID 关联
+func newBatchServer(t *testing.T, handle func(req *RPCRequest) *RPCResponse) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		var resps []*RPCResponse
+		for i := len(reqs) - 1; i >= 0; i-- {
+			if resp := handle(reqs[i]); resp != nil {
+				resp.JSONRPC = jsonrpcVersion
+				resp.ID = reqs[i].ID
+				resps = append(resps, resp)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// withIDs 按顺序为批量请求设置不重复的 ID
+func withIDs(requests ...*RPCRequest) RPCRequests {
+	for i, req := range requests {
+		req.ID = i + 1
+	}
+	return requests
+}
+
+func TestRPCClient_CallBatch(t *testing.T) {
+	server := newBatchServer(t, func(req *RPCRequest) *RPCResponse {
+		switch req.Method {
+		case "echo":
+			return &RPCResponse{Result: req.Params}
+		case "fail":
+			return &RPCResponse{Error: &RPCError{Code: -32000, Message: "execution reverted"}}
+		default:
+			return nil
+		}
+	})
+	c := NewRPCClient(server.URL)
+	resps, err := c.CallBatch(withIDs(
+		NewRPCRequest("echo", "a"),
+		NewRPCRequest("fail"),
+		NewRPCRequest("echo", "b"),
+		NewRPCRequest("missing"),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, resps, 4)
+	assert.True(t, resps.HasError())
+
+	var a, b []string
+	assert.NoError(t, resps[0].ReadToObject(&a))
+	assert.NoError(t, resps[2].ReadToObject(&b))
+	assert.Equal(t, []string{"a"}, a)
+	assert.Equal(t, []string{"b"}, b)
+	assert.Equal(t, -32000, resps[1].GetError().Code)
+	assert.Equal(t, rpcErrMissingResponse, resps[3].GetError().Code)
+}
+
+func TestRPCClient_CallBatchFor(t *testing.T) {
+	server := newBatchServer(t, func(req *RPCRequest) *RPCResponse {
+		if req.Method == "fail" {
+			return &RPCResponse{Error: map[string]interface{}{"code": -32601, "message": "method not found"}}
+		}
+		return &RPCResponse{Result: req.Method}
+	})
+	c := NewRPCClient(server.URL)
+
+	var first, second string
+	err := c.CallBatchFor([]interface{}{&first, new(string), &second}, withIDs(
+		NewRPCRequest("first"),
+		NewRPCRequest("fail"),
+		NewRPCRequest("second"),
+	))
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Len(t, batchErr.Errors, 1)
+	assert.Equal(t, -32601, batchErr.Errors[1].(*RPCError).Code)
+	assert.Equal(t, "first", first)
+	assert.Equal(t, "second", second)
+
+	err = c.CallBatchFor([]interface{}{&first}, RPCRequests{})
+	assert.Error(t, err)
+}
+
+func TestRPCClient_CallBatchRetry(t *testing.T) {
+	var limited int32
+	server := newBatchServer(t, func(req *RPCRequest) *RPCResponse {
+		// 第一次请求 limited 方法时返回限流错误
+		if req.Method == "limited" && atomic.AddInt32(&limited, 1) == 1 {
+			return &RPCResponse{Error: &RPCError{Code: rpcErrLimitExceeded, Message: "limit exceeded"}}
+		}
+		return &RPCResponse{Result: req.Method}
+	})
+	c := NewRPCClient(server.URL, WithRetry(2, time.Millisecond, 10*time.Millisecond))
+
+	var ok, limitedResult string
+	err := c.CallBatchFor([]interface{}{&ok, &limitedResult}, withIDs(
+		NewRPCRequest("ok"),
+		NewRPCRequest("limited"),
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", ok)
+	assert.Equal(t, "limited", limitedResult)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&limited))
+}
+
+func TestRPCClient_CallBatchKeepsIDs(t *testing.T) {
+	var sent []int
+	server := newBatchServer(t, func(req *RPCRequest) *RPCResponse {
+		sent = append(sent, req.ID)
+		return &RPCResponse{Result: req.Method}
+	})
+	c := NewRPCClient(server.URL)
+
+	first, second := NewRPCRequest("first"), NewRPCRequest("second")
+	first.ID, second.ID = 7, 3
+	resps, err := c.CallBatch(RPCRequests{first, second})
+	assert.NoError(t, err)
+	// 按调用方的 ID 发送，响应按请求顺序返回并带回原 ID
+	assert.ElementsMatch(t, []int{7, 3}, sent)
+	assert.Equal(t, 7, resps[0].ID)
+	assert.Equal(t, 3, resps[1].ID)
+	var result string
+	assert.NoError(t, resps[0].ReadToObject(&result))
+	assert.Equal(t, "first", result)
+
+	// ID 重复时无法对应响应，不发送请求
+	sent = nil
+	_, err = c.CallBatch(RPCRequests{NewRPCRequest("a"), NewRPCRequest("b")})
+	assert.Error(t, err)
+	assert.Empty(t, sent)
+}
+
+func TestRPCClient_CallRetryOnLimitExceeded(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":{"code":-32005,"message":"limit exceeded"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	// 未配置重试时直接返回限流错误
+	var result string
+	err := NewRPCClient(server.URL).CallFor(&result, "eth_blockNumber")
+	assert.Error(t, err)
+
+	atomic.StoreInt32(&calls, 0)
+	err = NewRPCClient(server.URL, WithRetry(2, time.Millisecond, 5*time.Millisecond)).CallFor(&result, "eth_blockNumber")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", result)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRPCClient_RetryOnTooManyRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	var result string
+	err := NewRPCClient(server.URL).CallFor(&result, "eth_blockNumber")
+	assert.Error(t, err)
+
+	atomic.StoreInt32(&calls, 0)
+	err = NewRPCClient(server.URL, WithRetry(3, time.Millisecond, 5*time.Millisecond)).CallFor(&result, "eth_blockNumber")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRPCClient_Backoff(t *testing.T) {
+	c := &rpcClient{retryBaseDelay: 10 * time.Millisecond, retryMaxDelay: 50 * time.Millisecond}
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := c.backoff(attempt)
+		assert.True(t, delay >= 5*time.Millisecond && delay <= 50*time.Millisecond, "attempt %d delay %s", attempt, delay)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100, 1)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		l.Wait()
+	}
+	// 突发1个，之后每个令牌间隔10ms
+	assert.True(t, time.Since(start) >= 35*time.Millisecond)
+}
@@ -0,0 +1,21 @@
+const fs = require("fs");
+const path = require("path");
+const hre = require("hardhat");
+
+// 调度服务使用的v3 pool合约ABI，每行一个条目，与编译产物中的顺序一致
+const OUTPUT = path.resolve(__dirname, "../../../server/schedule/config/abi/Aave2PoolV3.json");
+
+async function main() {
+    // 使用 contracts/v3/Aave2PoolV2.sol 编译出的 Aave2Pool
+    const artifact = await hre.artifacts.readArtifact("contracts/v3/Aave2PoolV2.sol:Aave2Pool");
+    const lines = artifact.abi.map(item => "  " + JSON.stringify(item));
+    fs.writeFileSync(OUTPUT, "[\n" + lines.join(",\n") + "\n]\n");
+    console.log("Export abi to:", OUTPUT);
+}
+
+main()
+    .then(() => process.exit(0))
+    .catch(error => {
+        console.error(error);
+        process.exit(1);
+    });